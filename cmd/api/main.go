@@ -26,7 +26,7 @@ import (
 // @securityDefinitions.apikey	BearerAuth
 // @in							header
 // @name						Authorization
-// @description				Type "Bearer" followed by a space and JWT token. Example: "Bearer {token}"
+// @description				Type "Bearer" followed by a space and an access token or a personal access token. Example: "Bearer {token}"
 func main() {
 	if err := run(); err != nil {
 		log.Fatalf("Application failed: %v", err)
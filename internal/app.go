@@ -21,11 +21,15 @@ import (
 	userSvc "github.com/sammidev/goca/internal/modules/user/service"
 
 	"github.com/sammidev/goca/internal/config"
+	"github.com/sammidev/goca/internal/pkg/breach"
 	"github.com/sammidev/goca/internal/pkg/cache"
 	"github.com/sammidev/goca/internal/pkg/database"
 	"github.com/sammidev/goca/internal/pkg/email"
+	"github.com/sammidev/goca/internal/pkg/geoip"
 	"github.com/sammidev/goca/internal/pkg/logger"
 	"github.com/sammidev/goca/internal/pkg/observability"
+	"github.com/sammidev/goca/internal/pkg/otpcode"
+	"github.com/sammidev/goca/internal/pkg/password"
 	"github.com/sammidev/goca/internal/pkg/ratelimit"
 	"github.com/sammidev/goca/internal/pkg/scheduler"
 	"github.com/sammidev/goca/internal/pkg/token"
@@ -42,6 +46,8 @@ type Application struct {
 	taskProcessor worker.TaskProcessor
 	db            database.Database
 	cache         cache.Cache
+	geoip         geoip.Locator
+	breach        breach.Checker
 	observability func(context.Context) error
 }
 
@@ -75,7 +81,7 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 	}
 
 	// Initialize remaining dependencies
-	jwtToken, err := token.NewJWT(cfg)
+	jwtToken, err := token.New(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +101,26 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 		return nil, err
 	}
 
+	geoIPLocator, err := geoip.New(cfg.GeoIPDatabasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHasher, err := password.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordPolicy, err := password.NewPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	breachChecker, err := breach.New(cfg.PasswordBreachCorpusPath)
+	if err != nil {
+		return nil, err
+	}
+
 	asynqRedisOpt := asynq.RedisClientOpt{
 		Addr:     cfg.RedisDSN(),
 		Password: cfg.RedisPassword,
@@ -103,7 +129,12 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 
 	taskProcessor := worker.NewRedisTaskProcessor(postgresDB, zapLogger, asynqRedisOpt, smtpClient)
 	taskDistributor := worker.NewRedisTaskDistributor(asynqRedisOpt, cfg)
-	goPlaygroundValidator := validator.NewGoPlaygroundValidatorWithLocale(validator.Locale(cfg.AppLocale))
+	goPlaygroundValidator := validator.NewGoPlaygroundValidatorWithLocale(
+		validator.Locale(cfg.AppLocale),
+		validator.WithPasswordPolicy(passwordPolicy),
+		validator.WithBreachChecker(breachChecker),
+		validator.WithLogger(zapLogger.WithComponent("validator")),
+	)
 
 	cronScheduler, err := scheduler.New(zapLogger)
 	if err != nil {
@@ -111,7 +142,7 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 	}
 
 	// Initialize server with handlers
-	server, err := initializeServer(cfg, zapLogger, postgresDB, redisClient, jwtToken, authRateLimiter, goPlaygroundValidator, taskDistributor)
+	server, err := initializeServer(cfg, zapLogger, postgresDB, redisClient, jwtToken, authRateLimiter, geoIPLocator, passwordHasher, goPlaygroundValidator, taskDistributor, cronScheduler)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +156,8 @@ func NewApplication(cfg *config.Config) (*Application, error) {
 		taskProcessor: taskProcessor,
 		db:            postgresDB,
 		cache:         redisClient,
+		geoip:         geoIPLocator,
+		breach:        breachChecker,
 	}, nil
 }
 
@@ -169,6 +202,12 @@ func (a *Application) Close() {
 	if a.cache != nil {
 		a.cache.Close()
 	}
+	if a.geoip != nil {
+		a.geoip.Close()
+	}
+	if a.breach != nil {
+		a.breach.Close()
+	}
 }
 
 // handleShutdown handles graceful shutdown of all services
@@ -194,10 +233,33 @@ func initializeServer(
 	cache cache.Cache,
 	jwtToken token.Token,
 	authRateLimit ratelimit.RateLimiter,
+	geoIPLocator geoip.Locator,
+	passwordHasher password.Hasher,
 	validator validator.Validator,
 	taskDistributor worker.TaskDistributor,
+	cronScheduler scheduler.Scheduler,
 ) (*apiServer.Server, error) {
+	tokenDenylist := token.NewCacheDenylist(cache, cfg)
+
+	otpStore, err := otpcode.NewCacheStore(cache, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize user module
+	refreshTokenRepo := userRepo.NewRefreshTokenPostgresRepository(db.(*database.PostgreSQLDatabase))
+	twoFactorEnrollmentRepo := userRepo.NewTwoFactorEnrollmentPostgresRepository(db.(*database.PostgreSQLDatabase))
+	twoFactorRecoveryCodeRepo := userRepo.NewTwoFactorRecoveryCodePostgresRepository(db.(*database.PostgreSQLDatabase))
+	loginAttemptRepo := userRepo.NewLoginAttemptPostgresRepository(db.(*database.PostgreSQLDatabase))
+	sessionRepo := userRepo.NewSessionPostgresRepository(db.(*database.PostgreSQLDatabase))
+	emailChangeRequestRepo := userRepo.NewEmailChangeRequestPostgresRepository(db.(*database.PostgreSQLDatabase))
+	adminAuditLogRepo := userRepo.NewAdminAuditLogPostgresRepository(db.(*database.PostgreSQLDatabase))
+	roleRepo := userRepo.NewRolePostgresRepository(db.(*database.PostgreSQLDatabase))
+	personalAccessTokenRepo := userRepo.NewPersonalAccessTokenPostgresRepository(db.(*database.PostgreSQLDatabase))
+	securityEventRepo := userRepo.NewSecurityEventPostgresRepository(db.(*database.PostgreSQLDatabase))
+	knownDeviceRepo := userRepo.NewKnownDevicePostgresRepository(db.(*database.PostgreSQLDatabase))
+	passwordHistoryRepo := userRepo.NewPasswordHistoryPostgresRepository(db.(*database.PostgreSQLDatabase))
+	inviteRepo := userRepo.NewInvitePostgresRepository(db.(*database.PostgreSQLDatabase))
 	userRepo := userRepo.NewUserPostgresRepository(db.(*database.PostgreSQLDatabase))
 	userService := userSvc.NewUserService(
 		cfg,
@@ -205,13 +267,78 @@ func initializeServer(
 		validator,
 		db,
 		jwtToken,
+		tokenDenylist,
 		cache,
 		taskDistributor,
 		authRateLimit,
+		geoIPLocator,
+		passwordHasher,
+		otpStore,
 		userRepo,
+		refreshTokenRepo,
+		twoFactorEnrollmentRepo,
+		twoFactorRecoveryCodeRepo,
+		loginAttemptRepo,
+		sessionRepo,
+		emailChangeRequestRepo,
+		adminAuditLogRepo,
+		roleRepo,
+		personalAccessTokenRepo,
+		securityEventRepo,
+		knownDeviceRepo,
+		passwordHistoryRepo,
+		inviteRepo,
 	)
 	userHandler := userHdl.NewUserHandler(userService)
 
+	if err := cronScheduler.RegisterJob(config.ExpiredTokenCleanupSchedule, func() {
+		_ = userService.PurgeExpiredRefreshTokens(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := cronScheduler.RegisterJob(config.ExpiredTwoFactorEnrollmentCleanupSchedule, func() {
+		_ = userService.PurgeExpired2FAEnrollments(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := cronScheduler.RegisterJob(config.ExpiredLoginAttemptCleanupSchedule, func() {
+		_ = userService.PurgeExpiredLoginAttempts(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := cronScheduler.RegisterJob(config.ExpiredSessionCleanupSchedule, func() {
+		_ = userService.PurgeExpiredSessions(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := cronScheduler.RegisterJob(config.ExpiredSecurityEventCleanupSchedule, func() {
+		_ = userService.PurgeExpiredSecurityEvents(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := cronScheduler.RegisterJob(config.StaleKnownDeviceCleanupSchedule, func() {
+		_ = userService.PurgeStaleKnownDevices(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := cronScheduler.RegisterJob(config.ExpiredEmailChangeRequestCleanupSchedule, func() {
+		_ = userService.PurgeExpiredEmailChangeRequests(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := cronScheduler.RegisterJob(config.ScheduledAccountDeletionSchedule, func() {
+		_ = userService.PurgeScheduledAccountDeletions(context.Background())
+	}); err != nil {
+		return nil, err
+	}
+
 	// Initialize note module
 	noteRepo := noteRepo.NewNotePostgresRepository(db.(*database.PostgreSQLDatabase))
 	noteService := noteSvc.NewNoteService(
@@ -224,7 +351,7 @@ func initializeServer(
 	)
 	noteHandler := noteHdl.NewNoteHandler(noteService)
 
-	server, err := apiServer.NewServer(cfg, logger, jwtToken, userHandler, noteHandler)
+	server, err := apiServer.NewServer(cfg, logger, jwtToken, tokenDenylist, userService, userHandler, noteHandler)
 	if err != nil {
 		return nil, err
 	}
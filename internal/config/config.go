@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"reflect"
 	"strings"
 	"time"
 
@@ -60,12 +61,42 @@ type Config struct {
 	ServerShutdownTimeout time.Duration `mapstructure:"SERVER_SHUTDOWN_TIMEOUT"`
 
 	// Auth
+	AuthTokenFormat                string        `mapstructure:"AUTH_TOKEN_FORMAT"`
+	AuthJWTAlgorithm               string        `mapstructure:"AUTH_JWT_ALGORITHM"`
 	AuthJWTSecret                  string        `mapstructure:"AUTH_JWT_SECRET"`
+	AuthJWTKeysDir                 string        `mapstructure:"AUTH_JWT_KEYS_DIR"`
+	AuthJWTSigningKeyID            string        `mapstructure:"AUTH_JWT_SIGNING_KEY_ID"`
+	AuthPASETOKey                  string        `mapstructure:"AUTH_PASETO_KEY"`
+	AuthOTPSecret                  string        `mapstructure:"AUTH_OTP_SECRET"`
+	AuthEmailVerificationMode      string        `mapstructure:"AUTH_EMAIL_VERIFICATION_MODE"`
+	AuthPasswordResetMode          string        `mapstructure:"AUTH_PASSWORD_RESET_MODE"`
 	AuthAccessTokenExpiry          time.Duration `mapstructure:"AUTH_ACCESS_TOKEN_EXPIRY"`
 	AuthAccessTokenExpiryExtended  time.Duration `mapstructure:"AUTH_ACCESS_TOKEN_EXPIRY_EXTENDED"`
 	AuthRefreshTokenExpiry         time.Duration `mapstructure:"AUTH_REFRESH_TOKEN_EXPIRY"`
 	AuthRefreshTokenExpiryExtended time.Duration `mapstructure:"AUTH_REFRESH_TOKEN_EXPIRY_EXTENDED"`
 
+	// Registration
+	RegistrationMode           string   `mapstructure:"REGISTRATION_MODE"`
+	RegistrationAllowedDomains []string `mapstructure:"REGISTRATION_ALLOWED_DOMAINS"`
+
+	// Password hashing, zero values use the package defaults
+	PasswordHashAlgorithm     string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordArgon2Memory      uint32 `mapstructure:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Iterations  uint32 `mapstructure:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism uint8  `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+	PasswordBcryptCost        int    `mapstructure:"PASSWORD_BCRYPT_COST"`
+
+	// Password policy, keys that are not set keep password.DefaultPolicy
+	PasswordMinLength        int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinLowercase     int           `mapstructure:"PASSWORD_MIN_LOWERCASE"`
+	PasswordMinUppercase     int           `mapstructure:"PASSWORD_MIN_UPPERCASE"`
+	PasswordMinDigits        int           `mapstructure:"PASSWORD_MIN_DIGITS"`
+	PasswordMinSpecial       int           `mapstructure:"PASSWORD_MIN_SPECIAL"`
+	PasswordBannedWords      []string      `mapstructure:"PASSWORD_BANNED_WORDS"`
+	PasswordMinStrength      int           `mapstructure:"PASSWORD_MIN_STRENGTH"`
+	PasswordBreachCorpusPath string        `mapstructure:"PASSWORD_BREACH_CORPUS_PATH"`
+	PasswordMaxAge           time.Duration `mapstructure:"PASSWORD_MAX_AGE"`
+
 	// Logger
 	LoggerFile       string `mapstructure:"LOGGER_FILE"`
 	LoggerLevel      string `mapstructure:"LOGGER_LEVEL"`
@@ -75,8 +106,32 @@ type Config struct {
 	LoggerCompress   bool   `mapstructure:"LOGGER_COMPRESS"`
 	LoggerOutput     string `mapstructure:"LOGGER_OUTPUT"`
 
+	// GeoIP
+	GeoIPDatabasePath string `mapstructure:"GEOIP_DATABASE_PATH"`
+
 	// Observability
 	OtelExporterOtlpEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+
+	// setKeys holds the keys given explicitly in the .env file or the environment.
+	setKeys map[string]bool
+}
+
+// IsSet reports whether key was given explicitly, so callers can tell a value left
+// unset from one deliberately set to its zero value.
+func (c *Config) IsSet(key string) bool {
+	return c.setKeys[key]
+}
+
+// MarkSet records keys as given explicitly. NewConfig marks every key it finds, tests
+// and other callers building a Config by hand mark the keys they fill in.
+func (c *Config) MarkSet(keys ...string) *Config {
+	if c.setKeys == nil {
+		c.setKeys = make(map[string]bool, len(keys))
+	}
+	for _, key := range keys {
+		c.setKeys[key] = true
+	}
+	return c
 }
 
 // DSN generates the database connection string
@@ -114,16 +169,75 @@ func NewConfig() (*Config, error) {
 	// Ensure case-sensitive matching for mapstructure tags
 	v.SetEnvPrefix("")
 
+	// Bind every key explicitly, AutomaticEnv alone is not seen by Unmarshal for
+	// keys missing from the .env file
+	keys := configKeys()
+	for _, key := range keys {
+		if err := v.BindEnv(key); err != nil {
+			return nil, fmt.Errorf("failed to bind %s: %w", key, err)
+		}
+	}
+
 	// Unmarshal configuration into struct
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	for _, key := range keys {
+		if v.IsSet(key) {
+			cfg.MarkSet(key)
+		}
+	}
+
 	// Validate critical fields
 	if cfg.DatabaseHost == "" || cfg.DatabaseName == "" || cfg.DatabaseUser == "" {
 		return nil, fmt.Errorf("missing required database configuration: DATABASE_HOST, DATABASE_DB, or DATABASE_USER")
 	}
 
+	if err := cfg.normalizeRegistration(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+// configKeys returns the mapstructure key of every Config field.
+func configKeys() []string {
+	configType := reflect.TypeFor[Config]()
+	keys := make([]string, 0, configType.NumField())
+	for i := range configType.NumField() {
+		if key := configType.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// normalizeRegistration defaults the registration mode to open and lowercases the
+// allowed domains, so they can be compared with email addresses as they are.
+func (c *Config) normalizeRegistration() error {
+	c.RegistrationMode = strings.ToLower(strings.TrimSpace(c.RegistrationMode))
+	switch c.RegistrationMode {
+	case "":
+		c.RegistrationMode = RegistrationModeOpen
+	case RegistrationModeOpen, RegistrationModeInviteOnly, RegistrationModeDomainAllowlist, RegistrationModeClosed:
+	default:
+		return fmt.Errorf("unsupported REGISTRATION_MODE %q", c.RegistrationMode)
+	}
+
+	domains := make([]string, 0, len(c.RegistrationAllowedDomains))
+	for _, domain := range c.RegistrationAllowedDomains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	c.RegistrationAllowedDomains = domains
+
+	if c.RegistrationMode == RegistrationModeDomainAllowlist && len(domains) == 0 {
+		return errors.New("REGISTRATION_ALLOWED_DOMAINS is required when REGISTRATION_MODE is domain_allowlist")
+	}
+
+	return nil
+}
@@ -10,8 +10,91 @@ const (
 const (
 	EmailConfirmationExpInMin = 15 * time.Minute
 	OTPCodeLength             = 6
+	// OTPMaxAttempts is the number of wrong guesses after which a code is invalidated.
+	OTPMaxAttempts = 5
+	// OTPResendCooldown is the minimum time between two codes for the same purpose and address.
+	OTPResendCooldown = 1 * time.Minute
 )
 
 const (
 	AuthRateLimiterKey = "auth"
 )
+
+const (
+	MFAChallengeExpiry      = 5 * time.Minute
+	MFAChallengeMaxAttempts = 5
+	ReauthTokenExpiry       = 5 * time.Minute
+)
+
+const (
+	TwoFactorEnrollmentExpiry  = 15 * time.Minute
+	TwoFactorRecoveryCodeCount = 10
+	// TwoFactorRecoveryCodeLength is the number of random characters per code,
+	// shown to the user in two dash-separated halves.
+	TwoFactorRecoveryCodeLength = 10
+)
+
+// Registration modes accepted by REGISTRATION_MODE.
+const (
+	// RegistrationModeOpen lets anyone register.
+	RegistrationModeOpen = "open"
+	// RegistrationModeInviteOnly only accepts sign-ups that redeem an invite.
+	RegistrationModeInviteOnly = "invite_only"
+	// RegistrationModeDomainAllowlist only accepts email addresses from
+	// REGISTRATION_ALLOWED_DOMAINS, or sign-ups that redeem an invite.
+	RegistrationModeDomainAllowlist = "domain_allowlist"
+	// RegistrationModeClosed disables registration.
+	RegistrationModeClosed = "closed"
+
+	// InviteExpiry is how long an invite can be redeemed after it is sent.
+	InviteExpiry = 7 * 24 * time.Hour
+)
+
+const (
+	// PasswordHistorySize is how many previous passwords a user cannot reuse, on top of the current one.
+	PasswordHistorySize = 5
+)
+
+const (
+	// LoginFailureWindow is how long a failed login keeps counting towards the lockout.
+	LoginFailureWindow = 1 * time.Hour
+	// LoginBackoffThreshold is the number of consecutive failures after which every
+	// further attempt has to wait, starting at LoginBackoffBaseDelay and doubling up
+	// to LoginBackoffMaxDelay.
+	LoginBackoffThreshold = 3
+	LoginBackoffBaseDelay = 1 * time.Second
+	LoginBackoffMaxDelay  = 1 * time.Minute
+	LoginLockoutThreshold = 10
+	LoginLockoutDuration  = 30 * time.Minute
+)
+
+const (
+	ExpiredTokenCleanupSchedule               = "1h"
+	ExpiredTwoFactorEnrollmentCleanupSchedule = "1h"
+	ExpiredLoginAttemptCleanupSchedule        = "1h"
+	ExpiredSessionCleanupSchedule             = "1h"
+	ExpiredEmailChangeRequestCleanupSchedule  = "1h"
+	ScheduledAccountDeletionSchedule          = "1h"
+	ExpiredSecurityEventCleanupSchedule       = "24h"
+	StaleKnownDeviceCleanupSchedule           = "24h"
+)
+
+const (
+	// AccountDeletionGracePeriod is how long a deleted account can still be restored by logging in.
+	AccountDeletionGracePeriod = 14 * 24 * time.Hour
+	// SecurityEventRetention is how long security events are kept before they are purged.
+	SecurityEventRetention = 180 * 24 * time.Hour
+	// KnownDeviceRetention is how long a device stays known without being used to sign in.
+	// Signing in from it after that raises a new login alert again.
+	KnownDeviceRetention = 180 * 24 * time.Hour
+	// LoginAlertRevokeLinkExpiry is how long the "this wasn't me" link in a login alert stays valid.
+	LoginAlertRevokeLinkExpiry = 7 * 24 * time.Hour
+)
+
+const (
+	// PersonalAccessTokenLastUsedInterval limits how often the last-used time of a
+	// personal access token is written, so busy scripts do not update it on every request.
+	PersonalAccessTokenLastUsedInterval = 1 * time.Minute
+	// PersonalAccessTokenMaxLifetime is the longest expiry a personal access token can be given.
+	PersonalAccessTokenMaxLifetime = 365 * 24 * time.Hour
+)
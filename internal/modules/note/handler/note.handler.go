@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sammidev/goca/internal/modules/note/dto"
+	"github.com/sammidev/goca/internal/pkg/request"
 	"github.com/sammidev/goca/internal/pkg/response"
 	"github.com/sammidev/goca/internal/server/api/middleware"
 )
@@ -65,7 +66,7 @@ func (h *NoteHandler) CreateNote(c *fiber.Ctx) error {
 //	@Failure		500	{object}	response.Response
 //	@Router			/notes/{id} [get]
 func (h *NoteHandler) GetNote(c *fiber.Ctx) error {
-	noteID, err := ParseUUIDParam(c, "id")
+	noteID, err := request.ParseUUIDParam(c, "id")
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
 	}
@@ -100,7 +101,7 @@ func (h *NoteHandler) GetNote(c *fiber.Ctx) error {
 //	@Failure		500		{object}	response.Response
 //	@Router			/notes/{id} [put]
 func (h *NoteHandler) UpdateNote(c *fiber.Ctx) error {
-	noteID, err := ParseUUIDParam(c, "id")
+	noteID, err := request.ParseUUIDParam(c, "id")
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
 	}
@@ -137,7 +138,7 @@ func (h *NoteHandler) UpdateNote(c *fiber.Ctx) error {
 //	@Failure		500	{object}	response.Response
 //	@Router			/notes/{id} [delete]
 func (h *NoteHandler) DeleteNote(c *fiber.Ctx) error {
-	noteID, err := ParseUUIDParam(c, "id")
+	noteID, err := request.ParseUUIDParam(c, "id")
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
 	}
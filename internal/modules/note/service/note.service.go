@@ -9,6 +9,7 @@ import (
 	"github.com/sammidev/goca/internal/modules/note/dto"
 	"github.com/sammidev/goca/internal/modules/note/entity"
 	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/authz"
 	"github.com/sammidev/goca/internal/pkg/database"
 	"github.com/sammidev/goca/internal/pkg/logger"
 	"github.com/sammidev/goca/internal/pkg/validator"
@@ -105,7 +106,7 @@ func (s *NoteService) GetNote(ctx context.Context, req *dto.GetNoteRequest) (*dt
 		return nil, err
 	}
 
-	if err := s.checkNoteOwnership(note.UserID, req.UserID); err != nil {
+	if err := s.checkNoteOwnership(ctx, note.UserID, req.UserID, authz.NotesReadAny); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
@@ -134,7 +135,7 @@ func (s *NoteService) UpdateNote(ctx context.Context, req *dto.UpdateNoteRequest
 
 	var updatedNote *entity.Note
 	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		note, err := s.getNoteWithOwnershipCheck(txCtx, req.NoteID, req.UserID)
+		note, err := s.getNoteWithOwnershipCheck(txCtx, req.NoteID, req.UserID, authz.NotesUpdateAny)
 		if err != nil {
 			return err
 		}
@@ -172,7 +173,7 @@ func (s *NoteService) DeleteNote(ctx context.Context, req *dto.DeleteNoteRequest
 	)
 
 	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		if _, err := s.getNoteWithOwnershipCheck(txCtx, req.NoteID, req.UserID); err != nil {
+		if _, err := s.getNoteWithOwnershipCheck(txCtx, req.NoteID, req.UserID, authz.NotesDeleteAny); err != nil {
 			return err
 		}
 
@@ -212,14 +213,16 @@ func (s *NoteService) GetNotes(ctx context.Context, req *dto.GetNotesRequest) (*
 
 // Helper methods
 
-func (s *NoteService) checkNoteOwnership(noteUserID, requestUserID uuid.UUID) error {
-	if noteUserID != requestUserID {
+// checkNoteOwnership allows the owner of the note, or a caller whose token grants
+// anyPermission, e.g. a moderator deleting someone else's note.
+func (s *NoteService) checkNoteOwnership(ctx context.Context, noteUserID, requestUserID uuid.UUID, anyPermission string) error {
+	if !authz.CanAccess(ctx, requestUserID, noteUserID, anyPermission) {
 		return apperror.ErrForbidden
 	}
 	return nil
 }
 
-func (s *NoteService) getNoteWithOwnershipCheck(ctx context.Context, noteID, userID uuid.UUID) (*entity.Note, error) {
+func (s *NoteService) getNoteWithOwnershipCheck(ctx context.Context, noteID, userID uuid.UUID, anyPermission string) (*entity.Note, error) {
 	ctx, span := s.tracer.Start(ctx, "helper.getNoteWithOwnershipCheck")
 	defer span.End()
 
@@ -239,7 +242,7 @@ func (s *NoteService) getNoteWithOwnershipCheck(ctx context.Context, noteID, use
 		return nil, apperror.ErrInternalError
 	}
 
-	if err := s.checkNoteOwnership(note.UserID, userID); err != nil {
+	if err := s.checkNoteOwnership(ctx, note.UserID, userID, anyPermission); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
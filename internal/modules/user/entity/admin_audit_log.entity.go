@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdminAction string
+
+const (
+	AdminActionListUsers     AdminAction = "user.list"
+	AdminActionViewUser      AdminAction = "user.view"
+	AdminActionSuspendUser   AdminAction = "user.suspend"
+	AdminActionReactivate    AdminAction = "user.reactivate"
+	AdminActionVerifyEmail   AdminAction = "user.verify_email"
+	AdminActionReset2FA      AdminAction = "user.reset_2fa"
+	AdminActionResetPassword AdminAction = "user.reset_password"
+	AdminActionUnlockAccount AdminAction = "user.unlock"
+	AdminActionAssignRole    AdminAction = "user.assign_role"
+
+	AdminActionListSecurityEvents AdminAction = "security_event.list"
+
+	AdminActionCreateInvite AdminAction = "invite.create"
+	AdminActionListInvites  AdminAction = "invite.list"
+	AdminActionRevokeInvite AdminAction = "invite.revoke"
+)
+
+// AdminAuditLog records an action an administrator performed. TargetUserID is nil
+// for actions that do not concern a single user, such as listing users.
+type AdminAuditLog struct {
+	ID           uuid.UUID         `db:"id"`
+	ActorID      uuid.UUID         `db:"actor_id"`
+	TargetUserID *uuid.UUID        `db:"target_user_id"`
+	Action       AdminAction       `db:"action"`
+	Metadata     map[string]string `db:"metadata"`
+	IPAddress    string            `db:"ip_address"`
+	UserAgent    string            `db:"user_agent"`
+	CreatedAt    time.Time         `db:"created_at"`
+}
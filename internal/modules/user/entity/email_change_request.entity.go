@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailChangeRequest is a pending change of a user's email address. The address
+// is only swapped once the code sent to NewEmail is confirmed; the link sent to
+// the current address carries the token CancelTokenID and withdraws the request.
+type EmailChangeRequest struct {
+	UserID        uuid.UUID `db:"user_id"`
+	NewEmail      string    `db:"new_email"`
+	CancelTokenID uuid.UUID `db:"cancel_token_id"`
+	ExpiresAt     time.Time `db:"expires_at"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+func (r *EmailChangeRequest) IsExpired(now time.Time) bool {
+	return now.After(r.ExpiresAt)
+}
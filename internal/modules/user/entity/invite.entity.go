@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InviteStatus string
+
+const (
+	InviteStatusPending  InviteStatus = "pending"
+	InviteStatusRedeemed InviteStatus = "redeemed"
+	InviteStatusRevoked  InviteStatus = "revoked"
+	InviteStatusExpired  InviteStatus = "expired"
+)
+
+// Invite lets an administrator admit one email address while registration is
+// restricted. Only the hash of the invite token is stored. InvitedBy and RedeemedBy
+// become nil when the account they point to is deleted.
+type Invite struct {
+	ID         uuid.UUID  `db:"id"`
+	Email      string     `db:"email"`
+	TokenHash  string     `db:"token_hash"`
+	InvitedBy  *uuid.UUID `db:"invited_by"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RedeemedAt *time.Time `db:"redeemed_at"`
+	RedeemedBy *uuid.UUID `db:"redeemed_by"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+func (i *Invite) IsRedeemed() bool {
+	return i.RedeemedAt != nil
+}
+
+func (i *Invite) IsRevoked() bool {
+	return i.RevokedAt != nil
+}
+
+func (i *Invite) IsExpired(now time.Time) bool {
+	return now.After(i.ExpiresAt)
+}
+
+// IsFor reports whether the invite was sent to the email address, ignoring case.
+func (i *Invite) IsFor(email string) bool {
+	return strings.EqualFold(i.Email, email)
+}
+
+// Status describes the invite at the given time. A redeemed invite stays redeemed
+// after it expires.
+func (i *Invite) Status(now time.Time) InviteStatus {
+	switch {
+	case i.IsRedeemed():
+		return InviteStatusRedeemed
+	case i.IsRevoked():
+		return InviteStatusRevoked
+	case i.IsExpired(now):
+		return InviteStatusExpired
+	default:
+		return InviteStatusPending
+	}
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KnownDevice is a device fingerprint and IP range combination a user has signed in
+// from. A login from a fingerprint or an IP range the user has not signed in from
+// before is reported to the user.
+type KnownDevice struct {
+	ID          uuid.UUID `db:"id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Fingerprint string    `db:"fingerprint"`
+	IPRange     string    `db:"ip_range"`
+	Device      string    `db:"device"`
+	Location    string    `db:"location"`
+	FirstSeenAt time.Time `db:"first_seen_at"`
+	LastSeenAt  time.Time `db:"last_seen_at"`
+}
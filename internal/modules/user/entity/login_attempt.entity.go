@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt tracks the consecutive failed sign-in attempts of a user. The record
+// is removed after a successful login or when the account is unlocked.
+type LoginAttempt struct {
+	UserID       uuid.UUID  `db:"user_id"`
+	FailedCount  int        `db:"failed_count"`
+	LastFailedAt time.Time  `db:"last_failed_at"`
+	LockedUntil  *time.Time `db:"locked_until"`
+}
+
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
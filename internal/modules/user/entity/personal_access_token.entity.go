@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessToken is a long-lived token for scripts and integrations. Only the
+// hash of the token is stored; TokenHint keeps its last characters so the user can
+// tell their tokens apart.
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `db:"id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	Name       string     `db:"name"`
+	TokenHash  string     `db:"token_hash"`
+	TokenHint  string     `db:"token_hint"`
+	Scopes     []string   `db:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	LastUsedIP string     `db:"last_used_ip"`
+	CreatedAt  time.Time  `db:"created_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+func (t *PersonalAccessToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsExpired reports whether the token has passed its expiry. Tokens without an
+// expiry never expire.
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
+
+func (t *PersonalAccessToken) Revoke(now time.Time) {
+	t.RevokedAt = &now
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is the server-side record of an issued refresh token.
+// ID is the token's jti; every token issued from the same login shares a FamilyID.
+type RefreshToken struct {
+	ID        uuid.UUID  `db:"id"`
+	FamilyID  uuid.UUID  `db:"family_id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	Remember  bool       `db:"remember"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+func (t *RefreshToken) IsRotated() bool {
+	return t.RotatedAt != nil
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
+}
+
+func (t *RefreshToken) Rotate(now time.Time) {
+	t.RotatedAt = &now
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// Role groups the permissions a user gets through users.role.
+type Role struct {
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	Permissions []string  `db:"permissions"`
+	CreatedAt   time.Time `db:"created_at"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SecurityEventType string
+
+const (
+	SecurityEventLogin                      SecurityEventType = "login"
+	SecurityEventNewDeviceLogin             SecurityEventType = "login.new_device"
+	SecurityEventLoginReported              SecurityEventType = "login.reported"
+	SecurityEventAccountLocked              SecurityEventType = "account.locked"
+	SecurityEventAccountDeletionScheduled   SecurityEventType = "account.deletion_scheduled"
+	SecurityEventOTPSent                    SecurityEventType = "otp.sent"
+	SecurityEventOTPVerified                SecurityEventType = "otp.verified"
+	SecurityEventEmailVerified              SecurityEventType = "email.verified"
+	SecurityEventEmailChanged               SecurityEventType = "email.changed"
+	SecurityEventReauthenticated            SecurityEventType = "reauthenticated"
+	SecurityEventPasswordReset              SecurityEventType = "password.reset"
+	SecurityEventPasswordChanged            SecurityEventType = "password.changed"
+	SecurityEventTwoFactorEnabled           SecurityEventType = "2fa.enabled"
+	SecurityEventTwoFactorDisabled          SecurityEventType = "2fa.disabled"
+	SecurityEventRecoveryCodesRegenerated   SecurityEventType = "2fa.recovery_codes_regenerated"
+	SecurityEventPersonalAccessTokenCreated SecurityEventType = "personal_access_token.created"
+	SecurityEventPersonalAccessTokenRevoked SecurityEventType = "personal_access_token.revoked"
+)
+
+type SecurityEventOutcome string
+
+const (
+	SecurityEventOutcomeSuccess SecurityEventOutcome = "success"
+	SecurityEventOutcomeFailure SecurityEventOutcome = "failure"
+)
+
+// SecurityEvent records authentication activity on an account. UserID is nil when
+// the attempt could not be tied to an account, e.g. a login with an unknown email.
+type SecurityEvent struct {
+	ID        uuid.UUID            `db:"id"`
+	UserID    *uuid.UUID           `db:"user_id"`
+	EventType SecurityEventType    `db:"event_type"`
+	Outcome   SecurityEventOutcome `db:"outcome"`
+	IPAddress string               `db:"ip_address"`
+	UserAgent string               `db:"user_agent"`
+	RequestID string               `db:"request_id"`
+	Metadata  map[string]string    `db:"metadata"`
+	CreatedAt time.Time            `db:"created_at"`
+}
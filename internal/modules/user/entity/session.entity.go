@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a signed-in device. Its ID is the FamilyID of the refresh tokens
+// issued for that login, and it ends when the family is revoked or expires.
+type Session struct {
+	ID         uuid.UUID  `db:"id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	Device     string     `db:"device"`
+	DeviceType string     `db:"device_type"`
+	IPAddress  string     `db:"ip_address"`
+	UserAgent  string     `db:"user_agent"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+func (s *Session) IsExpired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
+
+func (s *Session) Revoke(now time.Time) {
+	s.RevokedAt = &now
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactorEnrollment holds a TOTP secret that was generated for a user but not yet
+// confirmed with a valid code. 2FA is only enabled once the enrollment is confirmed.
+type TwoFactorEnrollment struct {
+	UserID    uuid.UUID `db:"user_id"`
+	Secret    string    `db:"secret"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (e *TwoFactorEnrollment) IsExpired(now time.Time) bool {
+	return now.After(e.ExpiresAt)
+}
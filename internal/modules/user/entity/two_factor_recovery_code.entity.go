@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactorRecoveryCode is a one-time code that can be used instead of a TOTP code
+// when the authenticator device is lost. Only the hash of the code is stored.
+type TwoFactorRecoveryCode struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+func (c *TwoFactorRecoveryCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
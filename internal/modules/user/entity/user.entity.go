@@ -15,6 +15,13 @@ const (
 	UserStatusSuspended UserStatus = "suspended"
 )
 
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
 type User struct {
 	ID               uuid.UUID  `db:"id"`
 	Email            string     `db:"email"`
@@ -24,19 +31,41 @@ type User struct {
 	FullName         string     `db:"full_name"`
 	Password         string     `db:"password"`
 	Status           UserStatus `db:"status"`
+	Role             UserRole   `db:"role"`
 	TwoFactorSecret  *string    `db:"two_factor_secret"`
 	TwoFactorEnabled bool       `db:"two_factor_enabled"`
-	CreatedAt        time.Time  `db:"created_at"`
-	UpdatedAt        time.Time  `db:"updated_at"`
+	// LoginAlertsEnabled is the user's preference for being emailed about sign-ins from new devices.
+	LoginAlertsEnabled bool `db:"login_alerts_enabled"`
+	// PasswordChangedAt is when the current password was set, used for the maximum password age.
+	PasswordChangedAt time.Time `db:"password_changed_at"`
+	// DeletionScheduledAt is when the account will be deleted, nil when no deletion is pending.
+	DeletionScheduledAt *time.Time `db:"deletion_scheduled_at"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
 }
 
 func (u *User) GenerateFullName() {
-	if u.LastName == nil {
+	if u.LastName == nil || *u.LastName == "" {
 		u.FullName = u.FirstName
+		return
 	}
 	u.FullName = u.FirstName + " " + *u.LastName
 }
 
+// IsPasswordExpired reports whether the password is older than maxAge. A zero maxAge never expires.
+func (u *User) IsPasswordExpired(maxAge time.Duration, now time.Time) bool {
+	return maxAge > 0 && now.Sub(u.PasswordChangedAt) > maxAge
+}
+
+// PersonalInfo returns the account details a password must not be built from.
+func (u *User) PersonalInfo() []string {
+	info := []string{u.Email, u.FirstName}
+	if u.LastName != nil {
+		info = append(info, *u.LastName)
+	}
+	return info
+}
+
 func (u *User) IsActive() bool {
 	return u.Status == UserStatusActive
 }
@@ -100,3 +129,15 @@ func (u *User) EnableTwoFactor() {
 func (u *User) VerifyEmail(t time.Time) {
 	u.EmailVerifiedAt = &t
 }
+
+func (u *User) IsDeletionScheduled() bool {
+	return u.DeletionScheduledAt != nil
+}
+
+func (u *User) ScheduleDeletion(t time.Time) {
+	u.DeletionScheduledAt = &t
+}
+
+func (u *User) CancelDeletion() {
+	u.DeletionScheduledAt = nil
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUserGenerateFullName(t *testing.T) {
+	Convey("Diberikan user dengan nama depan", t, func() {
+		user := &User{FirstName: "Sammi"}
+
+		Convey("Ketika nama belakang nil", func() {
+			user.LastName = nil
+			user.GenerateFullName()
+
+			Convey("Maka nama lengkap sama dengan nama depan", func() {
+				So(user.FullName, ShouldEqual, "Sammi")
+			})
+		})
+
+		Convey("Ketika nama belakang kosong", func() {
+			lastName := ""
+			user.LastName = &lastName
+			user.GenerateFullName()
+
+			Convey("Maka nama lengkap tidak diakhiri spasi", func() {
+				So(user.FullName, ShouldEqual, "Sammi")
+			})
+		})
+
+		Convey("Ketika nama belakang diisi", func() {
+			lastName := "Aldhi"
+			user.LastName = &lastName
+			user.GenerateFullName()
+
+			Convey("Maka nama lengkap menggabungkan keduanya", func() {
+				So(user.FullName, ShouldEqual, "Sammi Aldhi")
+			})
+		})
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sammidev/goca/internal/modules/user/dto"
+	"github.com/sammidev/goca/internal/pkg/request"
 	"github.com/sammidev/goca/internal/pkg/response"
 	"github.com/sammidev/goca/internal/server/api/middleware"
 )
@@ -28,7 +29,8 @@ func NewUserHandler(userService UserService) *UserHandler {
 //	@Produce		json
 //	@Param			request	body		dto.RegisterRequest								true	"User registration data"
 //	@Success		201		{object}	response.Response{data=dto.RegisterResponse}	"Register Successfully"
-//	@Failure		400		{object}	response.Response
+//	@Failure		400		{object}	response.Response								"Invalid data, or an invalid or expired invite"
+//	@Failure		403		{object}	response.Response								"Registration is closed, needs an invite or the email domain is not allowed"
 //	@Failure		500		{object}	response.Response
 //	@Router			/auth/register [post]
 func (h *UserHandler) Register(c *fiber.Ctx) error {
@@ -37,6 +39,8 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 		return response.HandleErrorAPI(c, err)
 	}
 
+	req.Client = ClientInfo(c)
+
 	res, err := h.userService.Register(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -48,13 +52,15 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 // Login godoc
 //
 //	@Summary		Login a user
-//	@Description	Authenticate a user with email and password
+//	@Description	Authenticate a user with email and password. When 2FA is enabled no tokens are returned; instead an MFA challenge token is issued that must be completed at /auth/2fa/challenge.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		dto.LoginRequest							true	"User login data"
 //	@Success		200		{object}	response.Response{data=dto.LoginResponse}	"Login Successfully"
 //	@Failure		400		{object}	response.Response
+//	@Failure		423		{object}	response.Response	"Account locked, retry after the Retry-After header"
+//	@Failure		429		{object}	response.Response	"Too many failed attempts, retry after the Retry-After header"
 //	@Failure		500		{object}	response.Response
 //	@Router			/auth/login [post]
 func (h *UserHandler) Login(c *fiber.Ctx) error {
@@ -63,6 +69,8 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 		return response.HandleErrorAPI(c, err)
 	}
 
+	req.Client = ClientInfo(c)
+
 	res, err := h.userService.Login(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -81,6 +89,7 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 //	@Param			request	body		dto.VerifyOTPRequest							true	"OTP verification data"
 //	@Success		200		{object}	response.Response{data=dto.VerifyOTPResponse}	"OTP verified successfully"
 //	@Failure		400		{object}	response.Response
+//	@Failure		429		{object}	response.Response	"Too many invalid codes, request a new one"
 //	@Failure		500		{object}	response.Response
 //	@Router			/auth/verify-otp [post]
 func (h *UserHandler) VerifyOTP(c *fiber.Ctx) error {
@@ -89,6 +98,8 @@ func (h *UserHandler) VerifyOTP(c *fiber.Ctx) error {
 		return response.HandleErrorAPI(c, err)
 	}
 
+	req.Client = ClientInfo(c)
+
 	res, err := h.userService.VerifyOTP(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -107,6 +118,7 @@ func (h *UserHandler) VerifyOTP(c *fiber.Ctx) error {
 //	@Param			request	body		dto.ResendOTPRequest	true	"Resend OTP data"
 //	@Success		200		{object}	response.Response		"OTP resent successfully"
 //	@Failure		400		{object}	response.Response
+//	@Failure		429		{object}	response.Response	"A code was sent recently, retry after the Retry-After header"
 //	@Failure		500		{object}	response.Response
 //	@Router			/auth/resend-otp [post]
 func (h *UserHandler) ResendOTP(c *fiber.Ctx) error {
@@ -115,6 +127,8 @@ func (h *UserHandler) ResendOTP(c *fiber.Ctx) error {
 		return response.HandleErrorAPI(c, err)
 	}
 
+	req.Client = ClientInfo(c)
+
 	err := h.userService.ResendOTP(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -126,13 +140,14 @@ func (h *UserHandler) ResendOTP(c *fiber.Ctx) error {
 // RefreshToken godoc
 //
 //	@Summary		Refresh Token
-//	@Description	Refresh the authentication token
+//	@Description	Rotate the refresh token and issue a new token pair. The submitted refresh token is invalidated; presenting it again ends the whole session, revoking its refresh and access tokens.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		dto.RefreshTokenRequest								true	"Refresh token data"
 //	@Success		200		{object}	response.Response{data=dto.RefreshTokenResponse}	"Token refreshed successfully"
 //	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
 //	@Failure		500		{object}	response.Response
 //	@Router			/auth/refresh-token [post]
 func (h *UserHandler) RefreshToken(c *fiber.Ctx) error {
@@ -141,6 +156,8 @@ func (h *UserHandler) RefreshToken(c *fiber.Ctx) error {
 		return response.HandleErrorAPI(c, err)
 	}
 
+	req.Client = ClientInfo(c)
+
 	res, err := h.userService.RefreshToken(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -167,6 +184,8 @@ func (h *UserHandler) ForgotPassword(c *fiber.Ctx) error {
 		return response.HandleErrorAPI(c, err)
 	}
 
+	req.Client = ClientInfo(c)
+
 	err := h.userService.ForgotPassword(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -178,12 +197,13 @@ func (h *UserHandler) ForgotPassword(c *fiber.Ctx) error {
 // ResetPassword godoc
 //
 //	@Summary		Reset Password
-//	@Description	Reset the user's password
+//	@Description	Reset the user's password. Every session and personal access token of the user is revoked.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		dto.ResetPasswordRequest	true	"Reset password data"
 //	@Failure		400		{object}	response.Response
+//	@Failure		429		{object}	response.Response	"Too many invalid codes, request a new one"
 //	@Failure		500		{object}	response.Response
 //	@Router			/auth/reset-password [post]
 func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
@@ -192,6 +212,8 @@ func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
 		return response.HandleErrorAPI(c, err)
 	}
 
+	req.Client = ClientInfo(c)
+
 	err := h.userService.ResetPassword(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -200,17 +222,215 @@ func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
 	return response.HandleSuccessAPI(c, http.StatusOK, "Password reset successfully", nil, nil)
 }
 
+// ConsumeLink godoc
+//
+//	@Summary		Consume an email link
+//	@Description	Complete email verification or a password reset with the token from an emailed link. Links are single-use and stop working once a newer code or link is sent. new_password is required for password resets, which revoke every session and personal access token of the user.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ConsumeLinkRequest							true	"Link token"
+//	@Success		200		{object}	response.Response{data=dto.ConsumeLinkResponse}	"Link consumed successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		429		{object}	response.Response	"Too many invalid links, request a new one"
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/consume-link [post]
+func (h *UserHandler) ConsumeLink(c *fiber.Ctx) error {
+	var req dto.ConsumeLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.ConsumeLink(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Link consumed successfully", res, nil)
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Change password
+//	@Description	Change the password of the authenticated user. The current password is required; every other session is signed out and a notification email is sent.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Reauth-Token	header		string						true	"Re-authentication token from /auth/reauthenticate"
+//	@Param			request			body		dto.ChangePasswordRequest	true	"Current and new password"
+//	@Success		200				{object}	response.Response			"Password changed successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		401				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		423				{object}	response.Response	"Account locked, retry after the Retry-After header"
+//	@Failure		429				{object}	response.Response	"Too many failed attempts, retry after the Retry-After header"
+//	@Failure		500				{object}	response.Response
+//	@Router			/auth/change-password [post]
+func (h *UserHandler) ChangePassword(c *fiber.Ctx) error {
+	var req dto.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	payload := middleware.GetUser(c)
+	req.UserID = payload.UserID
+	req.CurrentSessionID = payload.SessionID
+	req.Client = ClientInfo(c)
+
+	err := h.userService.ChangePassword(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Password changed successfully", nil, nil)
+}
+
+// RequestEmailChange godoc
+//
+//	@Summary		Request email change
+//	@Description	Start changing the email address of the authenticated user. A confirmation code is sent to the new address and a notice with a cancel link to the current one. The address is only changed after the code is confirmed.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Reauth-Token	header		string													true	"Re-authentication token from /auth/reauthenticate"
+//	@Param			request			body		dto.RequestEmailChangeRequest							true	"New email address"
+//	@Success		200				{object}	response.Response{data=dto.RequestEmailChangeResponse}	"Email change requested successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		409				{object}	response.Response	"Email already used by another account"
+//	@Failure		429				{object}	response.Response	"A code was sent recently, retry after the Retry-After header"
+//	@Failure		500				{object}	response.Response
+//	@Router			/auth/change-email [post]
+func (h *UserHandler) RequestEmailChange(c *fiber.Ctx) error {
+	var req dto.RequestEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.UserID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.RequestEmailChange(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Email change requested successfully", res, nil)
+}
+
+// ConfirmEmailChange godoc
+//
+//	@Summary		Confirm email change
+//	@Description	Confirm the pending email change with the OTP code sent to the new address
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.ConfirmEmailChangeRequest							true	"OTP code"
+//	@Success		200		{object}	response.Response{data=dto.ConfirmEmailChangeResponse}	"Email changed successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		404		{object}	response.Response	"No pending email change"
+//	@Failure		409		{object}	response.Response	"Email already used by another account"
+//	@Failure		429		{object}	response.Response	"Too many invalid codes, request a new one"
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/change-email/confirm [post]
+func (h *UserHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	var req dto.ConfirmEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.UserID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.ConfirmEmailChange(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Email changed successfully", res, nil)
+}
+
+// CancelEmailChange godoc
+//
+//	@Summary		Cancel email change
+//	@Description	Cancel a pending email change with the token from the notice sent to the current address
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.CancelEmailChangeRequest	true	"Cancel token"
+//	@Success		200		{object}	response.Response				"Email change cancelled successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/change-email/cancel [post]
+func (h *UserHandler) CancelEmailChange(c *fiber.Ctx) error {
+	var req dto.CancelEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	err := h.userService.CancelEmailChange(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Email change cancelled successfully", nil, nil)
+}
+
+// Reauthenticate godoc
+//
+//	@Summary		Re-authenticate
+//	@Description	Confirm the password (and a TOTP or recovery code when 2FA is enabled) to obtain a short-lived re-authentication token. Sensitive endpoints require it in the X-Reauth-Token header, and it is only accepted together with an access token from the same session.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.ReauthenticateRequest							true	"Re-authentication data"
+//	@Success		200		{object}	response.Response{data=dto.ReauthenticateResponse}	"Re-authenticated successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		423		{object}	response.Response	"Account locked, retry after the Retry-After header"
+//	@Failure		429		{object}	response.Response	"Too many failed attempts, retry after the Retry-After header"
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/reauthenticate [post]
+func (h *UserHandler) Reauthenticate(c *fiber.Ctx) error {
+	var req dto.ReauthenticateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	payload := middleware.GetUser(c)
+	req.UserID = payload.UserID
+	req.SessionID = payload.SessionID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.Reauthenticate(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Re-authenticated successfully", res, nil)
+}
+
 // Setup2FA godoc
 //
 //	@Summary		Setup Two-Factor Authentication
-//	@Description	Initiate 2FA setup for the authenticated user
+//	@Description	Start a pending 2FA enrollment for the authenticated user. 2FA is only enabled once the first code is confirmed through verify-2fa before the enrollment expires.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{object}	response.Response{data=dto.Setup2FAResponse}	"2FA setup initiated"
-//	@Failure		400	{object}	response.Response
-//	@Failure		500	{object}	response.Response
+//	@Param			X-Reauth-Token	header		string											true	"Re-authentication token from /auth/reauthenticate"
+//	@Success		200				{object}	response.Response{data=dto.Setup2FAResponse}	"2FA setup initiated"
+//	@Failure		400				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		500				{object}	response.Response
 //	@Router			/auth/setup-2fa [post]
 func (h *UserHandler) Setup2FA(c *fiber.Ctx) error {
 	req := dto.Setup2FARequest{
@@ -228,7 +448,7 @@ func (h *UserHandler) Setup2FA(c *fiber.Ctx) error {
 // Verify2FA godoc
 //
 //	@Summary		Verify Two-Factor Authentication
-//	@Description	Verify 2FA for the authenticated user
+//	@Description	Confirm the pending 2FA enrollment of the authenticated user with the first TOTP code. This enables 2FA, ends all other sessions and returns a new token pair.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
@@ -236,6 +456,8 @@ func (h *UserHandler) Setup2FA(c *fiber.Ctx) error {
 //	@Param			request	body		dto.Verify2FARequest							true	"Verify 2FA data"
 //	@Success		200		{object}	response.Response{data=dto.Verify2FAResponse}	"2FA verified successfully"
 //	@Failure		400		{object}	response.Response
+//	@Failure		423		{object}	response.Response	"Account locked, retry after the Retry-After header"
+//	@Failure		429		{object}	response.Response	"Too many failed attempts, retry after the Retry-After header"
 //	@Failure		500		{object}	response.Response
 //	@Router			/auth/verify-2fa [post]
 func (h *UserHandler) Verify2FA(c *fiber.Ctx) error {
@@ -245,6 +467,7 @@ func (h *UserHandler) Verify2FA(c *fiber.Ctx) error {
 	}
 
 	req.UserID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
 
 	res, err := h.userService.Verify2FA(c.UserContext(), &req)
 	if err != nil {
@@ -254,23 +477,62 @@ func (h *UserHandler) Verify2FA(c *fiber.Ctx) error {
 	return response.HandleSuccessAPI(c, http.StatusOK, "2FA verified successfully", res, nil)
 }
 
+// Complete2FAChallenge godoc
+//
+//	@Summary		Complete 2FA login challenge
+//	@Description	Exchange the MFA challenge token returned by login and a TOTP code (or an unused recovery code) for an access/refresh token pair. Each challenge is single-use and allows a limited number of attempts.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.TwoFactorChallengeRequest							true	"2FA challenge data"
+//	@Success		200		{object}	response.Response{data=dto.TwoFactorChallengeResponse}	"Login Successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		423		{object}	response.Response	"Account locked, retry after the Retry-After header"
+//	@Failure		429		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/2fa/challenge [post]
+func (h *UserHandler) Complete2FAChallenge(c *fiber.Ctx) error {
+	var req dto.TwoFactorChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.Complete2FAChallenge(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Login Successfully", res, nil)
+}
+
 // Disable2FA godoc
 //
 //	@Summary		Disable Two-Factor Authentication
-//	@Description	Disable 2FA for the authenticated user
+//	@Description	Disable 2FA for the authenticated user. Requires a current TOTP code or an unused recovery code. A wrong code counts towards the account lockout.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{object}	response.Response	"2FA disabled successfully"
-//	@Failure		400	{object}	response.Response
-//	@Failure		500	{object}	response.Response
+//	@Param			X-Reauth-Token	header		string					true	"Re-authentication token from /auth/reauthenticate"
+//	@Param			request			body		dto.Disable2FARequest	true	"TOTP code or recovery code"
+//	@Success		200				{object}	response.Response		"2FA disabled successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		423				{object}	response.Response	"Account locked, retry after the Retry-After header"
+//	@Failure		500				{object}	response.Response
 //	@Router			/auth/disable-2fa [post]
 func (h *UserHandler) Disable2FA(c *fiber.Ctx) error {
-	req := dto.Disable2FARequest{
-		UserID: middleware.GetUser(c).UserID,
+	var req dto.Disable2FARequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
 	}
 
+	req.UserID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
 	err := h.userService.Disable2FA(c.UserContext(), &req)
 	if err != nil {
 		return response.HandleErrorAPI(c, err)
@@ -278,3 +540,982 @@ func (h *UserHandler) Disable2FA(c *fiber.Ctx) error {
 
 	return response.HandleSuccessAPI(c, http.StatusOK, "2FA disabled successfully", nil, nil)
 }
+
+// GetProfile godoc
+//
+//	@Summary		Get profile
+//	@Description	Return the profile of the authenticated user
+//	@Tags			profile
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response{data=dto.UserResponse}	"Profile retrieved successfully"
+//	@Failure		401	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/me [get]
+func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
+	req := dto.GetProfileRequest{
+		UserID: middleware.GetUser(c).UserID,
+	}
+
+	res, err := h.userService.GetProfile(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Profile retrieved successfully", res, nil)
+}
+
+// UpdateProfile godoc
+//
+//	@Summary		Update profile
+//	@Description	Update the name and preferences of the authenticated user. Fields that are not sent are left unchanged, an empty last_name removes it. login_alerts_enabled turns the new login alert emails on or off.
+//	@Tags			profile
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.UpdateProfileRequest					true	"Profile fields to change"
+//	@Success		200		{object}	response.Response{data=dto.UserResponse}	"Profile updated successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/me [patch]
+func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
+	var req dto.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.UserID = middleware.GetUser(c).UserID
+
+	res, err := h.userService.UpdateProfile(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Profile updated successfully", res, nil)
+}
+
+// ListSecurityEvents godoc
+//
+//	@Summary		List my security events
+//	@Description	List the authentication activity on the authenticated user's account, such as logins, failed logins, OTP sends, password resets and 2FA changes, newest first.
+//	@Tags			profile
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			current_page	query		int													false	"Page number"		default(1)
+//	@Param			per_page		query		int													false	"Items per page"	default(20)
+//	@Param			event_type		query		string												false	"Filter by event type, e.g. login"
+//	@Param			outcome			query		string												false	"Filter by outcome"			Enums(success, failure)
+//	@Param			sort_by			query		string												false	"Sort by column"			Enums(event_type, outcome, created_at)
+//	@Param			sort_direction	query		string												false	"Sort direction (asc/desc)"	default(asc)
+//	@Success		200				{object}	response.Response{data=[]dto.SecurityEventResponse}	"Security events retrieved successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		401				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/me/security-events [get]
+func (h *UserHandler) ListSecurityEvents(c *fiber.Ctx) error {
+	req := dto.NewListSecurityEventsRequest()
+	if err := c.QueryParser(req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.UserID = middleware.GetUser(c).UserID
+
+	res, err := h.userService.ListSecurityEvents(c.UserContext(), req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Security events retrieved successfully", res.List, res.Paging)
+}
+
+// DeleteAccount godoc
+//
+//	@Summary		Delete account
+//	@Description	Schedule the authenticated account for deletion after a grace period. Every session is signed out and every personal access token revoked; logging in again before the deletion date cancels it.
+//	@Tags			profile
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Reauth-Token	header		string												true	"Re-authentication token from /auth/reauthenticate"
+//	@Success		200				{object}	response.Response{data=dto.DeleteAccountResponse}	"Account scheduled for deletion"
+//	@Failure		401				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/me [delete]
+func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
+	req := dto.DeleteAccountRequest{
+		UserID: middleware.GetUser(c).UserID,
+		Client: ClientInfo(c),
+	}
+
+	res, err := h.userService.DeleteAccount(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Account scheduled for deletion", res, nil)
+}
+
+// GetRecoveryCodesStatus godoc
+//
+//	@Summary		Get recovery codes status
+//	@Description	Return how many unused 2FA recovery codes the authenticated user has left
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response{data=dto.GetRecoveryCodesStatusResponse}	"Recovery codes status retrieved successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/auth/2fa/recovery-codes [get]
+func (h *UserHandler) GetRecoveryCodesStatus(c *fiber.Ctx) error {
+	req := dto.GetRecoveryCodesStatusRequest{
+		UserID: middleware.GetUser(c).UserID,
+	}
+
+	res, err := h.userService.GetRecoveryCodesStatus(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Recovery codes status retrieved successfully", res, nil)
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace all 2FA recovery codes of the authenticated user with a new set. The codes are only shown once.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Reauth-Token	header		string														true	"Re-authentication token from /auth/reauthenticate"
+//	@Success		200				{object}	response.Response{data=dto.RegenerateRecoveryCodesResponse}	"Recovery codes regenerated successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/auth/2fa/recovery-codes [post]
+func (h *UserHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	req := dto.RegenerateRecoveryCodesRequest{
+		UserID: middleware.GetUser(c).UserID,
+		Client: ClientInfo(c),
+	}
+
+	res, err := h.userService.RegenerateRecoveryCodes(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Recovery codes regenerated successfully", res, nil)
+}
+
+// Logout godoc
+//
+//	@Summary		Logout
+//	@Description	Log out the current device. The access token used for this request and every refresh token from the same login are revoked.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.LogoutRequest	true	"Logout data"
+//	@Success		200		{object}	response.Response	"Logged out successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/logout [post]
+func (h *UserHandler) Logout(c *fiber.Ctx) error {
+	var req dto.LogoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	user := middleware.GetUser(c)
+	req.UserID = user.UserID
+	req.TokenID = user.ID
+	req.TokenExpiresAt = user.ExpiresAt
+
+	err := h.userService.Logout(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Logged out successfully", nil, nil)
+}
+
+// UnlockAccount godoc
+//
+//	@Summary		Unlock account
+//	@Description	Lift a temporary lockout using the single-use token from the account locked email
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.UnlockAccountRequest	true	"Unlock token"
+//	@Success		200		{object}	response.Response			"Account unlocked successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/unlock-account [post]
+func (h *UserHandler) UnlockAccount(c *fiber.Ctx) error {
+	var req dto.UnlockAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	err := h.userService.UnlockAccount(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Account unlocked successfully", nil, nil)
+}
+
+// ReportLogin godoc
+//
+//	@Summary		Report an unrecognised login
+//	@Description	Sign out of every session and revoke every personal access token using the single-use token from the "this wasn't me" link of a new login alert email
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ReportLoginRequest	true	"Login alert token"
+//	@Success		200		{object}	response.Response		"All sessions revoked successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/auth/report-login [post]
+func (h *UserHandler) ReportLogin(c *fiber.Ctx) error {
+	var req dto.ReportLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.Client = ClientInfo(c)
+
+	err := h.userService.ReportLogin(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "All sessions revoked successfully", nil, nil)
+}
+
+// AdminUnlockAccount godoc
+//
+//	@Summary		Unlock a user account
+//	@Description	Lift a lockout and reset the failed login counter of a user. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"User ID"
+//	@Success		200	{object}	response.Response	"Account unlocked successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/users/{id}/unlock [post]
+func (h *UserHandler) AdminUnlockAccount(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.AdminUnlockAccountRequest{
+		ActorID: middleware.GetUser(c).UserID,
+		UserID:  userID,
+		Client:  ClientInfo(c),
+	}
+
+	err = h.userService.AdminUnlockAccount(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Account unlocked successfully", nil, nil)
+}
+
+// AdminListUsers godoc
+//
+//	@Summary		List users
+//	@Description	List and search users by email or name with pagination. Requires the users:read permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			current_page	query		int											false	"Page number"		default(1)
+//	@Param			per_page		query		int											false	"Items per page"	default(20)
+//	@Param			keyword			query		string										false	"Search email or name"
+//	@Param			status			query		string										false	"Filter by status"	Enums(active, inactive, pending, suspended)
+//	@Param			role			query		string										false	"Filter by role name"
+//	@Param			sort_by			query		string										false	"Sort by column"			Enums(email, full_name, status, role, created_at, updated_at)
+//	@Param			sort_direction	query		string										false	"Sort direction (asc/desc)"	default(asc)
+//	@Success		200				{object}	response.Response{data=[]dto.UserResponse}	"Users listed successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		401				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/admin/users [get]
+func (h *UserHandler) AdminListUsers(c *fiber.Ctx) error {
+	req := dto.NewAdminListUsersRequest()
+	if err := c.QueryParser(req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.ActorID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.AdminListUsers(c.UserContext(), req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Users listed successfully", res.List, res.Paging)
+}
+
+// AdminListSecurityEvents godoc
+//
+//	@Summary		List security events
+//	@Description	List authentication activity such as logins, failed logins, OTP sends, password resets and 2FA changes across all users, newest first. Requires the security_events:read permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			current_page	query		int													false	"Page number"		default(1)
+//	@Param			per_page		query		int													false	"Items per page"	default(20)
+//	@Param			user_id			query		string												false	"Filter by user ID"
+//	@Param			event_type		query		string												false	"Filter by event type, e.g. login"
+//	@Param			outcome			query		string												false	"Filter by outcome"			Enums(success, failure)
+//	@Param			sort_by			query		string												false	"Sort by column"			Enums(event_type, outcome, created_at)
+//	@Param			sort_direction	query		string												false	"Sort direction (asc/desc)"	default(asc)
+//	@Success		200				{object}	response.Response{data=[]dto.SecurityEventResponse}	"Security events listed successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		401				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/admin/security-events [get]
+func (h *UserHandler) AdminListSecurityEvents(c *fiber.Ctx) error {
+	req := dto.NewAdminListSecurityEventsRequest()
+	if err := c.QueryParser(req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.ActorID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.AdminListSecurityEvents(c.UserContext(), req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Security events listed successfully", res.List, res.Paging)
+}
+
+// AdminGetUser godoc
+//
+//	@Summary		Get a user
+//	@Description	Return a single user. Requires the users:read permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string										true	"User ID"
+//	@Success		200	{object}	response.Response{data=dto.UserResponse}	"User retrieved successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/users/{id} [get]
+func (h *UserHandler) AdminGetUser(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.AdminGetUserRequest{
+		ActorID: middleware.GetUser(c).UserID,
+		UserID:  userID,
+		Client:  ClientInfo(c),
+	}
+
+	res, err := h.userService.AdminGetUser(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "User retrieved successfully", res, nil)
+}
+
+// AdminSuspendUser godoc
+//
+//	@Summary		Suspend a user
+//	@Description	Suspend a user, sign them out of every session and revoke their personal access tokens. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string										true	"User ID"
+//	@Param			request	body		dto.AdminSuspendUserRequest					false	"Reason for the audit log"
+//	@Success		200		{object}	response.Response{data=dto.UserResponse}	"User suspended successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		403		{object}	response.Response
+//	@Failure		404		{object}	response.Response
+//	@Failure		409		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/admin/users/{id}/suspend [post]
+func (h *UserHandler) AdminSuspendUser(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	var req dto.AdminSuspendUserRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.HandleErrorAPI(c, err)
+		}
+	}
+
+	req.ActorID = middleware.GetUser(c).UserID
+	req.UserID = userID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.AdminSuspendUser(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "User suspended successfully", res, nil)
+}
+
+// AdminReactivateUser godoc
+//
+//	@Summary		Reactivate a user
+//	@Description	Make a suspended or inactive user active again. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string										true	"User ID"
+//	@Success		200	{object}	response.Response{data=dto.UserResponse}	"User reactivated successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		409	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/users/{id}/reactivate [post]
+func (h *UserHandler) AdminReactivateUser(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.AdminReactivateUserRequest{
+		ActorID: middleware.GetUser(c).UserID,
+		UserID:  userID,
+		Client:  ClientInfo(c),
+	}
+
+	res, err := h.userService.AdminReactivateUser(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "User reactivated successfully", res, nil)
+}
+
+// AdminVerifyEmail godoc
+//
+//	@Summary		Verify a user email
+//	@Description	Mark the email of a user as verified and activate a pending account. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string										true	"User ID"
+//	@Success		200	{object}	response.Response{data=dto.UserResponse}	"User email verified successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		409	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/users/{id}/verify-email [post]
+func (h *UserHandler) AdminVerifyEmail(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.AdminVerifyEmailRequest{
+		ActorID: middleware.GetUser(c).UserID,
+		UserID:  userID,
+		Client:  ClientInfo(c),
+	}
+
+	res, err := h.userService.AdminVerifyEmail(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "User email verified successfully", res, nil)
+}
+
+// AdminReset2FA godoc
+//
+//	@Summary		Reset user 2FA
+//	@Description	Disable 2FA for a user, remove their recovery codes and sign them out of every session. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string										true	"User ID"
+//	@Success		200	{object}	response.Response{data=dto.UserResponse}	"User 2FA reset successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		409	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/users/{id}/reset-2fa [post]
+func (h *UserHandler) AdminReset2FA(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.AdminReset2FARequest{
+		ActorID: middleware.GetUser(c).UserID,
+		UserID:  userID,
+		Client:  ClientInfo(c),
+	}
+
+	res, err := h.userService.AdminReset2FA(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "User 2FA reset successfully", res, nil)
+}
+
+// AdminResetPassword godoc
+//
+//	@Summary		Trigger a password reset
+//	@Description	Send the user a password reset code or link, the same as forgot password. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"User ID"
+//	@Success		200	{object}	response.Response	"Password reset email sent successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		429	{object}	response.Response	"A code was sent recently, retry after the Retry-After header"
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/users/{id}/reset-password [post]
+func (h *UserHandler) AdminResetPassword(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.AdminResetPasswordRequest{
+		ActorID: middleware.GetUser(c).UserID,
+		UserID:  userID,
+		Client:  ClientInfo(c),
+	}
+
+	err = h.userService.AdminResetPassword(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Password reset email sent successfully", nil, nil)
+}
+
+// AdminListRoles godoc
+//
+//	@Summary		List roles
+//	@Description	List every role with the permissions it grants. Requires the roles:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response{data=dto.AdminListRolesResponse}	"Roles listed successfully"
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/roles [get]
+func (h *UserHandler) AdminListRoles(c *fiber.Ctx) error {
+	req := dto.AdminListRolesRequest{
+		ActorID: middleware.GetUser(c).UserID,
+	}
+
+	res, err := h.userService.AdminListRoles(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Roles listed successfully", res, nil)
+}
+
+// AdminAssignRole godoc
+//
+//	@Summary		Assign a role
+//	@Description	Change the role of a user. The user is signed out of every session, so the new permissions apply immediately. Requires the roles:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string										true	"User ID"
+//	@Param			request	body		dto.AdminAssignRoleRequest					true	"Role to assign"
+//	@Success		200		{object}	response.Response{data=dto.UserResponse}	"Role assigned successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		403		{object}	response.Response
+//	@Failure		404		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Router			/admin/users/{id}/role [put]
+func (h *UserHandler) AdminAssignRole(c *fiber.Ctx) error {
+	userID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	var req dto.AdminAssignRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.ActorID = middleware.GetUser(c).UserID
+	req.UserID = userID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.AdminAssignRole(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Role assigned successfully", res, nil)
+}
+
+// AdminCreateInvite godoc
+//
+//	@Summary		Invite a user
+//	@Description	Email an invite to register with the given address. Pending invites to the same address are revoked. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.AdminCreateInviteRequest				true	"Address to invite"
+//	@Success		201		{object}	response.Response{data=dto.InviteResponse}	"Invite sent successfully"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		403		{object}	response.Response
+//	@Failure		409		{object}	response.Response	"An account already uses the address"
+//	@Failure		500		{object}	response.Response
+//	@Router			/admin/invites [post]
+func (h *UserHandler) AdminCreateInvite(c *fiber.Ctx) error {
+	var req dto.AdminCreateInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.ActorID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.AdminCreateInvite(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusCreated, "Invite sent successfully", res, nil)
+}
+
+// AdminListInvites godoc
+//
+//	@Summary		List invites
+//	@Description	List invites with their inviter, expiry and redemption, newest first. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			current_page	query		int												false	"Page number"		default(1)
+//	@Param			per_page		query		int												false	"Items per page"	default(20)
+//	@Param			email			query		string											false	"Filter by invited email"
+//	@Param			status			query		string											false	"Filter by status"			Enums(pending, redeemed, revoked, expired)
+//	@Param			sort_by			query		string											false	"Sort by column"			Enums(email, expires_at, created_at)
+//	@Param			sort_direction	query		string											false	"Sort direction (asc/desc)"	default(asc)
+//	@Success		200				{object}	response.Response{data=[]dto.InviteResponse}	"Invites listed successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		401				{object}	response.Response
+//	@Failure		403				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/admin/invites [get]
+func (h *UserHandler) AdminListInvites(c *fiber.Ctx) error {
+	req := dto.NewAdminListInvitesRequest()
+	if err := c.QueryParser(req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.ActorID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.AdminListInvites(c.UserContext(), req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Invites listed successfully", res.List, res.Paging)
+}
+
+// AdminRevokeInvite godoc
+//
+//	@Summary		Revoke an invite
+//	@Description	Withdraw a pending invite so its link stops working. Requires the users:manage permission.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"Invite ID"
+//	@Success		200	{object}	response.Response	"Invite revoked successfully"
+//	@Failure		400	{object}	response.Response	"The invite was already redeemed or revoked"
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/admin/invites/{id} [delete]
+func (h *UserHandler) AdminRevokeInvite(c *fiber.Ctx) error {
+	inviteID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.AdminRevokeInviteRequest{
+		ActorID:  middleware.GetUser(c).UserID,
+		InviteID: inviteID,
+		Client:   ClientInfo(c),
+	}
+
+	err = h.userService.AdminRevokeInvite(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Invite revoked successfully", nil, nil)
+}
+
+// ListSessions godoc
+//
+//	@Summary		List active sessions
+//	@Description	List the devices the authenticated user is signed in on, most recently used first. The session of the current access token is flagged as current.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response{data=dto.ListSessionsResponse}	"Sessions retrieved successfully"
+//	@Failure		401	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/auth/sessions [get]
+func (h *UserHandler) ListSessions(c *fiber.Ctx) error {
+	payload := middleware.GetUser(c)
+	req := dto.ListSessionsRequest{
+		UserID:           payload.UserID,
+		CurrentSessionID: payload.SessionID,
+	}
+
+	res, err := h.userService.ListSessions(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Sessions retrieved successfully", res, nil)
+}
+
+// RevokeSession godoc
+//
+//	@Summary		Revoke a session
+//	@Description	Sign the authenticated user out of one session. Its refresh token can no longer be used and its access tokens are rejected immediately.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"Session ID"
+//	@Success		200	{object}	response.Response	"Session revoked successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/auth/sessions/{id} [delete]
+func (h *UserHandler) RevokeSession(c *fiber.Ctx) error {
+	sessionID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.RevokeSessionRequest{
+		UserID:    middleware.GetUser(c).UserID,
+		SessionID: sessionID,
+	}
+
+	err = h.userService.RevokeSession(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Session revoked successfully", nil, nil)
+}
+
+// CreatePersonalAccessToken godoc
+//
+//	@Summary		Create a personal access token
+//	@Description	Create a long-lived token for scripts and integrations, sent as "Authorization: Bearer goca_pat_...". The token value is only returned once. Scopes are notes:read, notes:write, profile:read, profile:write or a permission granted by the user's role.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Reauth-Token	header		string															true	"Re-authentication token from /auth/reauthenticate"
+//	@Param			request			body		dto.CreatePersonalAccessTokenRequest							true	"Token name, scopes and optional expiry"
+//	@Success		201				{object}	response.Response{data=dto.CreatePersonalAccessTokenResponse}	"Personal access token created successfully"
+//	@Failure		400				{object}	response.Response
+//	@Failure		401				{object}	response.Response
+//	@Failure		429				{object}	response.Response
+//	@Failure		500				{object}	response.Response
+//	@Router			/auth/access-tokens [post]
+func (h *UserHandler) CreatePersonalAccessToken(c *fiber.Ctx) error {
+	var req dto.CreatePersonalAccessTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req.UserID = middleware.GetUser(c).UserID
+	req.Client = ClientInfo(c)
+
+	res, err := h.userService.CreatePersonalAccessToken(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusCreated, "Personal access token created successfully", res, nil)
+}
+
+// ListPersonalAccessTokens godoc
+//
+//	@Summary		List personal access tokens
+//	@Description	List the personal access tokens of the authenticated user that have not been revoked, with their scopes and when they were last used.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response{data=dto.ListPersonalAccessTokensResponse}	"Personal access tokens retrieved successfully"
+//	@Failure		401	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/auth/access-tokens [get]
+func (h *UserHandler) ListPersonalAccessTokens(c *fiber.Ctx) error {
+	req := dto.ListPersonalAccessTokensRequest{
+		UserID: middleware.GetUser(c).UserID,
+	}
+
+	res, err := h.userService.ListPersonalAccessTokens(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Personal access tokens retrieved successfully", res, nil)
+}
+
+// RevokePersonalAccessToken godoc
+//
+//	@Summary		Revoke a personal access token
+//	@Description	Revoke one personal access token of the authenticated user. Requests using it are rejected immediately.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"Personal access token ID"
+//	@Success		200	{object}	response.Response	"Personal access token revoked successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/auth/access-tokens/{id} [delete]
+func (h *UserHandler) RevokePersonalAccessToken(c *fiber.Ctx) error {
+	tokenID, err := request.ParseUUIDParam(c, "id")
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	req := dto.RevokePersonalAccessTokenRequest{
+		UserID:  middleware.GetUser(c).UserID,
+		TokenID: tokenID,
+		Client:  ClientInfo(c),
+	}
+
+	err = h.userService.RevokePersonalAccessToken(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Personal access token revoked successfully", nil, nil)
+}
+
+// RevokeOtherSessions godoc
+//
+//	@Summary		Revoke all other sessions
+//	@Description	Sign the authenticated user out of every session except the one of the current access token
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response{data=dto.RevokeOtherSessionsResponse}	"Other sessions revoked successfully"
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/auth/sessions/revoke-others [post]
+func (h *UserHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	payload := middleware.GetUser(c)
+	req := dto.RevokeOtherSessionsRequest{
+		UserID:           payload.UserID,
+		CurrentSessionID: payload.SessionID,
+	}
+
+	res, err := h.userService.RevokeOtherSessions(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Other sessions revoked successfully", res, nil)
+}
+
+// LogoutAll godoc
+//
+//	@Summary		Logout from all devices
+//	@Description	Revoke every access and refresh token of the authenticated user. Personal access tokens keep working; revoke them individually.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response	"Logged out from all devices successfully"
+//	@Failure		401	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Router			/auth/logout-all [post]
+func (h *UserHandler) LogoutAll(c *fiber.Ctx) error {
+	req := dto.LogoutAllRequest{
+		UserID: middleware.GetUser(c).UserID,
+	}
+
+	err := h.userService.LogoutAll(c.UserContext(), &req)
+	if err != nil {
+		return response.HandleErrorAPI(c, err)
+	}
+
+	return response.HandleSuccessAPI(c, http.StatusOK, "Logged out from all devices successfully", nil, nil)
+}
@@ -14,7 +14,44 @@ type UserService interface {
 	RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error)
 	ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error
 	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+	ConsumeLink(ctx context.Context, req *dto.ConsumeLinkRequest) (*dto.ConsumeLinkResponse, error)
+	ChangePassword(ctx context.Context, req *dto.ChangePasswordRequest) error
+	RequestEmailChange(ctx context.Context, req *dto.RequestEmailChangeRequest) (*dto.RequestEmailChangeResponse, error)
+	ConfirmEmailChange(ctx context.Context, req *dto.ConfirmEmailChangeRequest) (*dto.ConfirmEmailChangeResponse, error)
+	CancelEmailChange(ctx context.Context, req *dto.CancelEmailChangeRequest) error
+	Reauthenticate(ctx context.Context, req *dto.ReauthenticateRequest) (*dto.ReauthenticateResponse, error)
 	Setup2FA(ctx context.Context, req *dto.Setup2FARequest) (*dto.Setup2FAResponse, error)
 	Verify2FA(ctx context.Context, req *dto.Verify2FARequest) (*dto.Verify2FAResponse, error)
+	Complete2FAChallenge(ctx context.Context, req *dto.TwoFactorChallengeRequest) (*dto.TwoFactorChallengeResponse, error)
 	Disable2FA(ctx context.Context, req *dto.Disable2FARequest) error
+	GetProfile(ctx context.Context, req *dto.GetProfileRequest) (*dto.UserResponse, error)
+	UpdateProfile(ctx context.Context, req *dto.UpdateProfileRequest) (*dto.UserResponse, error)
+	DeleteAccount(ctx context.Context, req *dto.DeleteAccountRequest) (*dto.DeleteAccountResponse, error)
+	GetRecoveryCodesStatus(ctx context.Context, req *dto.GetRecoveryCodesStatusRequest) (*dto.GetRecoveryCodesStatusResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, req *dto.RegenerateRecoveryCodesRequest) (*dto.RegenerateRecoveryCodesResponse, error)
+	Logout(ctx context.Context, req *dto.LogoutRequest) error
+	LogoutAll(ctx context.Context, req *dto.LogoutAllRequest) error
+	ListSessions(ctx context.Context, req *dto.ListSessionsRequest) (*dto.ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, req *dto.RevokeSessionRequest) error
+	CreatePersonalAccessToken(ctx context.Context, req *dto.CreatePersonalAccessTokenRequest) (*dto.CreatePersonalAccessTokenResponse, error)
+	ListPersonalAccessTokens(ctx context.Context, req *dto.ListPersonalAccessTokensRequest) (*dto.ListPersonalAccessTokensResponse, error)
+	RevokePersonalAccessToken(ctx context.Context, req *dto.RevokePersonalAccessTokenRequest) error
+	RevokeOtherSessions(ctx context.Context, req *dto.RevokeOtherSessionsRequest) (*dto.RevokeOtherSessionsResponse, error)
+	UnlockAccount(ctx context.Context, req *dto.UnlockAccountRequest) error
+	ReportLogin(ctx context.Context, req *dto.ReportLoginRequest) error
+	AdminUnlockAccount(ctx context.Context, req *dto.AdminUnlockAccountRequest) error
+	AdminListUsers(ctx context.Context, req *dto.AdminListUsersRequest) (*dto.AdminListUsersResponse, error)
+	AdminGetUser(ctx context.Context, req *dto.AdminGetUserRequest) (*dto.UserResponse, error)
+	AdminSuspendUser(ctx context.Context, req *dto.AdminSuspendUserRequest) (*dto.UserResponse, error)
+	AdminReactivateUser(ctx context.Context, req *dto.AdminReactivateUserRequest) (*dto.UserResponse, error)
+	AdminVerifyEmail(ctx context.Context, req *dto.AdminVerifyEmailRequest) (*dto.UserResponse, error)
+	AdminReset2FA(ctx context.Context, req *dto.AdminReset2FARequest) (*dto.UserResponse, error)
+	AdminResetPassword(ctx context.Context, req *dto.AdminResetPasswordRequest) error
+	AdminListSecurityEvents(ctx context.Context, req *dto.AdminListSecurityEventsRequest) (*dto.ListSecurityEventsResponse, error)
+	ListSecurityEvents(ctx context.Context, req *dto.ListSecurityEventsRequest) (*dto.ListSecurityEventsResponse, error)
+	AdminListRoles(ctx context.Context, req *dto.AdminListRolesRequest) (*dto.AdminListRolesResponse, error)
+	AdminAssignRole(ctx context.Context, req *dto.AdminAssignRoleRequest) (*dto.UserResponse, error)
+	AdminCreateInvite(ctx context.Context, req *dto.AdminCreateInviteRequest) (*dto.InviteResponse, error)
+	AdminListInvites(ctx context.Context, req *dto.AdminListInvitesRequest) (*dto.ListInvitesResponse, error)
+	AdminRevokeInvite(ctx context.Context, req *dto.AdminRevokeInviteRequest) error
 }
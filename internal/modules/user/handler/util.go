@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sammidev/goca/internal/modules/user/dto"
+)
+
+// ClientInfo collects the details of the client that are stored with a session.
+func ClientInfo(c *fiber.Ctx) dto.ClientInfo {
+	return dto.ClientInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+type AdminAuditLogPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewAdminAuditLogPostgresRepository(db *database.PostgreSQLDatabase) *AdminAuditLogPostgresRepository {
+	return &AdminAuditLogPostgresRepository{
+		db: db,
+	}
+}
+
+func (r *AdminAuditLogPostgresRepository) Create(ctx context.Context, log *entity.AdminAuditLog) error {
+	metadata := log.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	builder := sq.Insert("admin_audit_logs").Columns(
+		"id", "actor_id", "target_user_id", "action", "metadata", "ip_address", "user_agent", "created_at",
+	).Values(
+		log.ID, log.ActorID, log.TargetUserID, log.Action, metadata, log.IPAddress, log.UserAgent, log.CreatedAt,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to create admin audit log")
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+type EmailChangeRequestPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewEmailChangeRequestPostgresRepository(db *database.PostgreSQLDatabase) *EmailChangeRequestPostgresRepository {
+	return &EmailChangeRequestPostgresRepository{
+		db: db,
+	}
+}
+
+func (r *EmailChangeRequestPostgresRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.EmailChangeRequest, error) {
+	builder := sq.Select("user_id, new_email, cancel_token_id, expires_at, created_at").
+		From("email_change_requests").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var request entity.EmailChangeRequest
+	err = sqlExecutor.QueryRow(ctx, sql, args...).Scan(
+		&request.UserID, &request.NewEmail, &request.CancelTokenID, &request.ExpiresAt, &request.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve email change request")
+	}
+
+	return &request, nil
+}
+
+// Upsert stores the request, replacing any pending request the user already had.
+func (r *EmailChangeRequestPostgresRepository) Upsert(ctx context.Context, request *entity.EmailChangeRequest) error {
+	builder := sq.Insert("email_change_requests").Columns(
+		"user_id", "new_email", "cancel_token_id", "expires_at", "created_at",
+	).Values(
+		request.UserID, request.NewEmail, request.CancelTokenID, request.ExpiresAt, request.CreatedAt,
+	).Suffix(
+		"ON CONFLICT (user_id) DO UPDATE SET new_email = EXCLUDED.new_email, cancel_token_id = EXCLUDED.cancel_token_id, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at",
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to save email change request")
+	}
+
+	return nil
+}
+
+func (r *EmailChangeRequestPostgresRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	builder := sq.Delete("email_change_requests").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete email change request")
+	}
+
+	return nil
+}
+
+// DeleteExpired removes requests that expired before the given time and returns how many were deleted.
+func (r *EmailChangeRequestPostgresRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("email_change_requests").
+		Where(sq.Lt{"expires_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete expired email change requests")
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,283 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/dto"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+	"github.com/sammidev/goca/internal/pkg/request"
+)
+
+const inviteColumns = "id, email, token_hash, invited_by, expires_at, redeemed_at, redeemed_by, revoked_at, created_at"
+
+// inviteSortColumns maps the sort_by values accepted from clients to columns.
+var inviteSortColumns = map[string]string{
+	"email":      "email",
+	"expires_at": "expires_at",
+	"created_at": "created_at",
+}
+
+// pendingInvite matches invites that can still be redeemed or revoked.
+var pendingInvite = sq.Eq{"redeemed_at": nil, "revoked_at": nil}
+
+func scanInvite(row pgx.Row) (*entity.Invite, error) {
+	var invite entity.Invite
+	err := row.Scan(
+		&invite.ID, &invite.Email, &invite.TokenHash, &invite.InvitedBy, &invite.ExpiresAt,
+		&invite.RedeemedAt, &invite.RedeemedBy, &invite.RevokedAt, &invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+type InvitePostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewInvitePostgresRepository(db *database.PostgreSQLDatabase) *InvitePostgresRepository {
+	return &InvitePostgresRepository{
+		db: db,
+	}
+}
+
+func (r *InvitePostgresRepository) Create(ctx context.Context, invite *entity.Invite) error {
+	builder := sq.Insert("invites").Columns(
+		"id", "email", "token_hash", "invited_by", "expires_at", "created_at",
+	).Values(
+		invite.ID, invite.Email, invite.TokenHash, invite.InvitedBy, invite.ExpiresAt, invite.CreatedAt,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to create invite")
+	}
+
+	return nil
+}
+
+func (r *InvitePostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Invite, error) {
+	return r.getOne(ctx, sq.Eq{"id": id})
+}
+
+func (r *InvitePostgresRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invite, error) {
+	return r.getOne(ctx, sq.Eq{"token_hash": tokenHash})
+}
+
+func (r *InvitePostgresRepository) getOne(ctx context.Context, where sq.Eq) (*entity.Invite, error) {
+	builder := sq.Select(inviteColumns).
+		From("invites").
+		Where(where).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := scanInvite(sqlExecutor.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve invite")
+	}
+
+	return invite, nil
+}
+
+// FindAll returns a page of invites, newest first unless another sort is requested.
+func (r *InvitePostgresRepository) FindAll(ctx context.Context, filter *dto.InviteFilter) (*dto.ListInvitesResponse, error) {
+	orderBy := "created_at DESC"
+	if filter.HasSort() {
+		column, ok := inviteSortColumns[filter.SortBy]
+		if !ok {
+			return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Unsupported sort_by column")
+		}
+		orderBy = column + " ASC"
+		if filter.IsDesc() {
+			orderBy = column + " DESC"
+		}
+	}
+
+	now := time.Now()
+
+	baseBuilder := sq.Select().
+		From("invites").
+		PlaceholderFormat(sq.Dollar)
+
+	if filter.Email != "" {
+		baseBuilder = baseBuilder.Where("LOWER(email) = LOWER(?)", filter.Email)
+	}
+
+	switch entity.InviteStatus(filter.Status) {
+	case entity.InviteStatusPending:
+		baseBuilder = baseBuilder.Where(pendingInvite).Where(sq.Gt{"expires_at": now})
+	case entity.InviteStatusRedeemed:
+		baseBuilder = baseBuilder.Where(sq.NotEq{"redeemed_at": nil})
+	case entity.InviteStatusRevoked:
+		baseBuilder = baseBuilder.Where(sq.Eq{"redeemed_at": nil}).Where(sq.NotEq{"revoked_at": nil})
+	case entity.InviteStatusExpired:
+		baseBuilder = baseBuilder.Where(pendingInvite).Where(sq.LtOrEq{"expires_at": now})
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalData int
+	if !filter.IsUnlimitedPage() {
+		countSql, countArgs, err := baseBuilder.Column("COUNT(*)").ToSql()
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build count query")
+		}
+
+		err = sqlExecutor.QueryRow(ctx, countSql, countArgs...).Scan(&totalData)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to execute count query")
+		}
+	}
+
+	dataBuilder := baseBuilder.Columns(inviteColumns).OrderBy(orderBy)
+	if !filter.IsUnlimitedPage() {
+		dataBuilder = dataBuilder.Limit(uint64(filter.GetLimit())).Offset(uint64(filter.GetOffset()))
+	}
+
+	sql, args, err := dataBuilder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build data query")
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve invites")
+	}
+	defer rows.Close()
+
+	invites := make([]*dto.InviteResponse, 0)
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan invite")
+		}
+		invites = append(invites, dto.InviteEntityToInviteResponse(invite, now))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve invites")
+	}
+
+	if filter.IsUnlimitedPage() {
+		totalData = len(invites)
+	}
+
+	paging, err := request.NewPaging(filter.CurrentPage, filter.PerPage, totalData)
+	if err != nil {
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, err.Error())
+	}
+
+	return &dto.ListInvitesResponse{
+		List:   invites,
+		Paging: paging,
+	}, nil
+}
+
+// MarkRedeemed records that the user registered with the invite. It returns
+// ErrNotFound when the invite was redeemed, revoked or expired in the meantime, so an
+// invite can never be redeemed twice concurrently.
+func (r *InvitePostgresRepository) MarkRedeemed(ctx context.Context, id, userID uuid.UUID, redeemedAt time.Time) error {
+	builder := sq.Update("invites").
+		Set("redeemed_at", redeemedAt).
+		Set("redeemed_by", userID).
+		Where(sq.Eq{"id": id}).
+		Where(pendingInvite).
+		Where(sq.Gt{"expires_at": redeemedAt}).
+		PlaceholderFormat(sq.Dollar)
+
+	return r.execPendingUpdate(ctx, builder, "Failed to redeem invite")
+}
+
+// MarkRevoked withdraws a pending invite. It returns ErrNotFound when the invite does
+// not exist or was already redeemed or revoked.
+func (r *InvitePostgresRepository) MarkRevoked(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	builder := sq.Update("invites").
+		Set("revoked_at", revokedAt).
+		Where(sq.Eq{"id": id}).
+		Where(pendingInvite).
+		PlaceholderFormat(sq.Dollar)
+
+	return r.execPendingUpdate(ctx, builder, "Failed to revoke invite")
+}
+
+func (r *InvitePostgresRepository) execPendingUpdate(ctx context.Context, builder sq.UpdateBuilder, message string) error {
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, message)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// RevokePendingByEmail withdraws every pending invite sent to the address, ignoring
+// case, and returns how many were revoked.
+func (r *InvitePostgresRepository) RevokePendingByEmail(ctx context.Context, email string, revokedAt time.Time) (int64, error) {
+	builder := sq.Update("invites").
+		Set("revoked_at", revokedAt).
+		Where("LOWER(email) = LOWER(?)", email).
+		Where(pendingInvite).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to revoke invites")
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+const knownDeviceColumns = "id, user_id, fingerprint, ip_range, device, location, first_seen_at, last_seen_at"
+
+func scanKnownDevice(row pgx.Row) (*entity.KnownDevice, error) {
+	var device entity.KnownDevice
+	err := row.Scan(
+		&device.ID, &device.UserID, &device.Fingerprint, &device.IPRange, &device.Device, &device.Location,
+		&device.FirstSeenAt, &device.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+type KnownDevicePostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewKnownDevicePostgresRepository(db *database.PostgreSQLDatabase) *KnownDevicePostgresRepository {
+	return &KnownDevicePostgresRepository{
+		db: db,
+	}
+}
+
+// ListByUserID returns every device the user has signed in from, most recently seen first.
+func (r *KnownDevicePostgresRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.KnownDevice, error) {
+	builder := sq.Select(knownDeviceColumns).
+		From("known_devices").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("last_seen_at DESC").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve known devices")
+	}
+	defer rows.Close()
+
+	devices := make([]*entity.KnownDevice, 0)
+	for rows.Next() {
+		device, err := scanKnownDevice(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan known device")
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to iterate known devices")
+	}
+
+	return devices, nil
+}
+
+// Upsert records a sign-in from the device. Signing in again from a known
+// fingerprint and IP range only refreshes its description and last-seen time.
+func (r *KnownDevicePostgresRepository) Upsert(ctx context.Context, device *entity.KnownDevice) error {
+	builder := sq.Insert("known_devices").Columns(
+		"id", "user_id", "fingerprint", "ip_range", "device", "location", "first_seen_at", "last_seen_at",
+	).Values(
+		device.ID, device.UserID, device.Fingerprint, device.IPRange, device.Device, device.Location,
+		device.FirstSeenAt, device.LastSeenAt,
+	).Suffix(
+		"ON CONFLICT (user_id, fingerprint, ip_range) DO UPDATE SET device = EXCLUDED.device, location = EXCLUDED.location, last_seen_at = EXCLUDED.last_seen_at",
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to save known device")
+	}
+
+	return nil
+}
+
+// DeleteNotSeenSince forgets devices that have not been used to sign in since the given time.
+func (r *KnownDevicePostgresRepository) DeleteNotSeenSince(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("known_devices").
+		Where(sq.Lt{"last_seen_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete stale known devices")
+	}
+
+	return tag.RowsAffected(), nil
+}
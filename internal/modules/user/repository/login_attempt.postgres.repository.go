@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+type LoginAttemptPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewLoginAttemptPostgresRepository(db *database.PostgreSQLDatabase) *LoginAttemptPostgresRepository {
+	return &LoginAttemptPostgresRepository{
+		db: db,
+	}
+}
+
+func (r *LoginAttemptPostgresRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.LoginAttempt, error) {
+	builder := sq.Select("user_id, failed_count, last_failed_at, locked_until").
+		From("login_attempts").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var attempt entity.LoginAttempt
+	err = sqlExecutor.QueryRow(ctx, sql, args...).Scan(
+		&attempt.UserID, &attempt.FailedCount, &attempt.LastFailedAt, &attempt.LockedUntil,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve login attempts")
+	}
+
+	return &attempt, nil
+}
+
+// RegisterFailure atomically counts a failed login and returns the updated record.
+// Failures older than windowStart no longer count, so the counter starts over at one.
+func (r *LoginAttemptPostgresRepository) RegisterFailure(ctx context.Context, userID uuid.UUID, failedAt, windowStart time.Time) (*entity.LoginAttempt, error) {
+	builder := sq.Insert("login_attempts").Columns(
+		"user_id", "failed_count", "last_failed_at",
+	).Values(
+		userID, 1, failedAt,
+	).Suffix(
+		`ON CONFLICT (user_id) DO UPDATE SET
+			failed_count = CASE WHEN login_attempts.last_failed_at >= ? THEN login_attempts.failed_count + 1 ELSE 1 END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING user_id, failed_count, last_failed_at, locked_until`,
+		windowStart,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var attempt entity.LoginAttempt
+	err = sqlExecutor.QueryRow(ctx, sql, args...).Scan(
+		&attempt.UserID, &attempt.FailedCount, &attempt.LastFailedAt, &attempt.LockedUntil,
+	)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to register failed login")
+	}
+
+	return &attempt, nil
+}
+
+func (r *LoginAttemptPostgresRepository) Lock(ctx context.Context, userID uuid.UUID, lockedUntil time.Time) error {
+	builder := sq.Update("login_attempts").
+		Set("locked_until", lockedUntil).
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to lock account")
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptPostgresRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	builder := sq.Delete("login_attempts").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete login attempts")
+	}
+
+	return nil
+}
+
+// DeleteExpired removes records whose last failure and lock both ended before the given time
+// and returns how many were deleted.
+func (r *LoginAttemptPostgresRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("login_attempts").
+		Where(sq.Lt{"last_failed_at": before}).
+		Where(sq.Or{sq.Eq{"locked_until": nil}, sq.Lt{"locked_until": before}}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete expired login attempts")
+	}
+
+	return tag.RowsAffected(), nil
+}
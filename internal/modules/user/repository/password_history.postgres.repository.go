@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+const passwordHistoryColumns = "id, user_id, password_hash, created_at"
+
+func scanPasswordHistory(row pgx.Row) (*entity.PasswordHistory, error) {
+	var history entity.PasswordHistory
+	err := row.Scan(&history.ID, &history.UserID, &history.PasswordHash, &history.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+type PasswordHistoryPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewPasswordHistoryPostgresRepository(db *database.PostgreSQLDatabase) *PasswordHistoryPostgresRepository {
+	return &PasswordHistoryPostgresRepository{
+		db: db,
+	}
+}
+
+// ListRecentByUserID returns up to limit of the user's previous passwords, newest first.
+func (r *PasswordHistoryPostgresRepository) ListRecentByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.PasswordHistory, error) {
+	builder := sq.Select(passwordHistoryColumns).
+		From("password_histories").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve password history")
+	}
+	defer rows.Close()
+
+	histories := make([]*entity.PasswordHistory, 0)
+	for rows.Next() {
+		history, err := scanPasswordHistory(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan password history")
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to iterate password history")
+	}
+
+	return histories, nil
+}
+
+func (r *PasswordHistoryPostgresRepository) Create(ctx context.Context, history *entity.PasswordHistory) error {
+	builder := sq.Insert("password_histories").Columns(
+		"id", "user_id", "password_hash", "created_at",
+	).Values(
+		history.ID, history.UserID, history.PasswordHash, history.CreatedAt,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to save password history")
+	}
+
+	return nil
+}
+
+// DeleteAllButNewest trims the user's history down to the keep most recent entries.
+func (r *PasswordHistoryPostgresRepository) DeleteAllButNewest(ctx context.Context, userID uuid.UUID, keep int) error {
+	newest := sq.Select("id").
+		From("password_histories").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(keep))
+
+	newestSQL, newestArgs, err := newest.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	builder := sq.Delete("password_histories").
+		Where(sq.Eq{"user_id": userID}).
+		Where("id NOT IN ("+newestSQL+")", newestArgs...).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to prune password history")
+	}
+
+	return nil
+}
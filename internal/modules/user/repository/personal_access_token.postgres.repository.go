@@ -0,0 +1,222 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+const personalAccessTokenColumns = "id, user_id, name, token_hash, token_hint, scopes, expires_at, last_used_at, last_used_ip, created_at, revoked_at"
+
+func scanPersonalAccessToken(row pgx.Row) (*entity.PersonalAccessToken, error) {
+	var pat entity.PersonalAccessToken
+	err := row.Scan(
+		&pat.ID, &pat.UserID, &pat.Name, &pat.TokenHash, &pat.TokenHint, &pat.Scopes,
+		&pat.ExpiresAt, &pat.LastUsedAt, &pat.LastUsedIP, &pat.CreatedAt, &pat.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pat, nil
+}
+
+type PersonalAccessTokenPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewPersonalAccessTokenPostgresRepository(db *database.PostgreSQLDatabase) *PersonalAccessTokenPostgresRepository {
+	return &PersonalAccessTokenPostgresRepository{
+		db: db,
+	}
+}
+
+func (r *PersonalAccessTokenPostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PersonalAccessToken, error) {
+	return r.getOne(ctx, sq.Eq{"id": id})
+}
+
+func (r *PersonalAccessTokenPostgresRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error) {
+	return r.getOne(ctx, sq.Eq{"token_hash": tokenHash})
+}
+
+func (r *PersonalAccessTokenPostgresRepository) getOne(ctx context.Context, where sq.Eq) (*entity.PersonalAccessToken, error) {
+	builder := sq.Select(personalAccessTokenColumns).
+		From("personal_access_tokens").
+		Where(where).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pat, err := scanPersonalAccessToken(sqlExecutor.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve personal access token")
+	}
+
+	return pat, nil
+}
+
+// ListActiveByUserID returns the tokens of the user that have not been revoked,
+// newest first. Expired tokens are included so the user can see why a script stopped working.
+func (r *PersonalAccessTokenPostgresRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.PersonalAccessToken, error) {
+	builder := sq.Select(personalAccessTokenColumns).
+		From("personal_access_tokens").
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		OrderBy("created_at DESC").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve personal access tokens")
+	}
+	defer rows.Close()
+
+	pats := make([]*entity.PersonalAccessToken, 0)
+	for rows.Next() {
+		pat, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan personal access token")
+		}
+		pats = append(pats, pat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to iterate personal access tokens")
+	}
+
+	return pats, nil
+}
+
+func (r *PersonalAccessTokenPostgresRepository) Create(ctx context.Context, pat *entity.PersonalAccessToken) error {
+	builder := sq.Insert("personal_access_tokens").Columns(
+		"id", "user_id", "name", "token_hash", "token_hint", "scopes", "expires_at", "last_used_at", "last_used_ip", "created_at", "revoked_at",
+	).Values(
+		pat.ID, pat.UserID, pat.Name, pat.TokenHash, pat.TokenHint, pat.Scopes,
+		pat.ExpiresAt, pat.LastUsedAt, pat.LastUsedIP, pat.CreatedAt, pat.RevokedAt,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to create personal access token")
+	}
+
+	return nil
+}
+
+func (r *PersonalAccessTokenPostgresRepository) Update(ctx context.Context, pat *entity.PersonalAccessToken) error {
+	builder := sq.Update("personal_access_tokens").
+		Set("name", pat.Name).
+		Set("scopes", pat.Scopes).
+		Set("expires_at", pat.ExpiresAt).
+		Set("last_used_at", pat.LastUsedAt).
+		Set("last_used_ip", pat.LastUsedIP).
+		Set("revoked_at", pat.RevokedAt).
+		Where(sq.Eq{"id": pat.ID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to update personal access token")
+	}
+
+	return nil
+}
+
+// TouchLastUsed records a use of the token unless it was already recorded after
+// notBefore, which keeps frequent requests from writing the row every time.
+func (r *PersonalAccessTokenPostgresRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt, notBefore time.Time, ipAddress string) error {
+	builder := sq.Update("personal_access_tokens").
+		Set("last_used_at", usedAt).
+		Set("last_used_ip", ipAddress).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Or{sq.Eq{"last_used_at": nil}, sq.Lt{"last_used_at": notBefore}}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to update personal access token usage")
+	}
+
+	return nil
+}
+
+// RevokeAllByUserID revokes every personal access token of the user and returns how many were revoked.
+func (r *PersonalAccessTokenPostgresRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID, revokedAt time.Time) (int64, error) {
+	builder := sq.Update("personal_access_tokens").
+		Set("revoked_at", revokedAt).
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to revoke personal access tokens")
+	}
+
+	return tag.RowsAffected(), nil
+}
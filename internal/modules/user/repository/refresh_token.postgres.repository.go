@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+type RefreshTokenPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewRefreshTokenPostgresRepository(db *database.PostgreSQLDatabase) *RefreshTokenPostgresRepository {
+	return &RefreshTokenPostgresRepository{
+		db: db,
+	}
+}
+
+// GetByIDForUpdate locks the row so concurrent refreshes of the same token are serialized.
+func (r *RefreshTokenPostgresRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.RefreshToken, error) {
+	builder := sq.Select("id, family_id, user_id, remember, expires_at, rotated_at, revoked_at, created_at").
+		From("refresh_tokens").
+		Where(sq.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var refreshToken entity.RefreshToken
+	err = sqlExecutor.QueryRow(ctx, sql, args...).Scan(
+		&refreshToken.ID, &refreshToken.FamilyID, &refreshToken.UserID, &refreshToken.Remember,
+		&refreshToken.ExpiresAt, &refreshToken.RotatedAt, &refreshToken.RevokedAt, &refreshToken.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve refresh token")
+	}
+
+	return &refreshToken, nil
+}
+
+func (r *RefreshTokenPostgresRepository) Create(ctx context.Context, refreshToken *entity.RefreshToken) error {
+	builder := sq.Insert("refresh_tokens").Columns(
+		"id", "family_id", "user_id", "remember", "expires_at", "rotated_at", "revoked_at", "created_at",
+	).Values(
+		refreshToken.ID, refreshToken.FamilyID, refreshToken.UserID, refreshToken.Remember,
+		refreshToken.ExpiresAt, refreshToken.RotatedAt, refreshToken.RevokedAt, refreshToken.CreatedAt,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to create refresh token")
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenPostgresRepository) Update(ctx context.Context, refreshToken *entity.RefreshToken) error {
+	builder := sq.Update("refresh_tokens").
+		Set("rotated_at", refreshToken.RotatedAt).
+		Set("revoked_at", refreshToken.RevokedAt).
+		Where(sq.Eq{"id": refreshToken.ID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to update refresh token")
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every token that descends from the same login.
+func (r *RefreshTokenPostgresRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID, revokedAt time.Time) error {
+	builder := sq.Update("refresh_tokens").
+		Set("revoked_at", revokedAt).
+		Where(sq.Eq{"family_id": familyID, "revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to revoke refresh token family")
+	}
+
+	return nil
+}
+
+// RevokeAllByUserID revokes every refresh token the user still holds, across all logins
+// except the family exceptFamilyID, which may be uuid.Nil.
+func (r *RefreshTokenPostgresRepository) RevokeAllByUserID(ctx context.Context, userID, exceptFamilyID uuid.UUID, revokedAt time.Time) error {
+	builder := sq.Update("refresh_tokens").
+		Set("revoked_at", revokedAt).
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		Where(sq.NotEq{"family_id": exceptFamilyID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to revoke user refresh tokens")
+	}
+
+	return nil
+}
+
+// DeleteExpired removes tokens that expired before the given time and returns how many were deleted.
+func (r *RefreshTokenPostgresRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("refresh_tokens").
+		Where(sq.Lt{"expires_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete expired refresh tokens")
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,136 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+// roleColumns aggregates the permissions of a role, so queries using it need GROUP BY r.name.
+const roleColumns = "r.name, r.description, r.created_at, " +
+	"COALESCE(array_agg(rp.permission_name ORDER BY rp.permission_name) FILTER (WHERE rp.permission_name IS NOT NULL), '{}')"
+
+func scanRole(row pgx.Row) (*entity.Role, error) {
+	var role entity.Role
+	if err := row.Scan(&role.Name, &role.Description, &role.CreatedAt, &role.Permissions); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+type RolePostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewRolePostgresRepository(db *database.PostgreSQLDatabase) *RolePostgresRepository {
+	return &RolePostgresRepository{
+		db: db,
+	}
+}
+
+func (r *RolePostgresRepository) selectRoles() sq.SelectBuilder {
+	return sq.Select(roleColumns).
+		From("roles r").
+		LeftJoin("role_permissions rp ON rp.role_name = r.name").
+		GroupBy("r.name").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func (r *RolePostgresRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	sql, args, err := r.selectRoles().Where(sq.Eq{"r.name": name}).ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := scanRole(sqlExecutor.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve role")
+	}
+
+	return role, nil
+}
+
+func (r *RolePostgresRepository) List(ctx context.Context) ([]*entity.Role, error) {
+	sql, args, err := r.selectRoles().OrderBy("r.name").ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve roles")
+	}
+	defer rows.Close()
+
+	roles := make([]*entity.Role, 0)
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan role")
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve roles")
+	}
+
+	return roles, nil
+}
+
+// ListPermissions returns the permission names granted to a role, empty for unknown roles.
+func (r *RolePostgresRepository) ListPermissions(ctx context.Context, roleName string) ([]string, error) {
+	builder := sq.Select("permission_name").
+		From("role_permissions").
+		Where(sq.Eq{"role_name": roleName}).
+		OrderBy("permission_name").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve role permissions")
+	}
+
+	defer rows.Close()
+
+	permissions := make([]string, 0)
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan role permission")
+		}
+		permissions = append(permissions, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve role permissions")
+	}
+
+	return permissions, nil
+}
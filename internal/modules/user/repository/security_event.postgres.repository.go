@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/dto"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+	"github.com/sammidev/goca/internal/pkg/request"
+)
+
+const securityEventColumns = "id, user_id, event_type, outcome, ip_address, user_agent, request_id, metadata, created_at"
+
+// securityEventSortColumns maps the sort_by values accepted from clients to columns.
+var securityEventSortColumns = map[string]string{
+	"event_type": "event_type",
+	"outcome":    "outcome",
+	"created_at": "created_at",
+}
+
+func scanSecurityEvent(row pgx.Row) (*entity.SecurityEvent, error) {
+	var event entity.SecurityEvent
+	err := row.Scan(
+		&event.ID, &event.UserID, &event.EventType, &event.Outcome, &event.IPAddress, &event.UserAgent,
+		&event.RequestID, &event.Metadata, &event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+type SecurityEventPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewSecurityEventPostgresRepository(db *database.PostgreSQLDatabase) *SecurityEventPostgresRepository {
+	return &SecurityEventPostgresRepository{
+		db: db,
+	}
+}
+
+func (r *SecurityEventPostgresRepository) Create(ctx context.Context, event *entity.SecurityEvent) error {
+	metadata := event.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	builder := sq.Insert("security_events").Columns(
+		"id", "user_id", "event_type", "outcome", "ip_address", "user_agent", "request_id", "metadata", "created_at",
+	).Values(
+		event.ID, event.UserID, event.EventType, event.Outcome, event.IPAddress, event.UserAgent,
+		event.RequestID, metadata, event.CreatedAt,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to create security event")
+	}
+
+	return nil
+}
+
+// FindAll returns a page of security events, newest first unless another sort is requested.
+func (r *SecurityEventPostgresRepository) FindAll(ctx context.Context, filter *dto.SecurityEventFilter) (*dto.ListSecurityEventsResponse, error) {
+	orderBy := "created_at DESC"
+	if filter.HasSort() {
+		column, ok := securityEventSortColumns[filter.SortBy]
+		if !ok {
+			return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Unsupported sort_by column")
+		}
+		orderBy = column + " ASC"
+		if filter.IsDesc() {
+			orderBy = column + " DESC"
+		}
+	}
+
+	baseBuilder := sq.Select().
+		From("security_events").
+		PlaceholderFormat(sq.Dollar)
+
+	if filter.UserID != nil {
+		baseBuilder = baseBuilder.Where(sq.Eq{"user_id": *filter.UserID})
+	}
+
+	if filter.EventType != "" {
+		baseBuilder = baseBuilder.Where(sq.Eq{"event_type": filter.EventType})
+	}
+
+	if filter.Outcome != "" {
+		baseBuilder = baseBuilder.Where(sq.Eq{"outcome": filter.Outcome})
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalData int
+	if !filter.IsUnlimitedPage() {
+		countSql, countArgs, err := baseBuilder.Column("COUNT(*)").ToSql()
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build count query")
+		}
+
+		err = sqlExecutor.QueryRow(ctx, countSql, countArgs...).Scan(&totalData)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to execute count query")
+		}
+	}
+
+	dataBuilder := baseBuilder.Columns(securityEventColumns).OrderBy(orderBy)
+	if !filter.IsUnlimitedPage() {
+		dataBuilder = dataBuilder.Limit(uint64(filter.GetLimit())).Offset(uint64(filter.GetOffset()))
+	}
+
+	sql, args, err := dataBuilder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build data query")
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve security events")
+	}
+	defer rows.Close()
+
+	events := make([]*dto.SecurityEventResponse, 0)
+	for rows.Next() {
+		event, err := scanSecurityEvent(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan security event")
+		}
+		events = append(events, dto.SecurityEventEntityToSecurityEventResponse(event))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve security events")
+	}
+
+	if filter.IsUnlimitedPage() {
+		totalData = len(events)
+	}
+
+	paging, err := request.NewPaging(filter.CurrentPage, filter.PerPage, totalData)
+	if err != nil {
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, err.Error())
+	}
+
+	return &dto.ListSecurityEventsResponse{
+		List:   events,
+		Paging: paging,
+	}, nil
+}
+
+// DeleteExpired removes security events created before the given time and returns how many were deleted.
+func (r *SecurityEventPostgresRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("security_events").
+		Where(sq.Lt{"created_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete expired security events")
+	}
+
+	return tag.RowsAffected(), nil
+}
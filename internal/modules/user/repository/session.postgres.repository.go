@@ -0,0 +1,229 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+const sessionColumns = "id, user_id, device, device_type, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at"
+
+func scanSession(row pgx.Row) (*entity.Session, error) {
+	var session entity.Session
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.Device, &session.DeviceType, &session.IPAddress, &session.UserAgent,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+type SessionPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewSessionPostgresRepository(db *database.PostgreSQLDatabase) *SessionPostgresRepository {
+	return &SessionPostgresRepository{
+		db: db,
+	}
+}
+
+func (r *SessionPostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
+	builder := sq.Select(sessionColumns).
+		From("sessions").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := scanSession(sqlExecutor.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve session")
+	}
+
+	return session, nil
+}
+
+// ListActiveByUserID returns the sessions that are neither revoked nor expired,
+// most recently used first.
+func (r *SessionPostgresRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error) {
+	builder := sq.Select(sessionColumns).
+		From("sessions").
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		OrderBy("last_seen_at DESC").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve sessions")
+	}
+	defer rows.Close()
+
+	sessions := make([]*entity.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan session")
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to iterate sessions")
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionPostgresRepository) Create(ctx context.Context, session *entity.Session) error {
+	builder := sq.Insert("sessions").Columns(
+		"id", "user_id", "device", "device_type", "ip_address", "user_agent", "created_at", "last_seen_at", "expires_at", "revoked_at",
+	).Values(
+		session.ID, session.UserID, session.Device, session.DeviceType, session.IPAddress, session.UserAgent,
+		session.CreatedAt, session.LastSeenAt, session.ExpiresAt, session.RevokedAt,
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to create session")
+	}
+
+	return nil
+}
+
+func (r *SessionPostgresRepository) Update(ctx context.Context, session *entity.Session) error {
+	builder := sq.Update("sessions").
+		Set("device", session.Device).
+		Set("device_type", session.DeviceType).
+		Set("ip_address", session.IPAddress).
+		Set("user_agent", session.UserAgent).
+		Set("last_seen_at", session.LastSeenAt).
+		Set("expires_at", session.ExpiresAt).
+		Set("revoked_at", session.RevokedAt).
+		Where(sq.Eq{"id": session.ID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to update session")
+	}
+
+	return nil
+}
+
+// RevokeAllByUserID revokes every active session of the user except exceptID, which
+// may be uuid.Nil, and returns the IDs of the sessions that were revoked.
+func (r *SessionPostgresRepository) RevokeAllByUserID(ctx context.Context, userID, exceptID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error) {
+	builder := sq.Update("sessions").
+		Set("revoked_at", revokedAt).
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		Where(sq.NotEq{"id": exceptID}).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to revoke sessions")
+	}
+	defer rows.Close()
+
+	revokedIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan session ID")
+		}
+		revokedIDs = append(revokedIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to revoke sessions")
+	}
+
+	return revokedIDs, nil
+}
+
+// DeleteExpired removes sessions that expired before the given time and returns how many were deleted.
+func (r *SessionPostgresRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("sessions").
+		Where(sq.Lt{"expires_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete expired sessions")
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+type TwoFactorEnrollmentPostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewTwoFactorEnrollmentPostgresRepository(db *database.PostgreSQLDatabase) *TwoFactorEnrollmentPostgresRepository {
+	return &TwoFactorEnrollmentPostgresRepository{
+		db: db,
+	}
+}
+
+func (r *TwoFactorEnrollmentPostgresRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.TwoFactorEnrollment, error) {
+	builder := sq.Select("user_id, secret, expires_at, created_at").
+		From("two_factor_enrollments").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var enrollment entity.TwoFactorEnrollment
+	err = sqlExecutor.QueryRow(ctx, sql, args...).Scan(
+		&enrollment.UserID, &enrollment.Secret, &enrollment.ExpiresAt, &enrollment.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve 2FA enrollment")
+	}
+
+	return &enrollment, nil
+}
+
+// Upsert stores the enrollment, replacing any pending enrollment the user already had.
+func (r *TwoFactorEnrollmentPostgresRepository) Upsert(ctx context.Context, enrollment *entity.TwoFactorEnrollment) error {
+	builder := sq.Insert("two_factor_enrollments").Columns(
+		"user_id", "secret", "expires_at", "created_at",
+	).Values(
+		enrollment.UserID, enrollment.Secret, enrollment.ExpiresAt, enrollment.CreatedAt,
+	).Suffix(
+		"ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at",
+	).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to save 2FA enrollment")
+	}
+
+	return nil
+}
+
+func (r *TwoFactorEnrollmentPostgresRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	builder := sq.Delete("two_factor_enrollments").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete 2FA enrollment")
+	}
+
+	return nil
+}
+
+// DeleteExpired removes enrollments that expired before the given time and returns how many were deleted.
+func (r *TwoFactorEnrollmentPostgresRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("two_factor_enrollments").
+		Where(sq.Lt{"expires_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete expired 2FA enrollments")
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,207 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/database"
+)
+
+type TwoFactorRecoveryCodePostgresRepository struct {
+	db *database.PostgreSQLDatabase
+}
+
+func NewTwoFactorRecoveryCodePostgresRepository(db *database.PostgreSQLDatabase) *TwoFactorRecoveryCodePostgresRepository {
+	return &TwoFactorRecoveryCodePostgresRepository{
+		db: db,
+	}
+}
+
+// ListLegacyUnusedByUserID returns the unused recovery codes that are still stored with
+// the password hasher. Their hashes are PHC strings, which always start with "$".
+func (r *TwoFactorRecoveryCodePostgresRepository) ListLegacyUnusedByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.TwoFactorRecoveryCode, error) {
+	builder := sq.Select("id, user_id, code_hash, used_at, created_at").
+		From("two_factor_recovery_codes").
+		Where(sq.Eq{"user_id": userID, "used_at": nil}).
+		Where(sq.Like{"code_hash": "$%"}).
+		OrderBy("created_at ASC").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve recovery codes")
+	}
+	defer rows.Close()
+
+	recoveryCodes := make([]*entity.TwoFactorRecoveryCode, 0)
+	for rows.Next() {
+		var recoveryCode entity.TwoFactorRecoveryCode
+		if err := rows.Scan(
+			&recoveryCode.ID, &recoveryCode.UserID, &recoveryCode.CodeHash, &recoveryCode.UsedAt, &recoveryCode.CreatedAt,
+		); err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan recovery code")
+		}
+		recoveryCodes = append(recoveryCodes, &recoveryCode)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to iterate recovery codes")
+	}
+
+	return recoveryCodes, nil
+}
+
+func (r *TwoFactorRecoveryCodePostgresRepository) CountUnusedByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	builder := sq.Select("COUNT(*)").
+		From("two_factor_recovery_codes").
+		Where(sq.Eq{"user_id": userID, "used_at": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := sqlExecutor.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to count recovery codes")
+	}
+
+	return count, nil
+}
+
+func (r *TwoFactorRecoveryCodePostgresRepository) CreateBatch(ctx context.Context, recoveryCodes []*entity.TwoFactorRecoveryCode) error {
+	if len(recoveryCodes) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert("two_factor_recovery_codes").Columns(
+		"id", "user_id", "code_hash", "used_at", "created_at",
+	).PlaceholderFormat(sq.Dollar)
+
+	for _, recoveryCode := range recoveryCodes {
+		builder = builder.Values(
+			recoveryCode.ID, recoveryCode.UserID, recoveryCode.CodeHash, recoveryCode.UsedAt, recoveryCode.CreatedAt,
+		)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to create recovery codes")
+	}
+
+	return nil
+}
+
+// MarkUsedByHash redeems the unused recovery code of the user with the given hash. It
+// returns ErrNotFound when no such code exists or it was already redeemed, so a code
+// can never be used twice concurrently.
+func (r *TwoFactorRecoveryCodePostgresRepository) MarkUsedByHash(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error {
+	builder := sq.Update("two_factor_recovery_codes").
+		Set("used_at", usedAt).
+		Where(sq.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to use recovery code")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// MarkUsed redeems a recovery code by ID. Like MarkUsedByHash it returns ErrNotFound
+// when the code does not exist or was already redeemed.
+func (r *TwoFactorRecoveryCodePostgresRepository) MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	builder := sq.Update("two_factor_recovery_codes").
+		Set("used_at", usedAt).
+		Where(sq.Eq{"id": id, "used_at": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to use recovery code")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRecoveryCodePostgresRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	builder := sq.Delete("two_factor_recovery_codes").
+		Where(sq.Eq{"user_id": userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete recovery codes")
+	}
+
+	return nil
+}
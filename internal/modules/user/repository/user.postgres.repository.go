@@ -3,15 +3,44 @@ package repo
 import (
 	"context"
 	"errors"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/sammidev/goca/internal/modules/user/dto"
 	"github.com/sammidev/goca/internal/modules/user/entity"
 	"github.com/sammidev/goca/internal/pkg/apperror"
 	"github.com/sammidev/goca/internal/pkg/database"
+	"github.com/sammidev/goca/internal/pkg/request"
 )
 
+const userColumns = "id, email, email_verified_at, first_name, last_name, full_name, password, password_changed_at, status, role, two_factor_secret, two_factor_enabled, login_alerts_enabled, deletion_scheduled_at, created_at, updated_at"
+
+// userSortColumns lists the columns users can be sorted by. sort_by comes from the
+// query string, so it is never put into the query without going through this map.
+var userSortColumns = map[string]string{
+	"email":      "email",
+	"full_name":  "full_name",
+	"status":     "status",
+	"role":       "role",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func scanUser(row pgx.Row) (*entity.User, error) {
+	var user entity.User
+	err := row.Scan(
+		&user.ID, &user.Email, &user.EmailVerifiedAt, &user.FirstName, &user.LastName, &user.FullName,
+		&user.Password, &user.PasswordChangedAt, &user.Status, &user.Role, &user.TwoFactorSecret, &user.TwoFactorEnabled,
+		&user.LoginAlertsEnabled, &user.DeletionScheduledAt, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 type UserPostgresRepository struct {
 	db *database.PostgreSQLDatabase
 }
@@ -23,7 +52,7 @@ func NewUserPostgresRepository(db *database.PostgreSQLDatabase) *UserPostgresRep
 }
 
 func (r *UserPostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
-	builder := sq.Select("id, email, email_verified_at, first_name, last_name, full_name, password, status, two_factor_secret, two_factor_enabled, created_at, updated_at").
+	builder := sq.Select(userColumns).
 		From("users").
 		Where(sq.Eq{"id": id}).
 		PlaceholderFormat(sq.Dollar)
@@ -38,13 +67,7 @@ func (r *UserPostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*en
 		return nil, err
 	}
 
-	var user entity.User
-	err = sqlExecutor.QueryRow(ctx, sql, args...).Scan(
-		&user.ID, &user.Email, &user.EmailVerifiedAt, &user.FirstName, &user.LastName, &user.FullName,
-		&user.Password, &user.Status, &user.TwoFactorSecret, &user.TwoFactorEnabled,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
+	user, err := scanUser(sqlExecutor.QueryRow(ctx, sql, args...))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.ErrNotFound
@@ -52,11 +75,11 @@ func (r *UserPostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*en
 		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve user")
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserPostgresRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
-	builder := sq.Select("id, email, email_verified_at, first_name, last_name, full_name, password, status, two_factor_secret, two_factor_enabled, created_at, updated_at").
+	builder := sq.Select(userColumns).
 		From("users").
 		Where(sq.Eq{"email": email}).
 		PlaceholderFormat(sq.Dollar)
@@ -71,13 +94,7 @@ func (r *UserPostgresRepository) GetByEmail(ctx context.Context, email string) (
 		return nil, err
 	}
 
-	var user entity.User
-	err = sqlExecutor.QueryRow(ctx, sql, args...).Scan(
-		&user.ID, &user.Email, &user.EmailVerifiedAt, &user.FirstName, &user.LastName, &user.FullName,
-		&user.Password, &user.Status, &user.TwoFactorSecret, &user.TwoFactorEnabled,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
+	user, err := scanUser(sqlExecutor.QueryRow(ctx, sql, args...))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.ErrUserNotFound
@@ -85,16 +102,112 @@ func (r *UserPostgresRepository) GetByEmail(ctx context.Context, email string) (
 		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve user")
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+// FindAll searches users by email or name, optionally narrowed down by status and role.
+// Without sort_by the newest users come first.
+func (r *UserPostgresRepository) FindAll(ctx context.Context, req *dto.AdminListUsersRequest) (*dto.AdminListUsersResponse, error) {
+	orderBy := "created_at DESC"
+	if req.HasSort() {
+		column, ok := userSortColumns[req.SortBy]
+		if !ok {
+			return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Unsupported sort_by column")
+		}
+		orderBy = column + " ASC"
+		if req.IsDesc() {
+			orderBy = column + " DESC"
+		}
+	}
+
+	baseBuilder := sq.Select().
+		From("users").
+		PlaceholderFormat(sq.Dollar)
+
+	if req.HasKeyword() {
+		keyword := "%" + req.Keyword + "%"
+		baseBuilder = baseBuilder.Where(sq.Or{
+			sq.ILike{"email": keyword},
+			sq.ILike{"full_name": keyword},
+		})
+	}
+
+	if req.Status != "" {
+		baseBuilder = baseBuilder.Where(sq.Eq{"status": req.Status})
+	}
+
+	if req.Role != "" {
+		baseBuilder = baseBuilder.Where(sq.Eq{"role": req.Role})
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalData int
+	if !req.IsUnlimitedPage() {
+		countSql, countArgs, err := baseBuilder.Column("COUNT(*)").ToSql()
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build count query")
+		}
+
+		err = sqlExecutor.QueryRow(ctx, countSql, countArgs...).Scan(&totalData)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to execute count query")
+		}
+	}
+
+	dataBuilder := baseBuilder.Columns(userColumns).OrderBy(orderBy)
+	if !req.IsUnlimitedPage() {
+		dataBuilder = dataBuilder.Limit(uint64(req.GetLimit())).Offset(uint64(req.GetOffset()))
+	}
+
+	sql, args, err := dataBuilder.ToSql()
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build data query")
+	}
+
+	rows, err := sqlExecutor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve users")
+	}
+	defer rows.Close()
+
+	users := make([]*dto.UserResponse, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to scan user")
+		}
+		users = append(users, dto.UserEntityToUserResponse(user))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to retrieve users")
+	}
+
+	if req.IsUnlimitedPage() {
+		totalData = len(users)
+	}
+
+	paging, err := request.NewPaging(req.CurrentPage, req.PerPage, totalData)
+	if err != nil {
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, err.Error())
+	}
+
+	return &dto.AdminListUsersResponse{
+		List:   users,
+		Paging: paging,
+	}, nil
 }
 
 func (r *UserPostgresRepository) Create(ctx context.Context, user *entity.User) error {
 	builder := sq.Insert("users").Columns(
-		"id", "email", "email_verified_at", "first_name", "last_name", "full_name", "password", "status", "two_factor_secret", "two_factor_enabled", "created_at", "updated_at",
+		"id", "email", "email_verified_at", "first_name", "last_name", "full_name", "password", "password_changed_at", "status", "role", "two_factor_secret", "two_factor_enabled", "login_alerts_enabled", "deletion_scheduled_at", "created_at", "updated_at",
 	).Values(
 		user.ID, user.Email, user.EmailVerifiedAt, user.FirstName,
-		user.LastName, user.FullName, user.Password, user.Status, user.TwoFactorSecret, user.TwoFactorEnabled,
-		user.CreatedAt, user.UpdatedAt,
+		user.LastName, user.FullName, user.Password, user.PasswordChangedAt, user.Status, user.Role, user.TwoFactorSecret, user.TwoFactorEnabled,
+		user.LoginAlertsEnabled, user.DeletionScheduledAt, user.CreatedAt, user.UpdatedAt,
 	).PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := builder.ToSql()
@@ -126,9 +239,13 @@ func (r *UserPostgresRepository) Update(ctx context.Context, user *entity.User)
 		Set("last_name", user.LastName).
 		Set("full_name", user.FullName).
 		Set("password", user.Password).
+		Set("password_changed_at", user.PasswordChangedAt).
 		Set("status", user.Status).
+		Set("role", user.Role).
 		Set("two_factor_secret", user.TwoFactorSecret).
 		Set("two_factor_enabled", user.TwoFactorEnabled).
+		Set("login_alerts_enabled", user.LoginAlertsEnabled).
+		Set("deletion_scheduled_at", user.DeletionScheduledAt).
 		Set("updated_at", user.UpdatedAt).
 		Where(sq.Eq{"id": user.ID}).PlaceholderFormat(sq.Dollar)
 
@@ -144,6 +261,10 @@ func (r *UserPostgresRepository) Update(ctx context.Context, user *entity.User)
 
 	_, err = sqlExecutor.Exec(ctx, sql, args...)
 	if err != nil {
+		// The email can change, so another user may have taken it in the meantime
+		if database.IsUniqueViolation(err) {
+			return apperror.ErrUserAlreadyExists
+		}
 		return apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to update user")
 	}
 
@@ -170,3 +291,27 @@ func (r *UserPostgresRepository) Delete(ctx context.Context, id uuid.UUID) error
 
 	return nil
 }
+
+// DeleteScheduled deletes the accounts whose deletion grace period ended before the given time.
+func (r *UserPostgresRepository) DeleteScheduled(ctx context.Context, before time.Time) (int64, error) {
+	builder := sq.Delete("users").
+		Where(sq.LtOrEq{"deletion_scheduled_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to build query")
+	}
+
+	sqlExecutor, err := r.db.GetSQLExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := sqlExecutor.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, apperror.WrapError(err, apperror.ErrCodeDatabaseError, "Failed to delete scheduled users")
+	}
+
+	return tag.RowsAffected(), nil
+}
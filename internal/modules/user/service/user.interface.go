@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/modules/user/dto"
 	"github.com/sammidev/goca/internal/modules/user/entity"
 )
 
@@ -13,4 +15,103 @@ type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteScheduled(ctx context.Context, before time.Time) (int64, error)
+	FindAll(ctx context.Context, req *dto.AdminListUsersRequest) (*dto.AdminListUsersResponse, error)
+}
+
+type RefreshTokenRepository interface {
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.RefreshToken, error)
+	Create(ctx context.Context, refreshToken *entity.RefreshToken) error
+	Update(ctx context.Context, refreshToken *entity.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, revokedAt time.Time) error
+	RevokeAllByUserID(ctx context.Context, userID, exceptFamilyID uuid.UUID, revokedAt time.Time) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TwoFactorEnrollmentRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.TwoFactorEnrollment, error)
+	Upsert(ctx context.Context, enrollment *entity.TwoFactorEnrollment) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TwoFactorRecoveryCodeRepository interface {
+	CountUnusedByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	CreateBatch(ctx context.Context, recoveryCodes []*entity.TwoFactorRecoveryCode) error
+	MarkUsedByHash(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error
+	ListLegacyUnusedByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.TwoFactorRecoveryCode, error)
+	MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type LoginAttemptRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.LoginAttempt, error)
+	RegisterFailure(ctx context.Context, userID uuid.UUID, failedAt, windowStart time.Time) (*entity.LoginAttempt, error)
+	Lock(ctx context.Context, userID uuid.UUID, lockedUntil time.Time) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type SessionRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entity.Session, error)
+	Create(ctx context.Context, session *entity.Session) error
+	Update(ctx context.Context, session *entity.Session) error
+	RevokeAllByUserID(ctx context.Context, userID, exceptID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type KnownDeviceRepository interface {
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.KnownDevice, error)
+	Upsert(ctx context.Context, device *entity.KnownDevice) error
+	DeleteNotSeenSince(ctx context.Context, before time.Time) (int64, error)
+}
+
+type PasswordHistoryRepository interface {
+	ListRecentByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.PasswordHistory, error)
+	Create(ctx context.Context, history *entity.PasswordHistory) error
+	DeleteAllButNewest(ctx context.Context, userID uuid.UUID, keep int) error
+}
+
+type InviteRepository interface {
+	Create(ctx context.Context, invite *entity.Invite) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Invite, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invite, error)
+	FindAll(ctx context.Context, filter *dto.InviteFilter) (*dto.ListInvitesResponse, error)
+	MarkRedeemed(ctx context.Context, id, userID uuid.UUID, redeemedAt time.Time) error
+	MarkRevoked(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	RevokePendingByEmail(ctx context.Context, email string, revokedAt time.Time) (int64, error)
+}
+
+type PersonalAccessTokenRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PersonalAccessToken, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error)
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.PersonalAccessToken, error)
+	Create(ctx context.Context, pat *entity.PersonalAccessToken) error
+	Update(ctx context.Context, pat *entity.PersonalAccessToken) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt, notBefore time.Time, ipAddress string) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID, revokedAt time.Time) (int64, error)
+}
+
+type SecurityEventRepository interface {
+	Create(ctx context.Context, event *entity.SecurityEvent) error
+	FindAll(ctx context.Context, filter *dto.SecurityEventFilter) (*dto.ListSecurityEventsResponse, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type RoleRepository interface {
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+	List(ctx context.Context) ([]*entity.Role, error)
+	ListPermissions(ctx context.Context, roleName string) ([]string, error)
+}
+
+type AdminAuditLogRepository interface {
+	Create(ctx context.Context, log *entity.AdminAuditLog) error
+}
+
+type EmailChangeRequestRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.EmailChangeRequest, error)
+	Upsert(ctx context.Context, request *entity.EmailChangeRequest) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
@@ -2,9 +2,16 @@ package service
 
 import (
 	"context"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,15 +22,19 @@ import (
 	"github.com/sammidev/goca/internal/modules/user/dto"
 	"github.com/sammidev/goca/internal/modules/user/entity"
 	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/authz"
 	"github.com/sammidev/goca/internal/pkg/cache"
 	"github.com/sammidev/goca/internal/pkg/database"
 	"github.com/sammidev/goca/internal/pkg/encoding"
+	"github.com/sammidev/goca/internal/pkg/geoip"
 	"github.com/sammidev/goca/internal/pkg/logger"
 	"github.com/sammidev/goca/internal/pkg/observability"
+	"github.com/sammidev/goca/internal/pkg/otpcode"
 	"github.com/sammidev/goca/internal/pkg/password"
 	"github.com/sammidev/goca/internal/pkg/random"
 	"github.com/sammidev/goca/internal/pkg/ratelimit"
 	"github.com/sammidev/goca/internal/pkg/token"
+	"github.com/sammidev/goca/internal/pkg/useragent"
 	"github.com/sammidev/goca/internal/pkg/validator"
 	"github.com/sammidev/goca/internal/pkg/worker"
 	"github.com/skip2/go-qrcode"
@@ -34,16 +45,33 @@ import (
 )
 
 type UserService struct {
-	cfg       *config.Config
-	logger    logger.Logger
-	validator validator.Validator
-	db        database.Database
-	token     token.Token
-	cache     cache.Cache
-	tracer    trace.Tracer
-	worker    worker.TaskDistributor
-	limiter   ratelimit.RateLimiter
-	userRepo  UserRepository
+	cfg                       *config.Config
+	logger                    logger.Logger
+	validator                 validator.Validator
+	db                        database.Database
+	token                     token.Token
+	denylist                  token.Denylist
+	cache                     cache.Cache
+	tracer                    trace.Tracer
+	worker                    worker.TaskDistributor
+	limiter                   ratelimit.RateLimiter
+	geoip                     geoip.Locator
+	hasher                    password.Hasher
+	otpStore                  otpcode.Store
+	userRepo                  UserRepository
+	refreshTokenRepo          RefreshTokenRepository
+	twoFactorEnrollmentRepo   TwoFactorEnrollmentRepository
+	twoFactorRecoveryCodeRepo TwoFactorRecoveryCodeRepository
+	loginAttemptRepo          LoginAttemptRepository
+	sessionRepo               SessionRepository
+	emailChangeRequestRepo    EmailChangeRequestRepository
+	adminAuditLogRepo         AdminAuditLogRepository
+	roleRepo                  RoleRepository
+	personalAccessTokenRepo   PersonalAccessTokenRepository
+	securityEventRepo         SecurityEventRepository
+	knownDeviceRepo           KnownDeviceRepository
+	passwordHistoryRepo       PasswordHistoryRepository
+	inviteRepo                InviteRepository
 }
 
 func NewUserService(
@@ -52,22 +80,56 @@ func NewUserService(
 	validator validator.Validator,
 	db database.Database,
 	token token.Token,
+	denylist token.Denylist,
 	cache cache.Cache,
 	worker worker.TaskDistributor,
 	limiter ratelimit.RateLimiter,
+	geoip geoip.Locator,
+	hasher password.Hasher,
+	otpStore otpcode.Store,
 	userRepo UserRepository,
+	refreshTokenRepo RefreshTokenRepository,
+	twoFactorEnrollmentRepo TwoFactorEnrollmentRepository,
+	twoFactorRecoveryCodeRepo TwoFactorRecoveryCodeRepository,
+	loginAttemptRepo LoginAttemptRepository,
+	sessionRepo SessionRepository,
+	emailChangeRequestRepo EmailChangeRequestRepository,
+	adminAuditLogRepo AdminAuditLogRepository,
+	roleRepo RoleRepository,
+	personalAccessTokenRepo PersonalAccessTokenRepository,
+	securityEventRepo SecurityEventRepository,
+	knownDeviceRepo KnownDeviceRepository,
+	passwordHistoryRepo PasswordHistoryRepository,
+	inviteRepo InviteRepository,
 ) *UserService {
 	return &UserService{
-		cfg:       cfg,
-		logger:    logger.WithComponent("user_service"),
-		validator: validator,
-		db:        db,
-		token:     token,
-		cache:     cache,
-		worker:    worker,
-		tracer:    otel.Tracer("user_service"),
-		limiter:   limiter,
-		userRepo:  userRepo,
+		cfg:                       cfg,
+		logger:                    logger.WithComponent("user_service"),
+		validator:                 validator,
+		db:                        db,
+		token:                     token,
+		denylist:                  denylist,
+		cache:                     cache,
+		worker:                    worker,
+		tracer:                    otel.Tracer("user_service"),
+		limiter:                   limiter,
+		geoip:                     geoip,
+		hasher:                    hasher,
+		otpStore:                  otpStore,
+		userRepo:                  userRepo,
+		refreshTokenRepo:          refreshTokenRepo,
+		twoFactorEnrollmentRepo:   twoFactorEnrollmentRepo,
+		twoFactorRecoveryCodeRepo: twoFactorRecoveryCodeRepo,
+		loginAttemptRepo:          loginAttemptRepo,
+		sessionRepo:               sessionRepo,
+		emailChangeRequestRepo:    emailChangeRequestRepo,
+		adminAuditLogRepo:         adminAuditLogRepo,
+		roleRepo:                  roleRepo,
+		personalAccessTokenRepo:   personalAccessTokenRepo,
+		securityEventRepo:         securityEventRepo,
+		knownDeviceRepo:           knownDeviceRepo,
+		passwordHistoryRepo:       passwordHistoryRepo,
+		inviteRepo:                inviteRepo,
 	}
 }
 
@@ -77,7 +139,7 @@ func NewUserService(
 
 func (s *UserService) hashPassword(ctx context.Context, plainPassword string) (string, error) {
 	return observability.TraceOperation(ctx, s.tracer, "hash_password", func(ctx context.Context) (string, error) {
-		hashedPassword, err := password.HashPassword(plainPassword)
+		hashedPassword, err := s.hasher.Hash(plainPassword)
 		if err != nil {
 			return "", apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to hash password")
 		}
@@ -155,7 +217,11 @@ func (s *UserService) updateUser(ctx context.Context, user *entity.User) error {
 
 func (s *UserService) verifyPassword(ctx context.Context, plainPassword, hashedPassword string) error {
 	_, err := observability.TraceOperation(ctx, s.tracer, "verify_password", func(ctx context.Context) (struct{}, error) {
-		if !password.CheckPasswordHash(plainPassword, hashedPassword) {
+		match, err := s.hasher.Verify(plainPassword, hashedPassword)
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Failed to verify password hash", "error", err)
+		}
+		if !match {
 			return struct{}{}, apperror.ErrUserIncorrectPassword
 		}
 		return struct{}{}, nil
@@ -163,59 +229,326 @@ func (s *UserService) verifyPassword(ctx context.Context, plainPassword, hashedP
 	return err
 }
 
+// rehashPasswordIfNeeded upgrades a password hash made with an outdated algorithm or
+// cost, using the plain password that was just verified. It is best-effort: the old
+// hash keeps working, so a failure is only logged.
+func (s *UserService) rehashPasswordIfNeeded(ctx context.Context, user *entity.User, plainPassword string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := s.hashPassword(ctx, plainPassword)
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+
+	previousHash := user.Password
+	user.Password = hashedPassword
+	if err := s.updateUser(ctx, user); err != nil {
+		user.Password = previousHash
+		s.logger.WithContext(ctx).Warn("Failed to store rehashed password", "user_id", user.ID, "error", err)
+		return
+	}
+
+	s.logger.WithContext(ctx).Info("Password hash upgraded", "user_id", user.ID)
+}
+
+// ensurePasswordNotReused rejects a new password that matches the current one or any
+// of the last config.PasswordHistorySize passwords.
+func (s *UserService) ensurePasswordNotReused(ctx context.Context, user *entity.User, plainPassword string) error {
+	if s.verifyPassword(ctx, plainPassword, user.Password) == nil {
+		return apperror.ErrPasswordReused
+	}
+
+	histories, err := s.passwordHistoryRepo.ListRecentByUserID(ctx, user.ID, config.PasswordHistorySize)
+	if err != nil {
+		return err
+	}
+
+	for _, history := range histories {
+		if s.verifyPassword(ctx, plainPassword, history.PasswordHash) == nil {
+			return apperror.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+// setPassword hashes and stores a new password for the user. The hash being replaced
+// goes into the password history, which is trimmed to config.PasswordHistorySize.
+// Callers are expected to run it inside a transaction.
+func (s *UserService) setPassword(ctx context.Context, user *entity.User, plainPassword string) error {
+	hashedPassword, err := s.hashPassword(ctx, plainPassword)
+	if err != nil {
+		return err
+	}
+
+	err = s.passwordHistoryRepo.Create(ctx, &entity.PasswordHistory{
+		ID:           uuid.Must(uuid.NewV7()),
+		UserID:       user.ID,
+		PasswordHash: user.Password,
+		CreatedAt:    user.PasswordChangedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordHistoryRepo.DeleteAllButNewest(ctx, user.ID, config.PasswordHistorySize); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.Password = hashedPassword
+	user.PasswordChangedAt = now
+	user.UpdatedAt = now
+
+	return s.updateUser(ctx, user)
+}
+
 // =============================================================================
 // OTP & CACHE HELPERS
 // =============================================================================
 
-func (s *UserService) generateAndCacheOTP(ctx context.Context, cacheKeyPrefix, identifier string) (string, error) {
-	ctx, span := s.tracer.Start(ctx, "generate_and_cache_otp")
+// issueOTP generates a code for the purpose and subject, replacing any pending one.
+func (s *UserService) issueOTP(ctx context.Context, purpose otpcode.Purpose, subject string) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "issue_otp")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("otp.purpose", string(purpose)))
+
+	code, err := s.otpStore.Issue(ctx, purpose, subject)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", otpIssueError(err)
+	}
+
+	return code, nil
+}
+
+// linkTokenTypes maps the flows that can be delivered as email links to the type of
+// their link token.
+var linkTokenTypes = map[otpcode.Purpose]token.TokenType{
+	otpcode.PurposeEmailVerification: token.TokenTypeEmailVerify,
+	otpcode.PurposePasswordReset:     token.TokenTypePasswordReset,
+}
+
+// issueLink signs a link token for the purpose and keeps its ID as the user's pending
+// secret, so the link expires, cools down and is invalidated exactly like a code.
+func (s *UserService) issueLink(ctx context.Context, purpose otpcode.Purpose, user *entity.User) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "issue_link")
 	defer span.End()
 
-	cacheKey := fmt.Sprintf("%s:%s", cacheKeyPrefix, identifier)
-	span.SetAttributes(attribute.String("cache.key", cacheKey))
+	span.SetAttributes(attribute.String("otp.purpose", string(purpose)))
 
-	otpCode, err := random.GenerateNumericOTP(config.OTPCodeLength)
+	linkToken, err := s.token.GenerateToken(user.ID, linkTokenTypes[purpose], config.EmailConfirmationExpInMin)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return "", apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate OTP")
+		return "", apperror.NewAppErrorWithCause(apperror.ErrCodeInternalError, "Failed to generate link token", err)
 	}
 
-	if err := s.cache.Set(ctx, cacheKey, otpCode, config.EmailConfirmationExpInMin); err != nil {
+	if err := s.otpStore.IssueLink(ctx, purpose, user.Email, linkToken.ID.String()); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return "", apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to cache OTP")
+		return "", otpIssueError(err)
 	}
 
-	return otpCode, nil
+	return linkToken.Value, nil
 }
 
-func (s *UserService) getCachedOTP(ctx context.Context, cacheKeyPrefix, identifier string) (string, error) {
-	ctx, span := s.tracer.Start(ctx, "get_cached_otp")
-	defer span.End()
+// otpIssueError maps a failure to issue a code or link to an application error.
+func otpIssueError(err error) error {
+	var cooldownErr *otpcode.CooldownError
+	if errors.As(err, &cooldownErr) {
+		return apperror.ErrOTPResendCooldown.WithRetryAfter(cooldownErr.RetryAfter)
+	}
+	return apperror.NewAppErrorWithCause(apperror.ErrCodeInternalError, "Failed to issue OTP", err)
+}
+
+// sendVerificationEmail issues an email verification code or link, depending on the
+// configured delivery, and queues the matching email. The code starts a cooldown, so
+// it is sent outside of any transaction that could still roll back.
+func (s *UserService) sendVerificationEmail(ctx context.Context, user *entity.User) error {
+	var err error
+	if s.otpStore.Delivery(otpcode.PurposeEmailVerification) == otpcode.DeliveryLink {
+		var linkToken string
+		if linkToken, err = s.issueLink(ctx, otpcode.PurposeEmailVerification, user); err != nil {
+			return err
+		}
+		err = s.queueVerificationLinkEmail(ctx, user, linkToken)
+	} else {
+		var otpCode string
+		if otpCode, err = s.issueOTP(ctx, otpcode.PurposeEmailVerification, user.Email); err != nil {
+			return err
+		}
+		err = s.queueVerificationEmail(ctx, user, otpCode)
+	}
+
+	if err != nil {
+		s.cancelOTP(ctx, otpcode.PurposeEmailVerification, user.Email)
+	}
+	return err
+}
 
-	cacheKey := fmt.Sprintf("%s:%s", cacheKeyPrefix, identifier)
-	span.SetAttributes(attribute.String("cache.key", cacheKey))
+// sendPasswordResetEmail issues a password reset code or link, depending on the
+// configured delivery, and queues the matching email. Like sendVerificationEmail it
+// runs outside of any transaction.
+func (s *UserService) sendPasswordResetEmail(ctx context.Context, user *entity.User) error {
+	var err error
+	if s.otpStore.Delivery(otpcode.PurposePasswordReset) == otpcode.DeliveryLink {
+		var linkToken string
+		if linkToken, err = s.issueLink(ctx, otpcode.PurposePasswordReset, user); err != nil {
+			return err
+		}
+		err = s.queuePasswordResetLinkEmail(ctx, user, linkToken)
+	} else {
+		var otpCode string
+		if otpCode, err = s.issueOTP(ctx, otpcode.PurposePasswordReset, user.Email); err != nil {
+			return err
+		}
+		err = s.queueForgotPasswordEmail(ctx, user, otpCode)
+	}
 
-	cachedOTP, err := s.cache.Get(ctx, cacheKey)
 	if err != nil {
+		s.cancelOTP(ctx, otpcode.PurposePasswordReset, user.Email)
+	}
+	return err
+}
+
+// verifyOTP checks a code and records the outcome. It has to run outside a
+// transaction, otherwise failed attempts would be rolled back with the flow.
+func (s *UserService) verifyOTP(ctx context.Context, userID uuid.UUID, client dto.ClientInfo, purpose otpcode.Purpose, subject, code string) error {
+	ctx, span := s.tracer.Start(ctx, "verify_otp")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("otp.purpose", string(purpose)))
+
+	err := s.otpStore.Verify(ctx, purpose, subject, code)
+	if err == nil {
+		s.recordSecurityEvent(ctx, userID, entity.SecurityEventOTPVerified, entity.SecurityEventOutcomeSuccess, client, otpEventMetadata(purpose, nil))
+		return nil
+	}
+
+	var appErr *apperror.AppError
+	switch {
+	case errors.Is(err, otpcode.ErrExpired):
+		appErr = apperror.ErrOTPExpired
+	case errors.Is(err, otpcode.ErrInvalid):
+		appErr = apperror.ErrInvalidOTP
+	case errors.Is(err, otpcode.ErrTooManyAttempts):
+		s.logger.WithContext(ctx).Warn("OTP invalidated after too many attempts", "user_id", userID, "purpose", purpose)
+		appErr = apperror.ErrOTPAttemptsExceeded
+	default:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return apperror.NewAppErrorWithCause(apperror.ErrCodeInternalError, "Failed to verify OTP", err)
+	}
+
+	span.RecordError(appErr)
+	s.recordSecurityEvent(ctx, userID, entity.SecurityEventOTPVerified, entity.SecurityEventOutcomeFailure, client, otpEventMetadata(purpose, appErr))
+	return appErr
+}
+
+// consumeOTP takes a verified code or link so it completes its flow only once. A code
+// that was taken concurrently, or replaced since it was verified, counts as expired.
+func (s *UserService) consumeOTP(ctx context.Context, purpose otpcode.Purpose, subject, code string) error {
+	ctx, span := s.tracer.Start(ctx, "consume_otp")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("otp.purpose", string(purpose)))
+
+	if err := s.otpStore.Consume(ctx, purpose, subject, code); err != nil {
 		span.RecordError(err)
+		if errors.Is(err, otpcode.ErrExpired) {
+			return apperror.ErrOTPExpired
+		}
 		span.SetStatus(codes.Error, err.Error())
-		return "", apperror.ErrOTPExpired
+		return apperror.NewAppErrorWithCause(apperror.ErrCodeInternalError, "Failed to consume OTP", err)
 	}
 
-	return cachedOTP.(string), nil
+	return nil
 }
 
-func (s *UserService) deleteCachedOTP(ctx context.Context, cacheKeyPrefix, identifier string) error {
-	_, err := observability.TraceOperation(ctx, s.tracer, "delete_cached_otp", func(ctx context.Context) (struct{}, error) {
-		cacheKey := fmt.Sprintf("%s:%s", cacheKeyPrefix, identifier)
-		if err := s.cache.Delete(ctx, cacheKey); err != nil {
-			s.logger.WithContext(ctx).Warn("Failed to delete cache", "cache_key", cacheKey, "error", err)
+// revokeOTP discards the pending code. It is best-effort, since the code expires anyway.
+func (s *UserService) revokeOTP(ctx context.Context, purpose otpcode.Purpose, subject string) {
+	_, _ = observability.TraceOperation(ctx, s.tracer, "revoke_otp", func(ctx context.Context) (struct{}, error) {
+		if err := s.otpStore.Revoke(ctx, purpose, subject); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to revoke OTP", "purpose", purpose, "error", err)
 		}
 		return struct{}{}, nil
 	})
-	return err
+}
+
+// cancelOTP discards a code that was issued but never sent, together with its
+// cooldown, so the user can ask for a new one straight away.
+func (s *UserService) cancelOTP(ctx context.Context, purpose otpcode.Purpose, subject string) {
+	_, _ = observability.TraceOperation(ctx, s.tracer, "cancel_otp", func(ctx context.Context) (struct{}, error) {
+		if err := s.otpStore.Cancel(ctx, purpose, subject); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to cancel OTP", "purpose", purpose, "error", err)
+		}
+		return struct{}{}, nil
+	})
+}
+
+// recordOTPSent records whether a code went out. It is called once the code was
+// issued and queued, after any transaction of the flow has committed.
+func (s *UserService) recordOTPSent(ctx context.Context, userID uuid.UUID, client dto.ClientInfo, purpose otpcode.Purpose, err error) {
+	outcome := entity.SecurityEventOutcomeSuccess
+	if err != nil {
+		outcome = entity.SecurityEventOutcomeFailure
+	}
+	s.recordSecurityEvent(ctx, userID, entity.SecurityEventOTPSent, outcome, client, otpEventMetadata(purpose, err))
+}
+
+// completeEmailVerification verifies and activates the account once its code or link
+// has been accepted, then discards the pending secret.
+func (s *UserService) completeEmailVerification(ctx context.Context, user *entity.User, client dto.ClientInfo) error {
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		user.VerifyEmail(time.Now())
+		user.Activate()
+
+		return s.updateUser(txCtx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.revokeOTP(ctx, otpcode.PurposeEmailVerification, user.Email)
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventEmailVerified, entity.SecurityEventOutcomeSuccess, client, nil)
+	return nil
+}
+
+// completePasswordReset sets the new password once the reset code or link has been
+// accepted, signs out every session and discards the pending secret. The caller has
+// already checked the password against the history and claimed the code or link.
+func (s *UserService) completePasswordReset(ctx context.Context, user *entity.User, newPassword string, client dto.ClientInfo) error {
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.setPassword(txCtx, user, newPassword); err != nil {
+			return err
+		}
+
+		// Sign out every device that may still hold the old credentials
+		return s.revokeAllCredentials(txCtx, user.ID)
+	})
+	if err != nil {
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordReset, entity.SecurityEventOutcomeFailure, client, securityEventFailure(err))
+		return err
+	}
+
+	s.revokeOTP(ctx, otpcode.PurposePasswordReset, user.Email)
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordReset, entity.SecurityEventOutcomeSuccess, client, nil)
+	return nil
+}
+
+// otpEventMetadata describes an OTP security event, with the failure reason when err is set.
+func otpEventMetadata(purpose otpcode.Purpose, err error) map[string]string {
+	metadata := map[string]string{"purpose": string(purpose)}
+	if err != nil {
+		metadata["reason"] = securityEventFailure(err)["reason"]
+	}
+	return metadata
 }
 
 // =============================================================================
@@ -227,12 +560,46 @@ type TokenPair struct {
 	RefreshToken *token.GenerateTokenResponse
 }
 
-func (s *UserService) generateAuthTokens(ctx context.Context, userID uuid.UUID, remember bool) (*TokenPair, error) {
-	_, span := s.tracer.Start(ctx, "generate_auth_tokens")
+// generateAuthTokens issues a token pair that starts a new refresh token family
+// and records it as a new session of the client.
+func (s *UserService) generateAuthTokens(ctx context.Context, user *entity.User, remember bool, client dto.ClientInfo) (*TokenPair, error) {
+	sessionID := uuid.Must(uuid.NewV7())
+	userID := user.ID
+
+	tokenPair, err := s.issueTokenPair(ctx, user, sessionID, remember)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &entity.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		CreatedAt: now,
+	}
+	setSessionClient(session, client, now, tokenPair.RefreshToken.ExpiresAt)
+
+	_, err = observability.TraceOperation(ctx, s.tracer, "repo.CreateSession", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.sessionRepo.Create(ctx, session)
+	}, attribute.String("session_id", sessionID.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenPair, nil
+}
+
+// issueTokenPair signs a new access/refresh token pair and stores the refresh token
+// server-side under the given family so it can later be rotated or revoked.
+func (s *UserService) issueTokenPair(ctx context.Context, user *entity.User, familyID uuid.UUID, remember bool) (*TokenPair, error) {
+	ctx, span := s.tracer.Start(ctx, "generate_auth_tokens")
 	defer span.End()
 
+	userID := user.ID
+
 	span.SetAttributes(
 		attribute.String("user_id", userID.String()),
+		attribute.String("token_family_id", familyID.String()),
 		attribute.Bool("remember", remember),
 	)
 
@@ -246,399 +613,3796 @@ func (s *UserService) generateAuthTokens(ctx context.Context, userID uuid.UUID,
 		refreshTokenExpiry = s.cfg.AuthRefreshTokenExpiry
 	}
 
-	accessToken, err := s.token.GenerateToken(userID, accessTokenExpiry)
+	// Permissions are resolved on every issue, so a role change reaches the token on the next refresh
+	permissions, err := s.rolePermissions(ctx, user.Role)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// The family ID doubles as the session ID, so every token of a login can be revoked at once
+	accessToken, err := s.token.GenerateToken(userID, token.TokenTypeAccess, accessTokenExpiry,
+		token.WithSessionID(familyID), token.WithPermissions(permissions...))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate access token")
 	}
 
-	refreshToken, err := s.token.GenerateToken(userID, refreshTokenExpiry)
+	refreshToken, err := s.token.GenerateToken(userID, token.TokenTypeRefresh, refreshTokenExpiry, token.WithSessionID(familyID))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate refresh token")
 	}
 
+	storedRefreshToken := &entity.RefreshToken{
+		ID:        refreshToken.ID,
+		FamilyID:  familyID,
+		UserID:    userID,
+		Remember:  remember,
+		ExpiresAt: refreshToken.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.refreshTokenRepo.Create(ctx, storedRefreshToken); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	return &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (s *UserService) verifyToken(ctx context.Context, tokenString string) (*token.Payload, error) {
-	_, span := s.tracer.Start(ctx, "verify_token")
-	defer span.End()
-
-	payload, err := s.token.VerifyToken(tokenString)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return nil, apperror.ErrInvalidToken
-	}
-
-	return payload, nil
+// rolePermissions returns the permission names granted to a role.
+func (s *UserService) rolePermissions(ctx context.Context, role entity.UserRole) ([]string, error) {
+	return observability.TraceOperation(ctx, s.tracer, "repo.ListRolePermissions", func(ctx context.Context) ([]string, error) {
+		return s.roleRepo.ListPermissions(ctx, string(role))
+	}, attribute.String("role", string(role)))
 }
 
-// =============================================================================
-// WORKER HELPERS
-// =============================================================================
+func (s *UserService) revokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "repo.RevokeRefreshTokenFamily", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.refreshTokenRepo.RevokeFamily(ctx, familyID, time.Now())
+	}, attribute.String("token_family_id", familyID.String()))
+	return err
+}
 
-func (s *UserService) queueVerificationEmail(ctx context.Context, user *entity.User, otpCode string) error {
-	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendVerifyEmail", func(ctx context.Context) (struct{}, error) {
-		span := trace.SpanFromContext(ctx)
-		span.SetAttributes(
-			attribute.String("worker.queue", worker.Critical),
-			attribute.String("worker.user_id", user.ID.String()),
-		)
+// setSessionClient records where the session was last used from and extends it
+// to the expiry of its newest refresh token.
+func setSessionClient(session *entity.Session, client dto.ClientInfo, now, expiresAt time.Time) {
+	device := useragent.Parse(client.UserAgent)
+
+	session.Device = device.String()
+	session.DeviceType = device.Type
+	session.IPAddress = client.IPAddress
+	session.UserAgent = client.UserAgent
+	session.LastSeenAt = now
+	session.ExpiresAt = expiresAt
+}
 
-		emailPayload := worker.PayloadSendVerifyEmail{
-			UserID:                     user.ID,
-			Name:                       user.FullName,
-			Email:                      user.Email,
-			VerificationCode:           otpCode,
-			VerificationCodeExpiration: int(config.EmailConfirmationExpInMin.Minutes()),
+// touchSession updates the session behind a rotated refresh token family. Families
+// started before sessions were recorded get a session on their first refresh.
+func (s *UserService) touchSession(ctx context.Context, userID, sessionID uuid.UUID, client dto.ClientInfo, expiresAt time.Time) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "touch_session", func(ctx context.Context) (struct{}, error) {
+		now := time.Now()
+
+		session, err := s.sessionRepo.GetByID(ctx, sessionID)
+		if errors.Is(err, apperror.ErrNotFound) {
+			session = &entity.Session{ID: sessionID, UserID: userID, CreatedAt: now}
+			setSessionClient(session, client, now, expiresAt)
+			return struct{}{}, s.sessionRepo.Create(ctx, session)
+		}
+		if err != nil {
+			return struct{}{}, err
 		}
 
-		taskOptions := []asynq.Option{
-			asynq.MaxRetry(worker.TaskSendVerifyEmailMaxRetry),
-			asynq.Queue(worker.Critical),
+		if session.UserID != userID || session.IsRevoked() {
+			return struct{}{}, apperror.ErrInvalidToken
 		}
 
-		return struct{}{}, s.worker.DistributeTaskSendVerifyEmail(ctx, &emailPayload, taskOptions...)
-	})
+		setSessionClient(session, client, now, expiresAt)
+		return struct{}{}, s.sessionRepo.Update(ctx, session)
+	}, attribute.String("session_id", sessionID.String()))
 	return err
 }
 
-func (s *UserService) queueForgotPasswordEmail(ctx context.Context, user *entity.User, otpCode string) error {
-	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendForgotPasswordEmail", func(ctx context.Context) (struct{}, error) {
-		span := trace.SpanFromContext(ctx)
-		span.SetAttributes(
-			attribute.String("worker.queue", worker.Critical),
-			attribute.String("worker.user_id", user.ID.String()),
-		)
+// revokeSession ends a single session: its refresh token family can no longer be
+// rotated and the access tokens bound to it are rejected by the auth middleware.
+func (s *UserService) revokeSession(ctx context.Context, session *entity.Session) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "revoke_session", func(ctx context.Context) (struct{}, error) {
+		now := time.Now()
 
-		emailPayload := worker.PayloadSendForgotPasswordEmail{
-			UserID:                     user.ID,
-			Name:                       user.FullName,
-			Email:                      user.Email,
-			VerificationCode:           otpCode,
-			VerificationCodeExpiration: int(config.EmailConfirmationExpInMin.Minutes()),
+		if !session.IsRevoked() {
+			session.Revoke(now)
+			if err := s.sessionRepo.Update(ctx, session); err != nil {
+				return struct{}{}, err
+			}
 		}
-
-		taskOptions := []asynq.Option{
-			asynq.MaxRetry(worker.TaskSendVerifyEmailMaxRetry),
-			asynq.Queue(worker.Critical),
+		if err := s.refreshTokenRepo.RevokeFamily(ctx, session.ID, now); err != nil {
+			return struct{}{}, err
 		}
-
-		return struct{}{}, s.worker.DistributeTaskSendForgotPasswordEmail(ctx, &emailPayload, taskOptions...)
-	})
+		if err := s.denylist.RevokeSession(ctx, session.ID); err != nil {
+			return struct{}{}, apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to revoke session tokens")
+		}
+		return struct{}{}, nil
+	}, attribute.String("session_id", session.ID.String()))
 	return err
 }
 
-// =============================================================================
-// BUSINESS LOGIC VALIDATION HELPERS
-// =============================================================================
+// revokeSessionByID ends the session behind a refresh token family, the session ID
+// being the family ID. Families started before sessions were recorded have no session
+// row, their tokens are revoked all the same.
+func (s *UserService) revokeSessionByID(ctx context.Context, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return err
+	}
+	if session != nil {
+		return s.revokeSession(ctx, session)
+	}
 
-func (s *UserService) validateUserState(user *entity.User, requireEmailVerified, requireActive bool) error {
-	if requireEmailVerified && !user.IsEmailVerified() {
-		return apperror.ErrUserEmailNotVerified
+	if err := s.revokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return err
 	}
-	if requireActive && !user.IsActive() {
-		return apperror.ErrUserInactive
+	if err := s.denylist.RevokeSession(ctx, sessionID); err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to revoke session tokens")
 	}
 	return nil
 }
 
-// =============================================================================
-// 2FA HELPERS
-// =============================================================================
-
-func (s *UserService) generate2FASecret(ctx context.Context, userEmail string) (*otp.Key, error) {
-	_, span := s.tracer.Start(ctx, "generate_2fa_secret")
-	defer span.End()
-
-	span.SetAttributes(attribute.String("email", userEmail))
+// revokeOtherSessions signs the user out of every session except keepSessionID and
+// returns how many sessions were ended.
+func (s *UserService) revokeOtherSessions(ctx context.Context, userID, keepSessionID uuid.UUID) (int, error) {
+	return observability.TraceOperation(ctx, s.tracer, "revoke_other_sessions", func(ctx context.Context) (int, error) {
+		now := time.Now()
 
-	key, err := totp.Generate(totp.GenerateOpts{
-		Issuer:      s.cfg.AppName,
-		AccountName: userEmail,
-		SecretSize:  20,
-		Digits:      otp.DigitsSix,
-		Algorithm:   otp.AlgorithmSHA1,
-		Period:      30,
-	})
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate 2FA secret")
-	}
+		revokedIDs, err := s.sessionRepo.RevokeAllByUserID(ctx, userID, keepSessionID, now)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.refreshTokenRepo.RevokeAllByUserID(ctx, userID, keepSessionID, now); err != nil {
+			return 0, err
+		}
 
-	return key, nil
+		for _, sessionID := range revokedIDs {
+			if err := s.denylist.RevokeSession(ctx, sessionID); err != nil {
+				return 0, apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to revoke session tokens")
+			}
+		}
+		return len(revokedIDs), nil
+	}, attribute.String("user_id", userID.String()), attribute.String("session_id", keepSessionID.String()))
+}
+
+// revokeAllSessions signs the user out everywhere: every session and stored refresh
+// token is revoked and every access token issued so far is put on the denylist.
+// Personal access tokens are left alone, see revokeAllCredentials.
+func (s *UserService) revokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "revoke_all_sessions", func(ctx context.Context) (struct{}, error) {
+		now := time.Now()
+		if _, err := s.sessionRepo.RevokeAllByUserID(ctx, userID, uuid.Nil, now); err != nil {
+			return struct{}{}, err
+		}
+		if err := s.refreshTokenRepo.RevokeAllByUserID(ctx, userID, uuid.Nil, now); err != nil {
+			return struct{}{}, err
+		}
+		if err := s.denylist.RevokeAllForUser(ctx, userID); err != nil {
+			return struct{}{}, apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to revoke access tokens")
+		}
+		return struct{}{}, nil
+	}, attribute.String("user_id", userID.String()))
+	return err
+}
+
+// revokeAllCredentials signs the user out everywhere like revokeAllSessions and also
+// revokes every personal access token. It is meant for account recovery, suspension
+// and deletion: personal access tokens bypass the denylist, so a token created by an
+// attacker would otherwise survive, while routine sign-outs keep integrations working.
+func (s *UserService) revokeAllCredentials(ctx context.Context, userID uuid.UUID) error {
+	if err := s.revokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	_, err := observability.TraceOperation(ctx, s.tracer, "revoke_personal_access_tokens", func(ctx context.Context) (struct{}, error) {
+		_, err := s.personalAccessTokenRepo.RevokeAllByUserID(ctx, userID, time.Now())
+		return struct{}{}, err
+	}, attribute.String("user_id", userID.String()))
+	return err
+}
+
+// cancelScheduledDeletion restores an account that was scheduled for deletion. Completing
+// a login within the grace period is how the user takes the deletion back.
+func (s *UserService) cancelScheduledDeletion(ctx context.Context, user *entity.User) error {
+	if !user.IsDeletionScheduled() {
+		return nil
+	}
+
+	user.CancelDeletion()
+	user.UpdatedAt = time.Now()
+	if err := s.updateUser(ctx, user); err != nil {
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Scheduled account deletion cancelled by login", "user_id", user.ID)
+	return nil
+}
+
+// checkLoginDevice remembers the device and network of a successful login and alerts
+// the user when either was not seen before. It is best-effort: a failure is logged
+// and never fails the login.
+func (s *UserService) checkLoginDevice(ctx context.Context, user *entity.User, client dto.ClientInfo) {
+	_, err := observability.TraceOperation(ctx, s.tracer, "check_login_device", func(ctx context.Context) (struct{}, error) {
+		now := time.Now()
+		device := useragent.Parse(client.UserAgent)
+		fingerprint := device.Fingerprint()
+		ipRange := geoip.IPRange(client.IPAddress)
+
+		location, err := s.geoip.Lookup(client.IPAddress)
+		if err != nil {
+			// The alert is still worth sending without a location
+			s.logger.WithContext(ctx).Warn("Failed to look up login location", "user_id", user.ID, "error", err)
+		}
+
+		knownDevices, err := s.knownDeviceRepo.ListByUserID(ctx, user.ID)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		fingerprintSeen, ipRangeSeen := false, false
+		for _, known := range knownDevices {
+			fingerprintSeen = fingerprintSeen || known.Fingerprint == fingerprint
+			ipRangeSeen = ipRangeSeen || known.IPRange == ipRange
+		}
+
+		err = s.knownDeviceRepo.Upsert(ctx, &entity.KnownDevice{
+			ID:          uuid.Must(uuid.NewV7()),
+			UserID:      user.ID,
+			Fingerprint: fingerprint,
+			IPRange:     ipRange,
+			Device:      device.String(),
+			Location:    location.String(),
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		// The first login after registration has nothing to compare against
+		if len(knownDevices) == 0 || (fingerprintSeen && ipRangeSeen) {
+			return struct{}{}, nil
+		}
+
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventNewDeviceLogin, entity.SecurityEventOutcomeSuccess, client, map[string]string{
+			"device":      device.String(),
+			"location":    location.String(),
+			"new_device":  strconv.FormatBool(!fingerprintSeen),
+			"new_network": strconv.FormatBool(!ipRangeSeen),
+		})
+
+		if !user.LoginAlertsEnabled {
+			return struct{}{}, nil
+		}
+		return struct{}{}, s.queueNewLoginAlertEmail(ctx, user, client, device.String(), location.String(), now)
+	}, attribute.String("user_id", user.ID.String()))
+	if err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to check login device", "user_id", user.ID, "error", err)
+	}
+}
+
+func (s *UserService) verifyToken(ctx context.Context, tokenString string, tokenType token.TokenType) (*token.Payload, error) {
+	_, span := s.tracer.Start(ctx, "verify_token")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("token.type", string(tokenType)))
+
+	payload, err := s.token.VerifyToken(tokenString, tokenType)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// claimSingleUseToken spends the token of a single-use email link. Links that were
+// revoked, individually or by a revoke-all, are rejected, and of several concurrent
+// requests with the same link only one gets through.
+func (s *UserService) claimSingleUseToken(ctx context.Context, payload *token.Payload) error {
+	revoked, err := s.denylist.IsRevoked(ctx, payload)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to check link token")
+	}
+	if revoked {
+		return apperror.ErrInvalidToken
+	}
+
+	claimed, err := s.denylist.ClaimToken(ctx, payload.ID, payload.ExpiresAt)
+	if err != nil {
+		return apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to claim link token")
+	}
+	if !claimed {
+		return apperror.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// formatRecoveryCode splits a raw recovery code in two halves so it is easier to copy.
+func formatRecoveryCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// normalizeRecoveryCode undoes the formatting and any casing or spacing the user
+// may have introduced while typing the code.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// recoveryCodeKeyInfo separates the recovery code key derived from AUTH_OTP_SECRET
+// from the key the one-time codes use.
+const recoveryCodeKeyInfo = "goca recovery codes v1"
+
+// hashRecoveryCode returns the HMAC-SHA256 of a normalized recovery code, keyed with a
+// subkey derived from AUTH_OTP_SECRET. Recovery codes were first stored with the
+// password hasher, but checking a wrong code then meant one slow hash per unused code
+// on a public endpoint. The codes are random and long enough that a keyed fast hash
+// is safe, and it lets a code be looked up directly. Codes stored the old way keep
+// working until they are regenerated, see redeemLegacyRecoveryCode.
+func (s *UserService) hashRecoveryCode(code string) (string, error) {
+	key, err := hkdf.Key(sha256.New, []byte(s.cfg.AuthOTPSecret), nil, recoveryCodeKeyInfo, sha256.Size)
+	if err != nil {
+		return "", apperror.NewAppErrorWithCause(apperror.ErrCodeInternalError, "Failed to hash recovery code", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// generateRecoveryCodes replaces every recovery code of the user with a fresh set and
+// returns the plain codes. Only their hashes are stored, so they can be shown only once.
+func (s *UserService) generateRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return observability.TraceOperation(ctx, s.tracer, "generate_recovery_codes", func(ctx context.Context) ([]string, error) {
+		if err := s.twoFactorRecoveryCodeRepo.DeleteByUserID(ctx, userID); err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		plainCodes := make([]string, 0, config.TwoFactorRecoveryCodeCount)
+		recoveryCodes := make([]*entity.TwoFactorRecoveryCode, 0, config.TwoFactorRecoveryCodeCount)
+
+		for range config.TwoFactorRecoveryCodeCount {
+			code, err := random.HexToken(config.TwoFactorRecoveryCodeLength)
+			if err != nil {
+				return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate recovery code")
+			}
+
+			codeHash, err := s.hashRecoveryCode(code)
+			if err != nil {
+				return nil, err
+			}
+
+			plainCodes = append(plainCodes, formatRecoveryCode(code))
+			recoveryCodes = append(recoveryCodes, &entity.TwoFactorRecoveryCode{
+				ID:        uuid.Must(uuid.NewV7()),
+				UserID:    userID,
+				CodeHash:  codeHash,
+				CreatedAt: now,
+			})
+		}
+
+		if err := s.twoFactorRecoveryCodeRepo.CreateBatch(ctx, recoveryCodes); err != nil {
+			return nil, err
+		}
+
+		return plainCodes, nil
+	}, attribute.String("user_id", userID.String()))
+}
+
+// redeemRecoveryCode marks the matching recovery code as used and returns how many
+// codes the user has left.
+func (s *UserService) redeemRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (int, error) {
+	return observability.TraceOperation(ctx, s.tracer, "redeem_recovery_code", func(ctx context.Context) (int, error) {
+		normalized := normalizeRecoveryCode(code)
+		codeHash, err := s.hashRecoveryCode(normalized)
+		if err != nil {
+			return 0, err
+		}
+
+		err = s.twoFactorRecoveryCodeRepo.MarkUsedByHash(ctx, userID, codeHash, time.Now())
+		if errors.Is(err, apperror.ErrNotFound) {
+			// unknown, redeemed concurrently by another request, or stored the old way
+			err = s.redeemLegacyRecoveryCode(ctx, userID, normalized)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		return s.twoFactorRecoveryCodeRepo.CountUnusedByUserID(ctx, userID)
+	}, attribute.String("user_id", userID.String()))
+}
+
+// redeemLegacyRecoveryCode checks a code against the codes still stored with the
+// password hasher. Only users who have not regenerated their codes since the switch
+// to hashRecoveryCode have any, so the slow hashes are limited to them.
+func (s *UserService) redeemLegacyRecoveryCode(ctx context.Context, userID uuid.UUID, normalized string) error {
+	recoveryCodes, err := s.twoFactorRecoveryCodeRepo.ListLegacyUnusedByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, recoveryCode := range recoveryCodes {
+		match, err := s.hasher.Verify(normalized, recoveryCode.CodeHash)
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Failed to verify recovery code hash", "recovery_code_id", recoveryCode.ID, "error", err)
+		}
+		if !match {
+			continue
+		}
+
+		if err := s.twoFactorRecoveryCodeRepo.MarkUsed(ctx, recoveryCode.ID, time.Now()); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				break // redeemed concurrently by another request
+			}
+			return err
+		}
+		return nil
+	}
+
+	return apperror.NewAppError(apperror.ErrCodeInvalidInput, "Invalid recovery code")
+}
+
+// verifySecondFactor accepts either a TOTP code or a recovery code for a user with 2FA
+// enabled. When a recovery code was redeemed the number of remaining codes is returned.
+func (s *UserService) verifySecondFactor(ctx context.Context, user *entity.User, code, recoveryCode string) (*int, error) {
+	if recoveryCode != "" {
+		remaining, err := s.redeemRecoveryCode(ctx, user.ID, recoveryCode)
+		if err != nil {
+			s.logger.WithContext(ctx).Warn("Invalid recovery code", "user_id", user.ID)
+			return nil, err
+		}
+
+		s.logger.WithContext(ctx).Info("Recovery code used", "user_id", user.ID, "remaining", remaining)
+		return &remaining, nil
+	}
+
+	if !totp.Validate(code, user.GetTwoFactorSecret()) {
+		s.logger.WithContext(ctx).Warn("Invalid 2FA code", "user_id", user.ID)
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Invalid 2FA code")
+	}
+
+	return nil, nil
+}
+
+// =============================================================================
+// EMAIL CHANGE HELPERS
+// =============================================================================
+
+// ensureEmailAvailable returns ErrUserAlreadyExists when another account uses the address.
+func (s *UserService) ensureEmailAvailable(ctx context.Context, email string) error {
+	_, err := s.getUserByEmail(ctx, email)
+	if err == nil {
+		return apperror.ErrUserAlreadyExists
+	}
+	if errors.Is(err, apperror.ErrUserNotFound) {
+		return nil
+	}
+	return err
+}
+
+// discardEmailChange removes a pending email change together with its code.
+func (s *UserService) discardEmailChange(ctx context.Context, userID uuid.UUID) {
+	if err := s.emailChangeRequestRepo.DeleteByUserID(ctx, userID); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to discard email change request", "user_id", userID, "error", err)
+	}
+	s.revokeOTP(ctx, otpcode.PurposeEmailChange, userID.String())
+}
+
+// sendEmailChangeCode issues the code for a stored email change request and queues
+// its emails. When that fails the request is discarded, so a code still pending from
+// an earlier request can never confirm the new address.
+func (s *UserService) sendEmailChangeCode(ctx context.Context, user *entity.User, request *entity.EmailChangeRequest, cancelToken string) error {
+	otpCode, err := s.issueOTP(ctx, otpcode.PurposeEmailChange, user.ID.String())
+	if err != nil {
+		s.discardEmailChange(ctx, user.ID)
+		return err
+	}
+
+	if err := s.queueEmailChangeEmails(ctx, user, request, otpCode, cancelToken); err != nil {
+		// The code never went out, so it does not hold back a new request either
+		s.discardEmailChange(ctx, user.ID)
+		s.cancelOTP(ctx, otpcode.PurposeEmailChange, user.ID.String())
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+// MFA CHALLENGE HELPERS
+// =============================================================================
+
+func mfaChallengeKey(challengeID uuid.UUID) string {
+	return fmt.Sprintf("mfa_challenge:%s", challengeID)
+}
+
+func mfaChallengeAttemptsKey(challengeID uuid.UUID) string {
+	return fmt.Sprintf("mfa_challenge_attempts:%s", challengeID)
+}
+
+// createMFAChallenge issues a short-lived challenge token after the password step
+// succeeded. The challenge is tracked in the cache so it can only be redeemed once
+// and only within a limited number of attempts.
+func (s *UserService) createMFAChallenge(ctx context.Context, userID uuid.UUID, remember bool) (*token.GenerateTokenResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "create_mfa_challenge")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("user_id", userID.String()))
+
+	challenge, err := s.token.GenerateToken(userID, token.TokenTypeMFAChallenge, config.MFAChallengeExpiry)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate 2FA challenge")
+	}
+
+	if err := s.cache.Set(ctx, mfaChallengeKey(challenge.ID), strconv.FormatBool(remember), config.MFAChallengeExpiry); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to store 2FA challenge")
+	}
+
+	return challenge, nil
+}
+
+// getMFAChallenge returns the remember flag of a pending challenge, or ErrInvalidToken
+// when the challenge was already redeemed, exhausted or has expired.
+func (s *UserService) getMFAChallenge(ctx context.Context, challengeID uuid.UUID) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "get_mfa_challenge")
+	defer span.End()
+
+	return s.readMFAChallenge(ctx, span, challengeID, s.cache.Get)
+}
+
+// consumeMFAChallenge removes a pending challenge in one atomic step and returns its
+// remember flag. When several requests race with a valid code only one of them gets
+// the challenge; the others see ErrInvalidToken.
+func (s *UserService) consumeMFAChallenge(ctx context.Context, challengeID uuid.UUID) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "consume_mfa_challenge")
+	defer span.End()
+
+	remember, err := s.readMFAChallenge(ctx, span, challengeID, s.cache.GetDelete)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.cache.Delete(ctx, mfaChallengeAttemptsKey(challengeID)); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to delete cache", "cache_key", mfaChallengeAttemptsKey(challengeID), "error", err)
+	}
+
+	return remember, nil
+}
+
+func (s *UserService) readMFAChallenge(ctx context.Context, span trace.Span, challengeID uuid.UUID, read func(context.Context, string) (any, error)) (bool, error) {
+	value, err := read(ctx, mfaChallengeKey(challengeID))
+	if err != nil {
+		if cache.IsNotFound(err) {
+			return false, apperror.ErrInvalidToken
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to get 2FA challenge")
+	}
+
+	remember, err := strconv.ParseBool(fmt.Sprint(value))
+	if err != nil {
+		return false, apperror.ErrInvalidToken
+	}
+
+	return remember, nil
+}
+
+// registerMFAChallengeAttempt counts an attempt against the challenge and burns the
+// challenge once the limit is exceeded, forcing the user to log in again.
+func (s *UserService) registerMFAChallengeAttempt(ctx context.Context, challengeID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "register_mfa_challenge_attempt")
+	defer span.End()
+
+	attempts, err := s.cache.Increment(ctx, mfaChallengeAttemptsKey(challengeID), config.MFAChallengeExpiry)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to track 2FA attempts")
+	}
+
+	span.SetAttributes(attribute.Int64("mfa.attempts", attempts))
+
+	if attempts > config.MFAChallengeMaxAttempts {
+		s.deleteMFAChallenge(ctx, challengeID)
+		return apperror.NewAppError(apperror.ErrCodeTooManyRequests, "Too many invalid 2FA codes, please log in again")
+	}
+
+	return nil
+}
+
+func (s *UserService) deleteMFAChallenge(ctx context.Context, challengeID uuid.UUID) {
+	for _, key := range []string{mfaChallengeKey(challengeID), mfaChallengeAttemptsKey(challengeID)} {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to delete cache", "cache_key", key, "error", err)
+		}
+	}
+}
+
+// =============================================================================
+// ACCOUNT LOCKOUT HELPERS
+// =============================================================================
+
+// loginBackoff returns how long a user has to wait after failedCount consecutive
+// failed attempts. The delay doubles with every failure past the threshold.
+func loginBackoff(failedCount int) time.Duration {
+	if failedCount < config.LoginBackoffThreshold {
+		return 0
+	}
+
+	delay := config.LoginBackoffBaseDelay
+	for i := config.LoginBackoffThreshold; i < failedCount && delay < config.LoginBackoffMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, config.LoginBackoffMaxDelay)
+}
+
+// checkLoginAllowed rejects sign-in attempts for accounts that are locked or still
+// inside their backoff delay. The returned error carries the remaining wait time.
+func (s *UserService) checkLoginAllowed(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "check_login_allowed")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("user_id", userID.String()))
+
+	attempt, err := s.loginAttemptRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	now := time.Now()
+	if attempt.IsLocked(now) {
+		return apperror.ErrAccountLocked.WithRetryAfter(attempt.LockedUntil.Sub(now))
+	}
+
+	if wait := attempt.LastFailedAt.Add(loginBackoff(attempt.FailedCount)).Sub(now); wait > 0 {
+		return apperror.ErrLoginThrottled.WithRetryAfter(wait)
+	}
+
+	return nil
+}
+
+// registerFailedLogin counts a failed password or 2FA code. Once the lockout threshold
+// is reached the account is locked, the user is emailed an unlock link and
+// ErrAccountLocked is returned; otherwise the caller reports its own error.
+func (s *UserService) registerFailedLogin(ctx context.Context, user *entity.User, client dto.ClientInfo) error {
+	ctx, span := s.tracer.Start(ctx, "register_failed_login")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("user_id", user.ID.String()))
+
+	now := time.Now()
+	attempt, err := s.loginAttemptRepo.RegisterFailure(ctx, user.ID, now, now.Add(-config.LoginFailureWindow))
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to register failed login", "user_id", user.ID, "error", err)
+		span.RecordError(err)
+		return nil
+	}
+
+	span.SetAttributes(attribute.Int("login.failed_count", attempt.FailedCount))
+
+	if attempt.FailedCount < config.LoginLockoutThreshold {
+		return nil
+	}
+
+	lockedUntil := now.Add(config.LoginLockoutDuration)
+	if err := s.loginAttemptRepo.Lock(ctx, user.ID, lockedUntil); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to lock account", "user_id", user.ID, "error", err)
+		span.RecordError(err)
+		return nil
+	}
+
+	s.logger.WithContext(ctx).Warn("Account locked after too many failed logins", "user_id", user.ID, "failed_count", attempt.FailedCount)
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventAccountLocked, entity.SecurityEventOutcomeSuccess, client, map[string]string{
+		"failed_count": strconv.Itoa(attempt.FailedCount),
+		"locked_until": lockedUntil.Format(time.RFC3339),
+	})
+
+	if err := s.queueAccountLockedEmail(ctx, user, lockedUntil); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to queue account locked email", "user_id", user.ID, "error", err)
+	}
+
+	return apperror.ErrAccountLocked.WithRetryAfter(config.LoginLockoutDuration)
+}
+
+// clearFailedLogins resets the failure counter after the user proved their identity.
+func (s *UserService) clearFailedLogins(ctx context.Context, userID uuid.UUID) {
+	if err := s.loginAttemptRepo.DeleteByUserID(ctx, userID); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to clear login attempts", "user_id", userID, "error", err)
+	}
+}
+
+// isCredentialError reports whether err was caused by a wrong password or 2FA code,
+// as opposed to an infrastructure failure.
+func isCredentialError(err error) bool {
+	return errors.Is(err, apperror.ErrUserIncorrectPassword) || errors.Is(err, apperror.ErrInvalidInput)
+}
+
+// =============================================================================
+// WORKER HELPERS
+// =============================================================================
+
+func (s *UserService) queueVerificationEmail(ctx context.Context, user *entity.User, otpCode string) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendVerifyEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		emailPayload := worker.PayloadSendVerifyEmail{
+			UserID:                     user.ID,
+			Name:                       user.FullName,
+			Email:                      user.Email,
+			VerificationCode:           otpCode,
+			VerificationCodeExpiration: int(config.EmailConfirmationExpInMin.Minutes()),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendVerifyEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendVerifyEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queueForgotPasswordEmail(ctx context.Context, user *entity.User, otpCode string) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendForgotPasswordEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		emailPayload := worker.PayloadSendForgotPasswordEmail{
+			UserID:                     user.ID,
+			Name:                       user.FullName,
+			Email:                      user.Email,
+			VerificationCode:           otpCode,
+			VerificationCodeExpiration: int(config.EmailConfirmationExpInMin.Minutes()),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendVerifyEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendForgotPasswordEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queueVerificationLinkEmail(ctx context.Context, user *entity.User, linkToken string) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendVerificationLinkEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		emailPayload := worker.PayloadSendVerificationLinkEmail{
+			UserID:         user.ID,
+			Name:           user.FullName,
+			Email:          user.Email,
+			LinkToken:      linkToken,
+			LinkExpiration: int(config.EmailConfirmationExpInMin.Minutes()),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendVerificationLinkEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendVerificationLinkEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queuePasswordResetLinkEmail(ctx context.Context, user *entity.User, linkToken string) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendPasswordResetLinkEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		emailPayload := worker.PayloadSendPasswordResetLinkEmail{
+			UserID:         user.ID,
+			Name:           user.FullName,
+			Email:          user.Email,
+			LinkToken:      linkToken,
+			LinkExpiration: int(config.EmailConfirmationExpInMin.Minutes()),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendPasswordResetLinkEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendPasswordResetLinkEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+// queueEmailChangeEmails sends the confirmation code to the new address and a notice
+// with a cancel link to the current one.
+func (s *UserService) queueEmailChangeEmails(ctx context.Context, user *entity.User, request *entity.EmailChangeRequest, otpCode, cancelToken string) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendEmailChangeEmails", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		expiration := int(config.EmailConfirmationExpInMin.Minutes())
+
+		verificationPayload := worker.PayloadSendEmailChangeVerification{
+			UserID:                     user.ID,
+			Name:                       user.FullName,
+			Email:                      request.NewEmail,
+			VerificationCode:           otpCode,
+			VerificationCodeExpiration: expiration,
+		}
+
+		verificationOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendEmailChangeVerificationMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		if err := s.worker.DistributeTaskSendEmailChangeVerification(ctx, &verificationPayload, verificationOptions...); err != nil {
+			return struct{}{}, err
+		}
+
+		noticePayload := worker.PayloadSendEmailChangeNotice{
+			UserID:               user.ID,
+			Name:                 user.FullName,
+			Email:                user.Email,
+			NewEmail:             request.NewEmail,
+			CancelToken:          cancelToken,
+			CancelLinkExpiration: expiration,
+		}
+
+		noticeOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendEmailChangeNoticeMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendEmailChangeNotice(ctx, &noticePayload, noticeOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queuePasswordChangedEmail(ctx context.Context, user *entity.User, client dto.ClientInfo) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendPasswordChangedEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		emailPayload := worker.PayloadSendPasswordChangedEmail{
+			UserID:    user.ID,
+			Name:      user.FullName,
+			Email:     user.Email,
+			ChangedAt: user.UpdatedAt.Format(time.RFC1123),
+			Device:    useragent.Parse(client.UserAgent).String(),
+			IPAddress: client.IPAddress,
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendPasswordChangedEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendPasswordChangedEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queueAccountDeletionScheduledEmail(ctx context.Context, user *entity.User) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendAccountDeletionScheduledEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		emailPayload := worker.PayloadSendAccountDeletionScheduledEmail{
+			UserID:       user.ID,
+			Name:         user.FullName,
+			Email:        user.Email,
+			DeletionDate: user.DeletionScheduledAt.Format(time.RFC1123),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendAccountDeletionScheduledEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendAccountDeletionScheduledEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queueAccountLockedEmail(ctx context.Context, user *entity.User, lockedUntil time.Time) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendAccountLockedEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		unlockToken, err := s.token.GenerateToken(user.ID, token.TokenTypeAccountUnlock, config.LoginLockoutDuration)
+		if err != nil {
+			return struct{}{}, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate unlock token")
+		}
+
+		emailPayload := worker.PayloadSendAccountLockedEmail{
+			UserID:               user.ID,
+			Name:                 user.FullName,
+			Email:                user.Email,
+			LockedUntil:          lockedUntil.Format(time.RFC1123),
+			UnlockToken:          unlockToken.Value,
+			UnlockLinkExpiration: int(config.LoginLockoutDuration.Minutes()),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendAccountLockedEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendAccountLockedEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queueNewLoginAlertEmail(ctx context.Context, user *entity.User, client dto.ClientInfo, device, location string, loginAt time.Time) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendNewLoginAlertEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.user_id", user.ID.String()),
+		)
+
+		revokeToken, err := s.token.GenerateToken(user.ID, token.TokenTypeLoginAlert, config.LoginAlertRevokeLinkExpiry)
+		if err != nil {
+			return struct{}{}, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate login alert token")
+		}
+
+		emailPayload := worker.PayloadSendNewLoginAlertEmail{
+			UserID:               user.ID,
+			Name:                 user.FullName,
+			Email:                user.Email,
+			LoginAt:              loginAt.Format(time.RFC1123),
+			Device:               device,
+			IPAddress:            client.IPAddress,
+			Location:             location,
+			RevokeToken:          revokeToken.Value,
+			RevokeLinkExpiration: int(config.LoginAlertRevokeLinkExpiry.Hours() / 24),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendNewLoginAlertEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendNewLoginAlertEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+func (s *UserService) queueInviteEmail(ctx context.Context, invite *entity.Invite, inviterName, inviteToken string) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "queue.SendInviteEmail", func(ctx context.Context) (struct{}, error) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(
+			attribute.String("worker.queue", worker.Critical),
+			attribute.String("worker.invite_id", invite.ID.String()),
+		)
+
+		emailPayload := worker.PayloadSendInviteEmail{
+			InviteID:         invite.ID,
+			Email:            invite.Email,
+			InviterName:      inviterName,
+			InviteToken:      inviteToken,
+			InviteExpiration: int(config.InviteExpiry.Hours() / 24),
+		}
+
+		taskOptions := []asynq.Option{
+			asynq.MaxRetry(worker.TaskSendInviteEmailMaxRetry),
+			asynq.Queue(worker.Critical),
+		}
+
+		return struct{}{}, s.worker.DistributeTaskSendInviteEmail(ctx, &emailPayload, taskOptions...)
+	})
+	return err
+}
+
+// =============================================================================
+// REGISTRATION HELPERS
+// =============================================================================
+
+// checkRegistrationAllowed applies the registration mode to a sign-up and returns the
+// invite it redeems, if any. A valid invite admits its address in every mode except
+// closed, so invite-only and domain-allowlist deployments can still let outsiders in.
+func (s *UserService) checkRegistrationAllowed(ctx context.Context, req *dto.RegisterRequest) (*entity.Invite, error) {
+	var invite *entity.Invite
+	if req.InviteToken != "" {
+		var err error
+		invite, err = s.getRedeemableInvite(ctx, req.InviteToken, req.Email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch s.cfg.RegistrationMode {
+	case config.RegistrationModeClosed:
+		return nil, apperror.ErrRegistrationClosed
+	case config.RegistrationModeInviteOnly:
+		if invite == nil {
+			return nil, apperror.ErrInviteRequired
+		}
+	case config.RegistrationModeDomainAllowlist:
+		if invite == nil && !isEmailDomainAllowed(req.Email, s.cfg.RegistrationAllowedDomains) {
+			return nil, apperror.ErrEmailDomainNotAllowed
+		}
+	}
+
+	return invite, nil
+}
+
+// getRedeemableInvite looks up the invite behind a token and checks it was sent to
+// the address registering. Unknown, used and revoked invites, and invites for another
+// address, are all reported as invalid so tokens cannot be probed.
+func (s *UserService) getRedeemableInvite(ctx context.Context, inviteToken, email string) (*entity.Invite, error) {
+	invite, err := s.inviteRepo.GetByTokenHash(ctx, token.HashInviteToken(inviteToken))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.ErrInvalidInvite
+		}
+		return nil, err
+	}
+
+	if invite.IsRedeemed() || invite.IsRevoked() || !invite.IsFor(email) {
+		return nil, apperror.ErrInvalidInvite
+	}
+
+	if invite.IsExpired(time.Now()) {
+		return nil, apperror.ErrInviteExpired
+	}
+
+	return invite, nil
+}
+
+// redeemInvite marks the invite as used by the new account. The update only succeeds
+// while the invite is pending, so two sign-ups racing for one invite cannot both win.
+func (s *UserService) redeemInvite(ctx context.Context, invite *entity.Invite, userID uuid.UUID) error {
+	now := time.Now()
+	if err := s.inviteRepo.MarkRedeemed(ctx, invite.ID, userID, now); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.ErrInvalidInvite
+		}
+		return err
+	}
+
+	invite.RedeemedAt = &now
+	invite.RedeemedBy = &userID
+	return nil
+}
+
+// isEmailDomainAllowed reports whether the domain of the address is one of domains,
+// which are expected in lowercase. Subdomains have to be listed on their own.
+func isEmailDomainAllowed(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	return slices.Contains(domains, strings.ToLower(email[at+1:]))
+}
+
+// =============================================================================
+// BUSINESS LOGIC VALIDATION HELPERS
+// =============================================================================
+
+// requirePermission loads the acting user and rejects the request unless their role
+// grants the permission. It reads the role from the database rather than the token,
+// so revoking a role takes effect immediately for the actions guarded here.
+func (s *UserService) requirePermission(ctx context.Context, actorID uuid.UUID, permission string) error {
+	actor, err := s.getUserByID(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.ErrForbidden
+		}
+		return err
+	}
+
+	if !actor.IsActive() {
+		return apperror.ErrForbidden
+	}
+
+	permissions, err := s.rolePermissions(ctx, actor.Role)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(permissions, permission) {
+		return apperror.ErrForbidden
+	}
+
+	return nil
+}
+
+// getAdminTarget loads the user an admin action is aimed at.
+func (s *UserService) getAdminTarget(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := s.getUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// recordAdminAction writes an entry to the admin audit log. Run it in the same
+// transaction as the action so that an action is never applied without its entry.
+func (s *UserService) recordAdminAction(ctx context.Context, actorID uuid.UUID, targetUserID *uuid.UUID, action entity.AdminAction, client dto.ClientInfo, metadata map[string]string) error {
+	_, err := observability.TraceOperation(ctx, s.tracer, "record_admin_action", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.adminAuditLogRepo.Create(ctx, &entity.AdminAuditLog{
+			ID:           uuid.Must(uuid.NewV7()),
+			ActorID:      actorID,
+			TargetUserID: targetUserID,
+			Action:       action,
+			Metadata:     metadata,
+			IPAddress:    client.IPAddress,
+			UserAgent:    client.UserAgent,
+			CreatedAt:    time.Now(),
+		})
+	}, attribute.String("actor_id", actorID.String()), attribute.String("action", string(action)))
+	return err
+}
+
+// recordSecurityEvent adds an entry to the security event log of a user. It is written
+// outside the transaction of the operation so that failed attempts are kept as well,
+// and an error writing it is logged rather than failing the request. userID is
+// uuid.Nil when the attempt could not be tied to an account.
+func (s *UserService) recordSecurityEvent(ctx context.Context, userID uuid.UUID, eventType entity.SecurityEventType, outcome entity.SecurityEventOutcome, client dto.ClientInfo, metadata map[string]string) {
+	var eventUserID *uuid.UUID
+	if userID != uuid.Nil {
+		eventUserID = &userID
+	}
+
+	requestID, _ := ctx.Value(config.RequestIDContextKey).(string)
+
+	_, err := observability.TraceOperation(ctx, s.tracer, "record_security_event", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.securityEventRepo.Create(ctx, &entity.SecurityEvent{
+			ID:        uuid.Must(uuid.NewV7()),
+			UserID:    eventUserID,
+			EventType: eventType,
+			Outcome:   outcome,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+			RequestID: requestID,
+			Metadata:  metadata,
+			CreatedAt: time.Now(),
+		})
+	}, attribute.String("event_type", string(eventType)), attribute.String("outcome", string(outcome)))
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to record security event", "user_id", userID, "event_type", eventType, "error", err)
+	}
+}
+
+// securityEventFailure describes a failed attempt by the code of the error that ended it.
+func securityEventFailure(err error) map[string]string {
+	code := apperror.ErrCodeInternalError
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		code = appErr.Code
+	}
+	return map[string]string{"reason": string(code)}
+}
+
+func (s *UserService) validateUserState(user *entity.User, requireEmailVerified, requireActive bool) error {
+	if requireEmailVerified && !user.IsEmailVerified() {
+		return apperror.ErrUserEmailNotVerified
+	}
+	if requireActive && !user.IsActive() {
+		return apperror.ErrUserInactive
+	}
+	return nil
+}
+
+// =============================================================================
+// 2FA HELPERS
+// =============================================================================
+
+func (s *UserService) generate2FASecret(ctx context.Context, userEmail string) (*otp.Key, error) {
+	_, span := s.tracer.Start(ctx, "generate_2fa_secret")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("email", userEmail))
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.cfg.AppName,
+		AccountName: userEmail,
+		SecretSize:  20,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+		Period:      30,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate 2FA secret")
+	}
+
+	return key, nil
 }
 
 func (s *UserService) generateQRCode(ctx context.Context, otpAuthURL string) (string, error) {
 	_, span := s.tracer.Start(ctx, "generate_qr_code")
 	defer span.End()
 
-	qrCodeData, err := qrcode.Encode(otpAuthURL, qrcode.Medium, 256)
+	qrCodeData, err := qrcode.Encode(otpAuthURL, qrcode.Medium, 256)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate QR code")
+	}
+
+	return fmt.Sprintf("data:image/png;base64,%s", encoding.Base64Encode(qrCodeData)), nil
+}
+
+// =============================================================================
+// MAIN SERVICE METHODS
+// =============================================================================
+
+func (s *UserService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.RegisterResponse, error) {
+	s.logger.WithContext(ctx).Info("Creating new user", "email", req.Email)
+
+	ctx, span := s.tracer.Start(ctx, "service.Register")
+	defer span.End()
+
+	// Pre-transaction validations
+	if err := s.checkRateLimit(ctx, "register", req.Email); err != nil {
+		return nil, err
+	}
+
+	if s.cfg.RegistrationMode == config.RegistrationModeClosed {
+		return nil, apperror.ErrRegistrationClosed
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.NewValidationError(err)
+	}
+
+	var createdUser *entity.User
+
+	// Database transaction
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		// Check the registration mode first, so restricted deployments do not
+		// reveal which addresses already have an account
+		invite, err := s.checkRegistrationAllowed(txCtx, req)
+		if err != nil {
+			return err
+		}
+
+		// Check if user already exists
+		existingUser, err := s.getUserByEmail(txCtx, req.Email)
+		if err != nil && !errors.Is(err, apperror.ErrUserNotFound) {
+			return err
+		}
+		if existingUser != nil {
+			return apperror.ErrUserAlreadyExists
+		}
+
+		// Create user entity
+		user := dto.RegisterRequestToUserEntity(req)
+
+		// Hash password
+		hashedPassword, err := s.hashPassword(txCtx, user.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hashedPassword
+
+		// Create user in database
+		if err := s.createUser(txCtx, user); err != nil {
+			return err
+		}
+		createdUser = user
+
+		if invite != nil {
+			if err := s.redeemInvite(txCtx, invite, user.ID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// The account exists once the transaction commits, so a failed email does not
+	// fail the registration, the user can ask for a new code
+	sendErr := s.sendVerificationEmail(ctx, createdUser)
+	if sendErr != nil {
+		s.logger.WithContext(ctx).Warn("Failed to send verification email", "user_id", createdUser.ID, "error", sendErr)
+	}
+	s.recordOTPSent(ctx, createdUser.ID, req.Client, otpcode.PurposeEmailVerification, sendErr)
+
+	s.logger.WithContext(ctx).Info("User created successfully", "user_id", createdUser.ID)
+	return &dto.RegisterResponse{
+		UserResponse: dto.UserEntityToUserResponse(createdUser),
+	}, nil
+}
+
+func (s *UserService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	s.logger.WithContext(ctx).Info("User login attempt", "email", req.Email)
+
+	ctx, span := s.tracer.Start(ctx, "service.Login")
+	defer span.End()
+
+	// Pre-authentication checks
+	if err := s.checkRateLimit(ctx, "login", req.Email); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Get user
+	user, err := s.getUserByEmail(ctx, req.Email)
+	if err != nil {
+		s.recordSecurityEvent(ctx, uuid.Nil, entity.SecurityEventLogin, entity.SecurityEventOutcomeFailure, req.Client, map[string]string{
+			"reason": string(apperror.ErrCodeUserNotFound),
+			"email":  req.Email,
+		})
+		// Hide the actual error (user not found) for security
+		return nil, apperror.ErrUserIncorrectPassword
+	}
+
+	// Locked accounts are rejected before the password is even checked
+	if err := s.checkLoginAllowed(ctx, user.ID); err != nil {
+		s.logger.WithContext(ctx).Warn("Login rejected, account locked or throttled", "user_id", user.ID)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventLogin, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return nil, err
+	}
+
+	// Verify password
+	if err := s.verifyPassword(ctx, req.Password, user.Password); err != nil {
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventLogin, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		if lockErr := s.registerFailedLogin(ctx, user, req.Client); lockErr != nil {
+			return nil, lockErr
+		}
+		return nil, err
+	}
+
+	s.rehashPasswordIfNeeded(ctx, user, req.Password)
+
+	// Business logic validations
+	if err := s.validateUserState(user, true, true); err != nil {
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventLogin, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return nil, err
+	}
+
+	// The password was right, so an expired one is not a failed login, but it
+	// has to be reset before the user can sign in again
+	if user.IsPasswordExpired(s.cfg.PasswordMaxAge, time.Now()) {
+		s.logger.WithContext(ctx).Info("Login rejected, password expired", "user_id", user.ID)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventLogin, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(apperror.ErrPasswordExpired))
+		return nil, apperror.ErrPasswordExpired
+	}
+
+	// Handle 2FA if enabled
+	if user.IsTwoFactorEnabled() {
+		challenge, err := s.createMFAChallenge(ctx, user.ID, req.Remember)
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger.WithContext(ctx).Info("Login requires 2FA", "user_id", user.ID)
+		return &dto.LoginResponse{
+			UserResponse:               dto.UserEntityToUserResponse(user),
+			TwoFactorEnabled:           true,
+			MFAChallengeToken:          challenge.Value,
+			MFAChallengeTokenExpiresAt: challenge.ExpiresAt,
+		}, nil
+	}
+
+	if err := s.cancelScheduledDeletion(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Generate authentication tokens
+	tokenPair, err := s.generateAuthTokens(ctx, user, req.Remember, req.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.clearFailedLogins(ctx, user.ID)
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventLogin, entity.SecurityEventOutcomeSuccess, req.Client, map[string]string{"method": "password"})
+	s.checkLoginDevice(ctx, user, req.Client)
+
+	s.logger.WithContext(ctx).Info("User logged in successfully", "user_id", user.ID)
+	return &dto.LoginResponse{
+		AccessToken:           tokenPair.AccessToken.Value,
+		RefreshToken:          tokenPair.RefreshToken.Value,
+		AccessTokenExpiresAt:  tokenPair.AccessToken.ExpiresAt,
+		RefreshTokenExpiresAt: tokenPair.RefreshToken.ExpiresAt,
+		UserResponse:          dto.UserEntityToUserResponse(user),
+	}, nil
+}
+
+func (s *UserService) VerifyOTP(ctx context.Context, req *dto.VerifyOTPRequest) (*dto.VerifyOTPResponse, error) {
+	s.logger.WithContext(ctx).Info("Verifying OTP", "email", req.Email)
+
+	ctx, span := s.tracer.Start(ctx, "service.VerifyOTP")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "verify_otp", req.Email); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, apperror.ErrUserNotFound) {
+			return nil, apperror.ErrInvalidOTP
+		}
+		return nil, err
+	}
+
+	if user.IsEmailVerified() {
+		return nil, apperror.NewAppError(apperror.ErrCodeBadRequest, "Email already verified")
+	}
+
+	// The code is checked before the transaction, so failed attempts are always counted and recorded
+	if err := s.verifyOTP(ctx, user.ID, req.Client, otpcode.PurposeEmailVerification, user.Email, req.OTP); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventEmailVerified, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return nil, err
+	}
+
+	if err := s.consumeOTP(ctx, otpcode.PurposeEmailVerification, user.Email, req.OTP); err != nil {
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventEmailVerified, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return nil, err
+	}
+
+	if err := s.completeEmailVerification(ctx, user, req.Client); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Email verified successfully", "user_id", user.ID)
+	return &dto.VerifyOTPResponse{
+		UserResponse: dto.UserEntityToUserResponse(user),
+	}, nil
+}
+
+func (s *UserService) ResendOTP(ctx context.Context, req *dto.ResendOTPRequest) error {
+	s.logger.WithContext(ctx).Info("Resending OTP", "email", req.Email)
+
+	ctx, span := s.tracer.Start(ctx, "service.ResendOTP")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "resend_otp", req.Email); err != nil {
+		return err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return apperror.NewValidationError(err)
+	}
+
+	var sentTo *entity.User
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		user, err := s.getUserByEmail(txCtx, req.Email)
+		if err != nil {
+			if errors.Is(err, apperror.ErrUserNotFound) {
+				return nil // Silent fail for security
+			}
+			return err
+		}
+
+		if user.IsEmailVerified() {
+			return apperror.NewAppError(apperror.ErrCodeBadRequest, "Email already verified")
+		}
+
+		sentTo = user
+		return nil
+	})
+
+	// The endpoint already tells verified and unverified addresses apart, so the
+	// cooldown is reported instead of silently skipping the email
+	if err == nil && sentTo != nil {
+		err = s.sendVerificationEmail(ctx, sentTo)
+	}
+
+	if sentTo != nil {
+		s.recordOTPSent(ctx, sentTo.ID, req.Client, otpcode.PurposeEmailVerification, err)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserService) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error) {
+	s.logger.WithContext(ctx).Info("Refreshing token")
+
+	ctx, span := s.tracer.Start(ctx, "service.RefreshToken")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	// Verify refresh token
+	payload, err := s.verifyToken(ctx, req.RefreshToken, token.TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		user          *entity.User
+		tokenPair     *TokenPair
+		reuseDetected bool
+	)
+
+	err = s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		storedToken, err := s.refreshTokenRepo.GetByIDForUpdate(txCtx, payload.ID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return apperror.ErrInvalidToken
+			}
+			return err
+		}
+
+		if storedToken.UserID != payload.UserID || storedToken.IsRevoked() || storedToken.IsExpired(time.Now()) {
+			return apperror.ErrInvalidToken
+		}
+
+		// A token that was already exchanged is being presented again: either the
+		// legitimate client or an attacker holds a stolen copy, so end the whole
+		// session, including the access tokens issued to whoever rotated first.
+		if storedToken.IsRotated() {
+			reuseDetected = true
+			return s.revokeSessionByID(txCtx, storedToken.FamilyID)
+		}
+
+		user, err = s.getUserByID(txCtx, payload.UserID)
+		if err != nil {
+			return apperror.ErrUserNotFound
+		}
+
+		if err := s.validateUserState(user, true, true); err != nil {
+			return err
+		}
+
+		storedToken.Rotate(time.Now())
+		if err := s.refreshTokenRepo.Update(txCtx, storedToken); err != nil {
+			return err
+		}
+
+		tokenPair, err = s.issueTokenPair(txCtx, user, storedToken.FamilyID, storedToken.Remember)
+		if err != nil {
+			return err
+		}
+
+		return s.touchSession(txCtx, user.ID, storedToken.FamilyID, req.Client, tokenPair.RefreshToken.ExpiresAt)
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if reuseDetected {
+		s.logger.WithContext(ctx).Warn("Refresh token reuse detected, session revoked", "user_id", payload.UserID, "token_id", payload.ID)
+		span.SetStatus(codes.Error, apperror.ErrRefreshTokenReused.Error())
+		return nil, apperror.ErrRefreshTokenReused
+	}
+
+	s.logger.WithContext(ctx).Info("Token refreshed successfully", "user_id", user.ID)
+	return &dto.RefreshTokenResponse{
+		AccessToken:           tokenPair.AccessToken.Value,
+		RefreshToken:          tokenPair.RefreshToken.Value,
+		AccessTokenExpiresAt:  tokenPair.AccessToken.ExpiresAt,
+		RefreshTokenExpiresAt: tokenPair.RefreshToken.ExpiresAt,
+	}, nil
+}
+
+func (s *UserService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	s.logger.WithContext(ctx).Info("Forgot password request", "email", req.Email)
+
+	ctx, span := s.tracer.Start(ctx, "service.ForgotPassword")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "forgot_password", req.Email); err != nil {
+		return err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	var (
+		sentTo  *entity.User
+		sendErr error
+	)
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		user, err := s.getUserByEmail(txCtx, req.Email)
+		if err != nil {
+			if errors.Is(err, apperror.ErrUserNotFound) {
+				return nil // Silent fail for security
+			}
+			return err
+		}
+
+		sentTo = user
+		return nil
+	})
+
+	if err == nil && sentTo != nil {
+		err = s.sendPasswordResetEmail(ctx, sentTo)
+		if errors.Is(err, apperror.ErrOTPResendCooldown) {
+			// Reporting the cooldown would reveal that the address has an account,
+			// the code or link sent earlier is still valid
+			sendErr, err = err, nil
+		}
+	}
+
+	if sentTo != nil {
+		s.recordOTPSent(ctx, sentTo.ID, req.Client, otpcode.PurposePasswordReset, errors.Join(err, sendErr))
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	s.logger.WithContext(ctx).Info("Reset password", "email", req.Email)
+
+	ctx, span := s.tracer.Start(ctx, "service.ResetPassword")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "reset_password", req.Email); err != nil {
+		return err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, apperror.ErrUserNotFound) {
+			return apperror.ErrInvalidOTP
+		}
+		return err
+	}
+
+	// The account's name is only known once it is looked up, so the password is
+	// checked against it here
+	req.AccountInfo = user.PersonalInfo()
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	// The code is checked before the transaction, so failed attempts are always counted and recorded
+	if err := s.verifyOTP(ctx, user.ID, req.Client, otpcode.PurposePasswordReset, user.Email, req.OTP); err != nil {
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordReset, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return err
+	}
+
+	// A recently used password is rejected before the code is taken, so the user can
+	// retry with the same code
+	if err := s.ensurePasswordNotReused(ctx, user, req.NewPassword); err != nil {
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordReset, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return err
+	}
+
+	// Taking the code atomically keeps concurrent requests from replaying it
+	if err := s.consumeOTP(ctx, otpcode.PurposePasswordReset, user.Email, req.OTP); err != nil {
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordReset, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return err
+	}
+
+	if err := s.completePasswordReset(ctx, user, req.NewPassword, req.Client); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Password reset successfully", "email", req.Email)
+	return nil
+}
+
+// ConsumeLink completes email verification or a password reset from an emailed link.
+// The link's token ID is checked against the pending secret of its flow, so a link
+// works once and stops working as soon as a newer code or link is sent.
+func (s *UserService) ConsumeLink(ctx context.Context, req *dto.ConsumeLinkRequest) (*dto.ConsumeLinkResponse, error) {
+	s.logger.WithContext(ctx).Info("Consuming email link", "purpose", req.Purpose)
+
+	ctx, span := s.tracer.Start(ctx, "service.ConsumeLink")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	purpose := otpcode.Purpose(req.Purpose)
+	payload, err := s.verifyToken(ctx, req.Token, linkTokenTypes[purpose])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRateLimit(ctx, "consume_link", payload.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUserByID(ctx, payload.UserID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrUserNotFound) {
+			return nil, apperror.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	eventType := entity.SecurityEventEmailVerified
+	if purpose == otpcode.PurposePasswordReset {
+		eventType = entity.SecurityEventPasswordReset
+
+		// The account's details are only known once the link is verified, so the
+		// password is checked against them here
+		req.AccountInfo = user.PersonalInfo()
+		if err := s.validator.ValidateAndGetErrors(req); err != nil {
+			span.RecordError(err)
+			return nil, apperror.NewValidationError(err)
+		}
+	} else if user.IsEmailVerified() {
+		return nil, apperror.NewAppError(apperror.ErrCodeBadRequest, "Email already verified")
+	}
+
+	// The link is checked before the transaction, so failed attempts are always counted and recorded
+	if err := s.verifyOTP(ctx, user.ID, req.Client, purpose, user.Email, payload.ID.String()); err != nil {
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, eventType, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return nil, err
+	}
+
+	if purpose == otpcode.PurposePasswordReset {
+		if err := s.ensurePasswordNotReused(ctx, user, req.NewPassword); err != nil {
+			span.RecordError(err)
+			s.recordSecurityEvent(ctx, user.ID, eventType, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+			return nil, err
+		}
+	}
+
+	// Taking the link atomically keeps concurrent requests with the same link from
+	// both completing the flow
+	if err := s.consumeOTP(ctx, purpose, user.Email, payload.ID.String()); err != nil {
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, eventType, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return nil, err
+	}
+
+	res := &dto.ConsumeLinkResponse{Purpose: req.Purpose}
+	if purpose == otpcode.PurposePasswordReset {
+		err = s.completePasswordReset(ctx, user, req.NewPassword, req.Client)
+	} else {
+		err = s.completeEmailVerification(ctx, user, req.Client)
+		res.User = dto.UserEntityToUserResponse(user)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Email link consumed", "user_id", user.ID, "purpose", purpose)
+	return res, nil
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the current
+// one. Every other session is signed out and the user is notified by email.
+func (s *UserService) ChangePassword(ctx context.Context, req *dto.ChangePasswordRequest) error {
+	s.logger.WithContext(ctx).Info("Change password attempt", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.ChangePassword")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "change_password", req.UserID.String()); err != nil {
+		return err
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		return apperror.ErrUserNotFound
+	}
+
+	// The password policy rejects new passwords built from the user's own name or email
+	req.AccountInfo = user.PersonalInfo()
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	if err := s.validateUserState(user, true, true); err != nil {
+		return err
+	}
+
+	if err := s.checkLoginAllowed(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// A wrong current password counts towards the lockout like a failed login
+	if err := s.verifyPassword(ctx, req.CurrentPassword, user.Password); err != nil {
+		s.logger.WithContext(ctx).Warn("Change password rejected, wrong current password", "user_id", user.ID)
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordChanged, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		if lockErr := s.registerFailedLogin(ctx, user, req.Client); lockErr != nil {
+			return lockErr
+		}
+		return err
+	}
+
+	s.clearFailedLogins(ctx, user.ID)
+
+	if err := s.ensurePasswordNotReused(ctx, user, req.NewPassword); err != nil {
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordChanged, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		return err
+	}
+
+	err = s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.setPassword(txCtx, user, req.NewPassword); err != nil {
+			return err
+		}
+
+		// Tokens without a session cannot be told apart, so they are all signed out
+		if req.CurrentSessionID == uuid.Nil {
+			return s.revokeAllSessions(txCtx, user.ID)
+		}
+		_, err = s.revokeOtherSessions(txCtx, user.ID, req.CurrentSessionID)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPasswordChanged, entity.SecurityEventOutcomeSuccess, req.Client, nil)
+
+	if err := s.queuePasswordChangedEmail(ctx, user, req.Client); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to queue password changed email", "user_id", user.ID, "error", err)
+	}
+
+	s.logger.WithContext(ctx).Info("Password changed successfully", "user_id", user.ID)
+	return nil
+}
+
+// RequestEmailChange stores a pending email change and sends a confirmation code to
+// the new address. The address is not changed until ConfirmEmailChange succeeds.
+func (s *UserService) RequestEmailChange(ctx context.Context, req *dto.RequestEmailChangeRequest) (*dto.RequestEmailChangeResponse, error) {
+	s.logger.WithContext(ctx).Info("Email change requested", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.RequestEmailChange")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "request_email_change", req.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, apperror.ErrUserNotFound
+	}
+
+	if err := s.validateUserState(user, true, true); err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(req.NewEmail, user.Email) {
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "New email must be different from the current email")
+	}
+
+	if err := s.ensureEmailAvailable(ctx, req.NewEmail); err != nil {
+		return nil, err
+	}
+
+	cancelToken, err := s.token.GenerateToken(user.ID, token.TokenTypeEmailChangeCancel, config.EmailConfirmationExpInMin)
+	if err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate cancel token")
+	}
+
+	now := time.Now()
+	request := &entity.EmailChangeRequest{
+		UserID:        user.ID,
+		NewEmail:      req.NewEmail,
+		CancelTokenID: cancelToken.ID,
+		ExpiresAt:     now.Add(config.EmailConfirmationExpInMin),
+		CreatedAt:     now,
+	}
+
+	// A new request replaces the pending one, which also invalidates its cancel link.
+	// The code and emails only go out once the request is stored.
+	err = s.emailChangeRequestRepo.Upsert(ctx, request)
+	if err == nil {
+		err = s.sendEmailChangeCode(ctx, user, request, cancelToken.Value)
+	}
+	s.recordOTPSent(ctx, user.ID, req.Client, otpcode.PurposeEmailChange, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Email change pending confirmation", "user_id", user.ID)
+	return &dto.RequestEmailChangeResponse{
+		NewEmail:  request.NewEmail,
+		ExpiresAt: request.ExpiresAt,
+	}, nil
+}
+
+// ConfirmEmailChange swaps the email address once the code sent to the new address
+// is confirmed. The address is checked again because another account may have
+// registered it while the request was pending.
+func (s *UserService) ConfirmEmailChange(ctx context.Context, req *dto.ConfirmEmailChangeRequest) (*dto.ConfirmEmailChangeResponse, error) {
+	s.logger.WithContext(ctx).Info("Confirming email change", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.ConfirmEmailChange")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "confirm_email_change", req.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	request, err := s.emailChangeRequestRepo.GetByUserID(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.NewAppError(apperror.ErrCodeNotFound, "No pending email change")
+		}
+		return nil, err
+	}
+
+	if request.IsExpired(time.Now()) {
+		return nil, apperror.ErrOTPExpired
+	}
+
+	if err := s.verifyOTP(ctx, req.UserID, req.Client, otpcode.PurposeEmailChange, req.UserID.String(), req.OTP); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.consumeOTP(ctx, otpcode.PurposeEmailChange, req.UserID.String(), req.OTP); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var user *entity.User
+	err = s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		user, err = s.getUserByID(txCtx, req.UserID)
+		if err != nil {
+			return apperror.ErrUserNotFound
+		}
+
+		if err := s.ensureEmailAvailable(txCtx, request.NewEmail); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user.Email = request.NewEmail
+		user.VerifyEmail(now)
+		user.UpdatedAt = now
+
+		// The unique constraint catches a registration that slipped in after the check above
+		if err := s.updateUser(txCtx, user); err != nil {
+			return err
+		}
+
+		return s.emailChangeRequestRepo.DeleteByUserID(txCtx, user.ID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		// The address is gone for good, so the pending request can never succeed
+		if errors.Is(err, apperror.ErrUserAlreadyExists) {
+			s.discardEmailChange(ctx, req.UserID)
+			return nil, apperror.NewAppError(apperror.ErrCodeUserAlreadyExists, "The new email address has been taken by another account, please request the change again")
+		}
+		return nil, err
+	}
+
+	s.revokeOTP(ctx, otpcode.PurposeEmailChange, req.UserID.String())
+
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventEmailChanged, entity.SecurityEventOutcomeSuccess, req.Client, map[string]string{"email": user.Email})
+
+	s.logger.WithContext(ctx).Info("Email changed successfully", "user_id", user.ID)
+	return &dto.ConfirmEmailChangeResponse{
+		UserResponse: dto.UserEntityToUserResponse(user),
+	}, nil
+}
+
+// CancelEmailChange withdraws a pending email change through the link that was sent
+// to the current address.
+func (s *UserService) CancelEmailChange(ctx context.Context, req *dto.CancelEmailChangeRequest) error {
+	s.logger.WithContext(ctx).Info("Cancelling email change via email link")
+
+	ctx, span := s.tracer.Start(ctx, "service.CancelEmailChange")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	payload, err := s.verifyToken(ctx, req.Token, token.TokenTypeEmailChangeCancel)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkRateLimit(ctx, "cancel_email_change", payload.UserID.String()); err != nil {
+		return err
+	}
+
+	request, err := s.emailChangeRequestRepo.GetByUserID(ctx, payload.UserID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.ErrInvalidToken
+		}
+		return err
+	}
+
+	// The link only cancels the request it was sent for
+	if request.CancelTokenID != payload.ID {
+		return apperror.ErrInvalidToken
+	}
+
+	if err := s.emailChangeRequestRepo.DeleteByUserID(ctx, payload.UserID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	s.revokeOTP(ctx, otpcode.PurposeEmailChange, payload.UserID.String())
+
+	s.logger.WithContext(ctx).Info("Email change cancelled", "user_id", payload.UserID)
+	return nil
+}
+
+// Reauthenticate asks the user to prove their identity again and issues a short-lived
+// re-authentication token that sensitive actions require. The token is bound to the
+// session it was issued for, so it stops working once that session is revoked. Users
+// with 2FA enabled must also provide a TOTP code or a recovery code.
+func (s *UserService) Reauthenticate(ctx context.Context, req *dto.ReauthenticateRequest) (*dto.ReauthenticateResponse, error) {
+	s.logger.WithContext(ctx).Info("User re-authentication attempt", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.Reauthenticate")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "reauthenticate", req.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, apperror.ErrUserNotFound
+	}
+
+	if err := s.validateUserState(user, true, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkLoginAllowed(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyPassword(ctx, req.Password, user.Password); err != nil {
+		s.logger.WithContext(ctx).Warn("Re-authentication failed", "user_id", user.ID)
+		span.RecordError(err)
+		s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventReauthenticated, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+		if lockErr := s.registerFailedLogin(ctx, user, req.Client); lockErr != nil {
+			return nil, lockErr
+		}
+		return nil, err
+	}
+
+	if user.IsTwoFactorEnabled() {
+		if req.Code == "" && req.RecoveryCode == "" {
+			return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "2FA code is required")
+		}
+		if _, err := s.verifySecondFactor(ctx, user, req.Code, req.RecoveryCode); err != nil {
+			span.RecordError(err)
+			if isCredentialError(err) {
+				s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventReauthenticated, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+				if lockErr := s.registerFailedLogin(ctx, user, req.Client); lockErr != nil {
+					return nil, lockErr
+				}
+			}
+			return nil, err
+		}
+	}
+
+	s.clearFailedLogins(ctx, user.ID)
+
+	reauthToken, err := s.token.GenerateToken(user.ID, token.TokenTypeReauth, config.ReauthTokenExpiry, token.WithSessionID(req.SessionID))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate re-authentication token")
+	}
+
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventReauthenticated, entity.SecurityEventOutcomeSuccess, req.Client, nil)
+
+	s.logger.WithContext(ctx).Info("User re-authenticated successfully", "user_id", user.ID)
+	return &dto.ReauthenticateResponse{
+		ReauthToken:          reauthToken.Value,
+		ReauthTokenExpiresAt: reauthToken.ExpiresAt,
+	}, nil
+}
+
+func (s *UserService) Setup2FA(ctx context.Context, req *dto.Setup2FARequest) (*dto.Setup2FAResponse, error) {
+	s.logger.WithContext(ctx).Info("Setting up 2FA", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.Setup2FA")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	var (
+		user       *entity.User
+		enrollment *entity.TwoFactorEnrollment
+	)
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		user, err = s.userRepo.GetByID(txCtx, req.UserID)
+		if err != nil {
+			s.logger.WithContext(txCtx).Error("Failed to get user", "error", err)
+			return apperror.ErrUserNotFound
+		}
+
+		if !user.IsEmailVerified() {
+			return apperror.ErrUserEmailNotVerified
+		}
+
+		if !user.IsActive() {
+			return apperror.ErrUserInactive
+		}
+
+		if user.IsTwoFactorEnabled() {
+			return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA already enabled")
+		}
+
+		key, err := s.generate2FASecret(txCtx, user.Email)
+		if err != nil {
+			s.logger.WithContext(txCtx).Error("Failed to generate 2FA secret", "error", err)
+			return apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate 2FA secret")
+		}
+
+		// The secret stays pending until Verify2FA confirms the first code,
+		// so an abandoned setup never locks the user into 2FA.
+		now := time.Now()
+		enrollment = &entity.TwoFactorEnrollment{
+			UserID:    user.ID,
+			Secret:    key.Secret(),
+			ExpiresAt: now.Add(config.TwoFactorEnrollmentExpiry),
+			CreatedAt: now,
+		}
+
+		if err := s.twoFactorEnrollmentRepo.Upsert(txCtx, enrollment); err != nil {
+			s.logger.WithContext(txCtx).Error("Failed to save 2FA enrollment", "error", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	issuer := url.QueryEscape(s.cfg.AppName)
+	accountName := url.QueryEscape(user.Email)
+
+	otpAuthURL := fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s&algorithm=SHA1&digits=6&period=30",
+		issuer, accountName, enrollment.Secret, issuer)
+
+	qrCodeBase64, err := s.generateQRCode(ctx, otpAuthURL)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("2FA setup successfully", "user_id", req.UserID)
+	return &dto.Setup2FAResponse{
+		Secret:    enrollment.Secret,
+		QRCode:    qrCodeBase64,
+		ExpiresAt: enrollment.ExpiresAt,
+	}, nil
+}
+
+func (s *UserService) Verify2FA(ctx context.Context, req *dto.Verify2FARequest) (*dto.Verify2FAResponse, error) {
+	s.logger.WithContext(ctx).Info("Verifying 2FA code", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.Verify2FA")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "verify_2fa", fmt.Sprintf("verify_2fa:%s", req.UserID)); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.checkLoginAllowed(ctx, req.UserID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var user *entity.User
+	var tokenPair *TokenPair
+	var recoveryCodes []string
+	var invalidCode bool
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		user, err = s.userRepo.GetByID(txCtx, req.UserID)
+		if err != nil {
+			s.logger.WithContext(txCtx).Error("Failed to get user", "error", err)
+			return apperror.ErrUserNotFound
+		}
+
+		if !user.IsEmailVerified() {
+			return apperror.ErrUserEmailNotVerified
+		}
+		if !user.IsActive() {
+			return apperror.ErrUserInactive
+		}
+		if user.IsTwoFactorEnabled() {
+			return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA already enabled")
+		}
+
+		enrollment, err := s.twoFactorEnrollmentRepo.GetByUserID(txCtx, user.ID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA not set up")
+			}
+			return err
+		}
+
+		if enrollment.IsExpired(time.Now()) {
+			return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA setup has expired, please start again")
+		}
+
+		if !totp.Validate(req.Code, enrollment.Secret) {
+			s.logger.WithContext(txCtx).Warn("Invalid 2FA code", "user_id", req.UserID)
+			invalidCode = true
+			return apperror.NewAppError(apperror.ErrCodeInvalidInput, "Invalid 2FA code")
+		}
+
+		user.SetTwoFactorSecret(enrollment.Secret)
+		user.EnableTwoFactor()
+		if err := s.userRepo.Update(txCtx, user); err != nil {
+			s.logger.WithContext(txCtx).Error("Failed to update user", "error", err)
+			return err
+		}
+
+		if err := s.twoFactorEnrollmentRepo.DeleteByUserID(txCtx, user.ID); err != nil {
+			return err
+		}
+
+		recoveryCodes, err = s.generateRecoveryCodes(txCtx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		// Sessions started before 2FA was confirmed are ended; only the pair issued here survives
+		if err := s.revokeAllSessions(txCtx, user.ID); err != nil {
+			return err
+		}
+
+		tokenPair, err = s.generateAuthTokens(txCtx, user, false, req.Client)
+		if err != nil {
+			s.logger.WithContext(txCtx).Error("Failed to generate auth tokens", "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		// Counted outside the transaction, which has been rolled back by now
+		if invalidCode {
+			s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventTwoFactorEnabled, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+			if lockErr := s.registerFailedLogin(ctx, user, req.Client); lockErr != nil {
+				return nil, lockErr
+			}
+		}
+		return nil, err
+	}
+
+	s.clearFailedLogins(ctx, user.ID)
+
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventTwoFactorEnabled, entity.SecurityEventOutcomeSuccess, req.Client, nil)
+
+	s.logger.WithContext(ctx).Info("2FA verified successfully", "user_id", req.UserID)
+	return &dto.Verify2FAResponse{
+		Verified:              true,
+		RecoveryCodes:         recoveryCodes,
+		AccessToken:           tokenPair.AccessToken.Value,
+		RefreshToken:          tokenPair.RefreshToken.Value,
+		AccessTokenExpiresAt:  tokenPair.AccessToken.ExpiresAt,
+		RefreshTokenExpiresAt: tokenPair.RefreshToken.ExpiresAt,
+	}, nil
+}
+
+func (s *UserService) Complete2FAChallenge(ctx context.Context, req *dto.TwoFactorChallengeRequest) (*dto.TwoFactorChallengeResponse, error) {
+	s.logger.WithContext(ctx).Info("Completing 2FA login challenge")
+
+	ctx, span := s.tracer.Start(ctx, "service.Complete2FAChallenge")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	payload, err := s.verifyToken(ctx, req.ChallengeToken, token.TokenTypeMFAChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRateLimit(ctx, "2fa_challenge", payload.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	remember, err := s.getMFAChallenge(ctx, payload.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.registerMFAChallengeAttempt(ctx, payload.ID); err != nil {
+		s.logger.WithContext(ctx).Warn("2FA challenge attempts exceeded", "user_id", payload.UserID)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	user, err := s.getUserByID(ctx, payload.UserID)
+	if err != nil {
+		return nil, apperror.ErrInvalidToken
+	}
+
+	if err := s.validateUserState(user, true, true); err != nil {
+		return nil, err
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		return nil, apperror.ErrInvalidToken
+	}
+
+	if err := s.checkLoginAllowed(ctx, user.ID); err != nil {
+		s.deleteMFAChallenge(ctx, payload.ID)
+		return nil, err
+	}
+
+	recoveryCodesRemaining, err := s.verifySecondFactor(ctx, user, req.Code, req.RecoveryCode)
+	if err != nil {
+		span.RecordError(err)
+		if isCredentialError(err) {
+			s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventLogin, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+			if lockErr := s.registerFailedLogin(ctx, user, req.Client); lockErr != nil {
+				s.deleteMFAChallenge(ctx, payload.ID)
+				return nil, lockErr
+			}
+		}
+		return nil, err
+	}
+
+	// The challenge is single-use: claim it before handing out tokens
+	remember, err = s.consumeMFAChallenge(ctx, payload.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.cancelScheduledDeletion(ctx, user); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tokenPair, err := s.generateAuthTokens(ctx, user, remember, req.Client)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.clearFailedLogins(ctx, user.ID)
+
+	method := "2fa"
+	if recoveryCodesRemaining != nil {
+		method = "recovery_code"
+	}
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventLogin, entity.SecurityEventOutcomeSuccess, req.Client, map[string]string{"method": method})
+	s.checkLoginDevice(ctx, user, req.Client)
+
+	s.logger.WithContext(ctx).Info("User logged in successfully with 2FA", "user_id", user.ID)
+	return &dto.TwoFactorChallengeResponse{
+		UserResponse:           dto.UserEntityToUserResponse(user),
+		AccessToken:            tokenPair.AccessToken.Value,
+		RefreshToken:           tokenPair.RefreshToken.Value,
+		AccessTokenExpiresAt:   tokenPair.AccessToken.ExpiresAt,
+		RefreshTokenExpiresAt:  tokenPair.RefreshToken.ExpiresAt,
+		RecoveryCodesRemaining: recoveryCodesRemaining,
+	}, nil
+}
+
+func (s *UserService) Disable2FA(ctx context.Context, req *dto.Disable2FARequest) error {
+	s.logger.WithContext(ctx).Info("Disabling 2FA", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.Disable2FA")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		return apperror.ErrUserNotFound
+	}
+
+	if err := s.validateUserState(user, true, true); err != nil {
+		return err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA not enabled")
+	}
+
+	if err := s.checkLoginAllowed(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// The code is checked outside the transaction, so a wrong guess is always recorded
+	// and counts towards the lockout like a failed login
+	if _, err := s.verifySecondFactor(ctx, user, req.Code, req.RecoveryCode); err != nil {
+		span.RecordError(err)
+		if isCredentialError(err) {
+			s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventTwoFactorDisabled, entity.SecurityEventOutcomeFailure, req.Client, securityEventFailure(err))
+			if lockErr := s.registerFailedLogin(ctx, user, req.Client); lockErr != nil {
+				return lockErr
+			}
+		}
+		return err
+	}
+
+	s.clearFailedLogins(ctx, user.ID)
+
+	err = s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		user.DisableTwoFactor()
+		if err := s.userRepo.Update(txCtx, user); err != nil {
+			s.logger.WithContext(txCtx).Error("Failed to update user", "error", err)
+			return err
+		}
+
+		if err := s.twoFactorRecoveryCodeRepo.DeleteByUserID(txCtx, user.ID); err != nil {
+			return err
+		}
+
+		return s.revokeAllSessions(txCtx, user.ID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	s.recordSecurityEvent(ctx, req.UserID, entity.SecurityEventTwoFactorDisabled, entity.SecurityEventOutcomeSuccess, req.Client, nil)
+
+	s.logger.WithContext(ctx).Info("2FA disabled successfully", "user_id", req.UserID)
+	return nil
+}
+
+func (s *UserService) Logout(ctx context.Context, req *dto.LogoutRequest) error {
+	s.logger.WithContext(ctx).Info("User logout", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.Logout")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	payload, err := s.verifyToken(ctx, req.RefreshToken, token.TokenTypeRefresh)
+	if err != nil {
+		return err
+	}
+
+	if payload.UserID != req.UserID {
+		return apperror.ErrInvalidToken
+	}
+
+	err = s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		storedToken, err := s.refreshTokenRepo.GetByIDForUpdate(txCtx, payload.ID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return apperror.ErrInvalidToken
+			}
+			return err
+		}
+
+		if storedToken.UserID != req.UserID {
+			return apperror.ErrInvalidToken
+		}
+
+		if err := s.revokeSessionByID(txCtx, storedToken.FamilyID); err != nil {
+			return err
+		}
+
+		if err := s.denylist.RevokeToken(txCtx, req.TokenID, req.TokenExpiresAt); err != nil {
+			return apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to revoke access token")
+		}
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("User logged out successfully", "user_id", req.UserID)
+	return nil
+}
+
+func (s *UserService) LogoutAll(ctx context.Context, req *dto.LogoutAllRequest) error {
+	s.logger.WithContext(ctx).Info("User logout from all devices", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.LogoutAll")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		return s.revokeAllSessions(txCtx, req.UserID)
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("User logged out from all devices", "user_id", req.UserID)
+	return nil
+}
+
+// ListSessions returns the devices the user is currently signed in on.
+func (s *UserService) ListSessions(ctx context.Context, req *dto.ListSessionsRequest) (*dto.ListSessionsResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.ListSessions")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	sessions, err := s.sessionRepo.ListActiveByUserID(ctx, req.UserID, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	responses := make([]*dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, dto.SessionEntityToSessionResponse(session, req.CurrentSessionID))
+	}
+
+	return &dto.ListSessionsResponse{
+		Sessions: responses,
+	}, nil
+}
+
+// RevokeSession signs the user out of a single session, which may be the current one.
+func (s *UserService) RevokeSession(ctx context.Context, req *dto.RevokeSessionRequest) error {
+	s.logger.WithContext(ctx).Info("Revoking session", "user_id", req.UserID, "session_id", req.SessionID)
+
+	ctx, span := s.tracer.Start(ctx, "service.RevokeSession")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		session, err := s.sessionRepo.GetByID(txCtx, req.SessionID)
+		if err != nil {
+			return err
+		}
+
+		// Sessions of other users are reported as missing so their IDs cannot be probed
+		if session.UserID != req.UserID {
+			return apperror.ErrNotFound
+		}
+
+		return s.revokeSession(txCtx, session)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Session revoked", "user_id", req.UserID, "session_id", req.SessionID)
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except the session making the request.
+func (s *UserService) RevokeOtherSessions(ctx context.Context, req *dto.RevokeOtherSessionsRequest) (*dto.RevokeOtherSessionsResponse, error) {
+	s.logger.WithContext(ctx).Info("Revoking other sessions", "user_id", req.UserID, "session_id", req.CurrentSessionID)
+
+	ctx, span := s.tracer.Start(ctx, "service.RevokeOtherSessions")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	var revoked int
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		revoked, err = s.revokeOtherSessions(txCtx, req.UserID, req.CurrentSessionID)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("Other sessions revoked", "user_id", req.UserID, "revoked", revoked)
+	return &dto.RevokeOtherSessionsResponse{
+		Revoked: revoked,
+	}, nil
+}
+
+// CreatePersonalAccessToken issues a long-lived token limited to the requested scopes.
+// Besides the scopes in authz.Scopes, a token may carry permissions the user's role
+// grants at the time of creation; they are intersected with the role again on every use.
+func (s *UserService) CreatePersonalAccessToken(ctx context.Context, req *dto.CreatePersonalAccessTokenRequest) (*dto.CreatePersonalAccessTokenResponse, error) {
+	s.logger.WithContext(ctx).Info("Creating personal access token", "user_id", req.UserID, "scopes", req.Scopes)
+
+	ctx, span := s.tracer.Start(ctx, "service.CreatePersonalAccessToken")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "create_personal_access_token", req.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && (!req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(config.PersonalAccessTokenMaxLifetime))) {
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Expiry must be in the future and within one year")
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, apperror.ErrUserNotFound
+	}
+
+	permissions, err := s.rolePermissions(ctx, user.Role)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !authz.IsScope(scope) && !slices.Contains(permissions, scope) {
+			return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, fmt.Sprintf("Unknown or unavailable scope %q", scope))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	value, hash, hint, err := token.GeneratePersonalAccessToken()
+	if err != nil {
+		span.RecordError(err)
+		return nil, apperror.WrapError(err, apperror.ErrCodeInternalError, "Failed to generate personal access token")
+	}
+
+	pat := &entity.PersonalAccessToken{
+		ID:        uuid.Must(uuid.NewV7()),
+		UserID:    user.ID,
+		Name:      req.Name,
+		TokenHash: hash,
+		TokenHint: hint,
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+
+	if err := s.personalAccessTokenRepo.Create(ctx, pat); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventPersonalAccessTokenCreated, entity.SecurityEventOutcomeSuccess, req.Client, map[string]string{
+		"token_id": pat.ID.String(),
+		"name":     pat.Name,
+	})
+
+	s.logger.WithContext(ctx).Info("Personal access token created", "user_id", user.ID, "token_id", pat.ID)
+	return &dto.CreatePersonalAccessTokenResponse{
+		PersonalAccessTokenResponse: dto.PersonalAccessTokenEntityToPersonalAccessTokenResponse(pat),
+		Token:                       value,
+	}, nil
+}
+
+func (s *UserService) ListPersonalAccessTokens(ctx context.Context, req *dto.ListPersonalAccessTokensRequest) (*dto.ListPersonalAccessTokensResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.ListPersonalAccessTokens")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	pats, err := s.personalAccessTokenRepo.ListActiveByUserID(ctx, req.UserID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	responses := make([]*dto.PersonalAccessTokenResponse, 0, len(pats))
+	for _, pat := range pats {
+		responses = append(responses, dto.PersonalAccessTokenEntityToPersonalAccessTokenResponse(pat))
+	}
+
+	return &dto.ListPersonalAccessTokensResponse{
+		Tokens: responses,
+	}, nil
+}
+
+func (s *UserService) RevokePersonalAccessToken(ctx context.Context, req *dto.RevokePersonalAccessTokenRequest) error {
+	s.logger.WithContext(ctx).Info("Revoking personal access token", "user_id", req.UserID, "token_id", req.TokenID)
+
+	ctx, span := s.tracer.Start(ctx, "service.RevokePersonalAccessToken")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		pat, err := s.personalAccessTokenRepo.GetByID(txCtx, req.TokenID)
+		if err != nil {
+			return err
+		}
+
+		// Tokens of other users are reported as missing so their IDs cannot be probed
+		if pat.UserID != req.UserID || pat.IsRevoked() {
+			return apperror.ErrNotFound
+		}
+
+		pat.Revoke(time.Now())
+		return s.personalAccessTokenRepo.Update(txCtx, pat)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	s.recordSecurityEvent(ctx, req.UserID, entity.SecurityEventPersonalAccessTokenRevoked, entity.SecurityEventOutcomeSuccess, req.Client, map[string]string{
+		"token_id": req.TokenID.String(),
+	})
+
+	s.logger.WithContext(ctx).Info("Personal access token revoked", "user_id", req.UserID, "token_id", req.TokenID)
+	return nil
+}
+
+// AuthenticatePersonalAccessToken resolves a personal access token sent as a bearer
+// token into the payload AuthMiddleware puts on the request. The token's permissions
+// are the scopes that the owner's current role still grants, so demoting a user also
+// narrows their tokens.
+func (s *UserService) AuthenticatePersonalAccessToken(ctx context.Context, value, ipAddress string) (*token.Payload, error) {
+	ctx, span := s.tracer.Start(ctx, "service.AuthenticatePersonalAccessToken")
+	defer span.End()
+
+	errInvalid := apperror.NewAppError(apperror.ErrCodeUnauthorized, "Personal access token is invalid, expired or revoked")
+
+	pat, err := s.personalAccessTokenRepo.GetByTokenHash(ctx, token.HashPersonalAccessToken(value))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, errInvalid
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if pat.IsRevoked() || pat.IsExpired(now) {
+		return nil, errInvalid
+	}
+
+	user, err := s.getUserByID(ctx, pat.UserID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, errInvalid
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.validateUserState(user, true, true); err != nil {
+		return nil, errInvalid
+	}
+
+	rolePermissions, err := s.rolePermissions(ctx, user.Role)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	permissions := make([]string, 0)
+	for _, scope := range pat.Scopes {
+		if slices.Contains(rolePermissions, scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	// Usage tracking must not fail the request it describes
+	if err := s.personalAccessTokenRepo.TouchLastUsed(ctx, pat.ID, now, now.Add(-config.PersonalAccessTokenLastUsedInterval), ipAddress); err != nil {
+		s.logger.WithContext(ctx).Warn("Failed to record personal access token usage", "token_id", pat.ID, "error", err)
+	}
+
+	payload := &token.Payload{
+		ID:          pat.ID,
+		UserID:      user.ID,
+		Type:        token.TokenTypePersonalAccess,
+		Audience:    token.AudienceAPI,
+		IssuedAt:    pat.CreatedAt,
+		Scopes:      pat.Scopes,
+		Permissions: permissions,
+	}
+	if pat.ExpiresAt != nil {
+		payload.ExpiresAt = *pat.ExpiresAt
+	}
+
+	return payload, nil
+}
+
+// ListSecurityEvents returns the security event log of the signed-in user.
+func (s *UserService) ListSecurityEvents(ctx context.Context, req *dto.ListSecurityEventsRequest) (*dto.ListSecurityEventsResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.ListSecurityEvents")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	res, err := s.securityEventRepo.FindAll(ctx, &dto.SecurityEventFilter{
+		UserID:    &req.UserID,
+		EventType: req.EventType,
+		Outcome:   req.Outcome,
+		Filter:    req.Filter,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *UserService) GetProfile(ctx context.Context, req *dto.GetProfileRequest) (*dto.UserResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.GetProfile")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, apperror.ErrUserNotFound
+	}
+
+	return dto.UserEntityToUserResponse(user), nil
+}
+
+func (s *UserService) UpdateProfile(ctx context.Context, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	s.logger.WithContext(ctx).Info("Updating user profile", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.UpdateProfile")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "update_profile", req.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, apperror.ErrUserNotFound
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		if *req.LastName == "" {
+			user.LastName = nil
+		} else {
+			user.LastName = req.LastName
+		}
+	}
+	if req.LoginAlertsEnabled != nil {
+		user.LoginAlertsEnabled = *req.LoginAlertsEnabled
+	}
+	user.GenerateFullName()
+	user.UpdatedAt = time.Now()
+
+	if err := s.updateUser(ctx, user); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).Info("User profile updated successfully", "user_id", user.ID)
+	return dto.UserEntityToUserResponse(user), nil
+}
+
+// DeleteAccount schedules the account for deletion after config.AccountDeletionGracePeriod
+// and signs the user out everywhere. Logging in again before then cancels the deletion.
+func (s *UserService) DeleteAccount(ctx context.Context, req *dto.DeleteAccountRequest) (*dto.DeleteAccountResponse, error) {
+	s.logger.WithContext(ctx).Info("Account deletion requested", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.DeleteAccount")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "delete_account", req.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	var user *entity.User
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		user, err = s.getUserByID(txCtx, req.UserID)
+		if err != nil {
+			return apperror.ErrUserNotFound
+		}
+
+		now := time.Now()
+		user.ScheduleDeletion(now.Add(config.AccountDeletionGracePeriod))
+		user.UpdatedAt = now
+
+		if err := s.updateUser(txCtx, user); err != nil {
+			return err
+		}
+
+		// Also revokes personal access tokens, so scripts cannot keep the account in
+		// use while it waits to be deleted
+		return s.revokeAllCredentials(txCtx, user.ID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := s.queueAccountDeletionScheduledEmail(ctx, user); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to queue account deletion scheduled email", "user_id", user.ID, "error", err)
+	}
+
+	s.recordSecurityEvent(ctx, user.ID, entity.SecurityEventAccountDeletionScheduled, entity.SecurityEventOutcomeSuccess, req.Client, map[string]string{
+		"deletion_scheduled_at": user.DeletionScheduledAt.Format(time.RFC3339),
+	})
+
+	s.logger.WithContext(ctx).Info("Account scheduled for deletion", "user_id", user.ID, "deletion_scheduled_at", user.DeletionScheduledAt)
+	return &dto.DeleteAccountResponse{
+		DeletionScheduledAt: *user.DeletionScheduledAt,
+	}, nil
+}
+
+func (s *UserService) GetRecoveryCodesStatus(ctx context.Context, req *dto.GetRecoveryCodesStatusRequest) (*dto.GetRecoveryCodesStatusResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.GetRecoveryCodesStatus")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	user, err := s.getUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, apperror.ErrUserNotFound
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		return nil, apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA not enabled")
+	}
+
+	remaining, err := s.twoFactorRecoveryCodeRepo.CountUnusedByUserID(ctx, user.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &dto.GetRecoveryCodesStatusResponse{
+		Remaining: remaining,
+	}, nil
+}
+
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, req *dto.RegenerateRecoveryCodesRequest) (*dto.RegenerateRecoveryCodesResponse, error) {
+	s.logger.WithContext(ctx).Info("Regenerating recovery codes", "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.RegenerateRecoveryCodes")
+	defer span.End()
+
+	if err := s.checkRateLimit(ctx, "regenerate_recovery_codes", req.UserID.String()); err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	var recoveryCodes []string
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		user, err := s.getUserByID(txCtx, req.UserID)
+		if err != nil {
+			return apperror.ErrUserNotFound
+		}
+
+		if !user.IsTwoFactorEnabled() {
+			return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA not enabled")
+		}
+
+		recoveryCodes, err = s.generateRecoveryCodes(txCtx, user.ID)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.recordSecurityEvent(ctx, req.UserID, entity.SecurityEventRecoveryCodesRegenerated, entity.SecurityEventOutcomeSuccess, req.Client, nil)
+
+	s.logger.WithContext(ctx).Info("Recovery codes regenerated", "user_id", req.UserID)
+	return &dto.RegenerateRecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// PurgeExpiredRefreshTokens deletes refresh tokens that can no longer be used.
+// It is meant to be run periodically by the scheduler.
+func (s *UserService) PurgeExpiredRefreshTokens(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeExpiredRefreshTokens")
+	defer span.End()
+
+	deleted, err := s.refreshTokenRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to purge expired refresh tokens", "error", err)
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Expired refresh tokens purged", "deleted", deleted)
+	return nil
+}
+
+// UnlockAccount lifts a lockout through the single-use link that was emailed to the user.
+func (s *UserService) UnlockAccount(ctx context.Context, req *dto.UnlockAccountRequest) error {
+	s.logger.WithContext(ctx).Info("Unlocking account via email link")
+
+	ctx, span := s.tracer.Start(ctx, "service.UnlockAccount")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	payload, err := s.verifyToken(ctx, req.Token, token.TokenTypeAccountUnlock)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkRateLimit(ctx, "unlock_account", payload.UserID.String()); err != nil {
+		return err
+	}
+
+	// The link is single-use
+	if err := s.claimSingleUseToken(ctx, payload); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := s.loginAttemptRepo.DeleteByUserID(ctx, payload.UserID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Account unlocked via email link", "user_id", payload.UserID)
+	return nil
+}
+
+// ReportLogin handles the "this wasn't me" link of a new login alert: the user is
+// signed out of every session. The link is single-use.
+func (s *UserService) ReportLogin(ctx context.Context, req *dto.ReportLoginRequest) error {
+	s.logger.WithContext(ctx).Info("Unrecognised login reported via email link")
+
+	ctx, span := s.tracer.Start(ctx, "service.ReportLogin")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	payload, err := s.verifyToken(ctx, req.Token, token.TokenTypeLoginAlert)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkRateLimit(ctx, "report_login", payload.UserID.String()); err != nil {
+		return err
+	}
+
+	// The link is single-use
+	if err := s.claimSingleUseToken(ctx, payload); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := s.revokeAllCredentials(ctx, payload.UserID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	s.recordSecurityEvent(ctx, payload.UserID, entity.SecurityEventLoginReported, entity.SecurityEventOutcomeSuccess, req.Client, nil)
+
+	s.logger.WithContext(ctx).Info("All sessions revoked after reported login", "user_id", payload.UserID)
+	return nil
+}
+
+// AdminUnlockAccount lets an administrator lift a lockout and reset the failure counter.
+func (s *UserService) AdminUnlockAccount(ctx context.Context, req *dto.AdminUnlockAccountRequest) error {
+	s.logger.WithContext(ctx).Info("Admin unlocking account", "actor_id", req.ActorID, "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.AdminUnlockAccount")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if _, err := s.getAdminTarget(ctx, req.UserID); err != nil {
+		return err
+	}
+
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.loginAttemptRepo.DeleteByUserID(txCtx, req.UserID); err != nil {
+			return err
+		}
+		return s.recordAdminAction(txCtx, req.ActorID, &req.UserID, entity.AdminActionUnlockAccount, req.Client, nil)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Account unlocked by admin", "actor_id", req.ActorID, "user_id", req.UserID)
+	return nil
+}
+
+func (s *UserService) AdminListUsers(ctx context.Context, req *dto.AdminListUsersRequest) (*dto.AdminListUsersResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.AdminListUsers")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersRead); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Roles are stored in a table and can be added at runtime, so the filter is
+	// checked against it rather than a fixed list
+	if req.Role != "" {
+		if _, err := s.roleRepo.GetByName(ctx, req.Role); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Unknown role")
+			}
+			return nil, err
+		}
+	}
+
+	res, err := s.userRepo.FindAll(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	metadata := map[string]string{
+		"keyword": req.Keyword,
+		"status":  req.Status,
+		"role":    req.Role,
+	}
+	if err := s.recordAdminAction(ctx, req.ActorID, nil, entity.AdminActionListUsers, req.Client, metadata); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *UserService) AdminListSecurityEvents(ctx context.Context, req *dto.AdminListSecurityEventsRequest) (*dto.ListSecurityEventsResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.AdminListSecurityEvents")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.requirePermission(ctx, req.ActorID, authz.SecurityEventsRead); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	filter := &dto.SecurityEventFilter{
+		EventType: req.EventType,
+		Outcome:   req.Outcome,
+		Filter:    req.Filter,
+	}
+	if req.UserID != "" {
+		// Already validated as a UUID
+		userID := uuid.MustParse(req.UserID)
+		filter.UserID = &userID
+	}
+
+	res, err := s.securityEventRepo.FindAll(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	metadata := map[string]string{
+		"user_id":    req.UserID,
+		"event_type": req.EventType,
+		"outcome":    req.Outcome,
+	}
+	if err := s.recordAdminAction(ctx, req.ActorID, filter.UserID, entity.AdminActionListSecurityEvents, req.Client, metadata); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *UserService) AdminGetUser(ctx context.Context, req *dto.AdminGetUserRequest) (*dto.UserResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.AdminGetUser")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersRead); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	user, err := s.getAdminTarget(ctx, req.UserID)
 	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAdminAction(ctx, req.ActorID, &user.ID, entity.AdminActionViewUser, req.Client, nil); err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return "", apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate QR code")
+		return nil, err
 	}
 
-	return fmt.Sprintf("data:image/png;base64,%s", encoding.Base64Encode(qrCodeData)), nil
+	return dto.UserEntityToUserResponse(user), nil
 }
 
-// =============================================================================
-// MAIN SERVICE METHODS
-// =============================================================================
-
-func (s *UserService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.RegisterResponse, error) {
-	s.logger.WithContext(ctx).Info("Creating new user", "email", req.Email)
+// AdminSuspendUser suspends a user and signs them out everywhere. Suspended users
+// cannot log in until an admin reactivates them.
+func (s *UserService) AdminSuspendUser(ctx context.Context, req *dto.AdminSuspendUserRequest) (*dto.UserResponse, error) {
+	s.logger.WithContext(ctx).Info("Admin suspending user", "actor_id", req.ActorID, "user_id", req.UserID)
 
-	ctx, span := s.tracer.Start(ctx, "service.Register")
+	ctx, span := s.tracer.Start(ctx, "service.AdminSuspendUser")
 	defer span.End()
 
-	// Pre-transaction validations
-	if err := s.checkRateLimit(ctx, "register", req.Email); err != nil {
-		return nil, err
-	}
-
 	if err := s.validator.ValidateAndGetErrors(req); err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return nil, apperror.NewValidationError(err)
 	}
 
-	var createdUser *entity.User
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
 
-	// Database transaction
+	if req.ActorID == req.UserID {
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Admins cannot suspend their own account")
+	}
+
+	var user *entity.User
 	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		// Check if user already exists
-		existingUser, err := s.getUserByEmail(txCtx, req.Email)
-		if err != nil && !errors.Is(err, apperror.ErrUserNotFound) {
+		var err error
+		user, err = s.getAdminTarget(txCtx, req.UserID)
+		if err != nil {
 			return err
 		}
-		if existingUser != nil {
-			return apperror.ErrUserAlreadyExists
-		}
-
-		// Create user entity
-		user := dto.RegisterRequestToUserEntity(req)
 
-		// Hash password
-		hashedPassword, err := s.hashPassword(txCtx, user.Password)
-		if err != nil {
-			return err
+		if user.IsSuspended() {
+			return apperror.NewAppError(apperror.ErrCodeConflict, "User is already suspended")
 		}
-		user.Password = hashedPassword
 
-		// Create user in database
-		if err := s.createUser(txCtx, user); err != nil {
+		user.Suspend()
+		user.UpdatedAt = time.Now()
+		if err := s.updateUser(txCtx, user); err != nil {
 			return err
 		}
-		createdUser = user
 
-		// Generate and cache OTP
-		otpCode, err := s.generateAndCacheOTP(txCtx, "email_verification", user.Email)
-		if err != nil {
+		if err := s.revokeAllCredentials(txCtx, user.ID); err != nil {
 			return err
 		}
 
-		// Queue verification email
-		return s.queueVerificationEmail(txCtx, user, otpCode)
+		return s.recordAdminAction(txCtx, req.ActorID, &user.ID, entity.AdminActionSuspendUser, req.Client, map[string]string{"reason": req.Reason})
 	})
-
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	s.logger.WithContext(ctx).Info("User created successfully", "user_id", createdUser.ID)
-	return &dto.RegisterResponse{
-		UserResponse: dto.UserEntityToUserResponse(createdUser),
-	}, nil
+	s.logger.WithContext(ctx).Info("User suspended by admin", "actor_id", req.ActorID, "user_id", user.ID)
+	return dto.UserEntityToUserResponse(user), nil
 }
 
-func (s *UserService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
-	s.logger.WithContext(ctx).Info("User login attempt", "email", req.Email)
+// AdminReactivateUser makes a suspended or inactive user active again. Pending users
+// have to verify their email instead, see AdminVerifyEmail.
+func (s *UserService) AdminReactivateUser(ctx context.Context, req *dto.AdminReactivateUserRequest) (*dto.UserResponse, error) {
+	s.logger.WithContext(ctx).Info("Admin reactivating user", "actor_id", req.ActorID, "user_id", req.UserID)
 
-	ctx, span := s.tracer.Start(ctx, "service.Login")
+	ctx, span := s.tracer.Start(ctx, "service.AdminReactivateUser")
 	defer span.End()
 
-	// Pre-authentication checks
-	if err := s.checkRateLimit(ctx, "login", req.Email); err != nil {
-		return nil, err
-	}
-
 	if err := s.validator.ValidateAndGetErrors(req); err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return nil, apperror.NewValidationError(err)
 	}
 
-	// Get user
-	user, err := s.getUserByEmail(ctx, req.Email)
-	if err != nil {
-		// Hide the actual error (user not found) for security
-		return nil, apperror.ErrUserIncorrectPassword
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	// Verify password
-	if err := s.verifyPassword(ctx, req.Password, user.Password); err != nil {
+	var user *entity.User
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		user, err = s.getAdminTarget(txCtx, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		if !user.IsSuspended() && !user.IsInactive() {
+			return apperror.NewAppError(apperror.ErrCodeConflict, "Only suspended or inactive users can be reactivated")
+		}
+
+		previousStatus := user.Status
+		user.Activate()
+		user.UpdatedAt = time.Now()
+		if err := s.updateUser(txCtx, user); err != nil {
+			return err
+		}
+
+		return s.recordAdminAction(txCtx, req.ActorID, &user.ID, entity.AdminActionReactivate, req.Client, map[string]string{"previous_status": string(previousStatus)})
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	// Business logic validations
-	if err := s.validateUserState(user, true, true); err != nil {
-		return nil, err
+	s.logger.WithContext(ctx).Info("User reactivated by admin", "actor_id", req.ActorID, "user_id", user.ID)
+	return dto.UserEntityToUserResponse(user), nil
+}
+
+// AdminVerifyEmail marks the email of a user as verified without the OTP and activates
+// a pending account, the same way VerifyOTP would.
+func (s *UserService) AdminVerifyEmail(ctx context.Context, req *dto.AdminVerifyEmailRequest) (*dto.UserResponse, error) {
+	s.logger.WithContext(ctx).Info("Admin verifying user email", "actor_id", req.ActorID, "user_id", req.UserID)
+
+	ctx, span := s.tracer.Start(ctx, "service.AdminVerifyEmail")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
 	}
 
-	// Handle 2FA if enabled
-	if user.IsTwoFactorEnabled() {
-		s.logger.WithContext(ctx).Info("Login requires 2FA", "user_id", user.ID)
-		return &dto.LoginResponse{
-			UserResponse:     dto.UserEntityToUserResponse(user),
-			TwoFactorEnabled: true,
-		}, nil
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	// Generate authentication tokens
-	tokenPair, err := s.generateAuthTokens(ctx, user.ID, req.Remember)
+	var user *entity.User
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		user, err = s.getAdminTarget(txCtx, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		if user.IsEmailVerified() {
+			return apperror.NewAppError(apperror.ErrCodeConflict, "Email already verified")
+		}
+
+		now := time.Now()
+		user.VerifyEmail(now)
+		if user.IsPending() {
+			user.Activate()
+		}
+		user.UpdatedAt = now
+		if err := s.updateUser(txCtx, user); err != nil {
+			return err
+		}
+
+		return s.recordAdminAction(txCtx, req.ActorID, &user.ID, entity.AdminActionVerifyEmail, req.Client, nil)
+	})
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	s.logger.WithContext(ctx).Info("User logged in successfully", "user_id", user.ID)
-	return &dto.LoginResponse{
-		AccessToken:           tokenPair.AccessToken.Value,
-		RefreshToken:          tokenPair.RefreshToken.Value,
-		AccessTokenExpiresAt:  tokenPair.AccessToken.ExpiresAt,
-		RefreshTokenExpiresAt: tokenPair.RefreshToken.ExpiresAt,
-		UserResponse:          dto.UserEntityToUserResponse(user),
-	}, nil
+	s.revokeOTP(ctx, otpcode.PurposeEmailVerification, user.Email)
+
+	s.logger.WithContext(ctx).Info("User email verified by admin", "actor_id", req.ActorID, "user_id", user.ID)
+	return dto.UserEntityToUserResponse(user), nil
 }
 
-func (s *UserService) VerifyOTP(ctx context.Context, req *dto.VerifyOTPRequest) (*dto.VerifyOTPResponse, error) {
-	s.logger.WithContext(ctx).Info("Verifying OTP", "email", req.Email)
+// AdminReset2FA turns off 2FA for a user who lost their authenticator, removes their
+// recovery codes and signs them out everywhere.
+func (s *UserService) AdminReset2FA(ctx context.Context, req *dto.AdminReset2FARequest) (*dto.UserResponse, error) {
+	s.logger.WithContext(ctx).Info("Admin resetting user 2FA", "actor_id", req.ActorID, "user_id", req.UserID)
 
-	ctx, span := s.tracer.Start(ctx, "service.VerifyOTP")
+	ctx, span := s.tracer.Start(ctx, "service.AdminReset2FA")
 	defer span.End()
 
-	if err := s.checkRateLimit(ctx, "verify_otp", req.Email); err != nil {
-		return nil, err
-	}
-
 	if err := s.validator.ValidateAndGetErrors(req); err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return nil, apperror.NewValidationError(err)
 	}
 
-	var user *entity.User
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
 
+	var user *entity.User
 	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
 		var err error
-		user, err = s.getUserByEmail(txCtx, req.Email)
+		user, err = s.getAdminTarget(txCtx, req.UserID)
 		if err != nil {
-			if errors.Is(err, apperror.ErrUserNotFound) {
-				return apperror.ErrInvalidOTP
-			}
 			return err
 		}
 
-		if user.IsEmailVerified() {
-			return apperror.NewAppError(apperror.ErrCodeBadRequest, "Email already verified")
+		if !user.IsTwoFactorEnabled() {
+			return apperror.NewAppError(apperror.ErrCodeConflict, "2FA not enabled")
 		}
 
-		// Get and verify OTP
-		cachedOTP, err := s.getCachedOTP(txCtx, "email_verification", req.Email)
-		if err != nil {
+		user.DisableTwoFactor()
+		user.UpdatedAt = time.Now()
+		if err := s.updateUser(txCtx, user); err != nil {
 			return err
 		}
 
-		if cachedOTP != req.OTP {
-			return apperror.ErrInvalidOTP
+		if err := s.twoFactorRecoveryCodeRepo.DeleteByUserID(txCtx, user.ID); err != nil {
+			return err
 		}
 
-		// Update user
-		user.VerifyEmail(time.Now())
-		user.Activate()
+		if err := s.twoFactorEnrollmentRepo.DeleteByUserID(txCtx, user.ID); err != nil {
+			return err
+		}
 
-		if err := s.updateUser(txCtx, user); err != nil {
+		if err := s.revokeAllSessions(txCtx, user.ID); err != nil {
 			return err
 		}
 
-		// Clean up cache
-		return s.deleteCachedOTP(txCtx, "email_verification", req.Email)
+		return s.recordAdminAction(txCtx, req.ActorID, &user.ID, entity.AdminActionReset2FA, req.Client, nil)
 	})
-
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	s.logger.WithContext(ctx).Info("Email verified successfully", "user_id", user.ID)
-	return &dto.VerifyOTPResponse{
-		UserResponse: dto.UserEntityToUserResponse(user),
-	}, nil
+	s.logger.WithContext(ctx).Info("User 2FA reset by admin", "actor_id", req.ActorID, "user_id", user.ID)
+	return dto.UserEntityToUserResponse(user), nil
 }
 
-func (s *UserService) ResendOTP(ctx context.Context, req *dto.ResendOTPRequest) error {
-	s.logger.WithContext(ctx).Info("Resending OTP", "email", req.Email)
+// AdminResetPassword sends the user the same reset code or link as ForgotPassword. The
+// admin never sees it and the current password keeps working until it is reset.
+func (s *UserService) AdminResetPassword(ctx context.Context, req *dto.AdminResetPasswordRequest) error {
+	s.logger.WithContext(ctx).Info("Admin triggering password reset", "actor_id", req.ActorID, "user_id", req.UserID)
 
-	ctx, span := s.tracer.Start(ctx, "service.ResendOTP")
+	ctx, span := s.tracer.Start(ctx, "service.AdminResetPassword")
 	defer span.End()
 
-	if err := s.checkRateLimit(ctx, "resend_otp", req.Email); err != nil {
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return apperror.NewValidationError(err)
+	}
+
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
 		return err
 	}
 
-	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+	var user *entity.User
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		user, err = s.getAdminTarget(txCtx, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		return s.recordAdminAction(txCtx, req.ActorID, &user.ID, entity.AdminActionResetPassword, req.Client, nil)
+	})
+	if err == nil {
+		err = s.sendPasswordResetEmail(ctx, user)
+	}
+	s.recordOTPSent(ctx, req.UserID, req.Client, otpcode.PurposePasswordReset, err)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return apperror.NewValidationError(err)
+		return err
+	}
+
+	s.logger.WithContext(ctx).Info("Password reset triggered by admin", "actor_id", req.ActorID, "user_id", req.UserID)
+	return nil
+}
+
+func (s *UserService) AdminListRoles(ctx context.Context, req *dto.AdminListRolesRequest) (*dto.AdminListRolesResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.AdminListRoles")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.requirePermission(ctx, req.ActorID, authz.RolesManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	res := &dto.AdminListRolesResponse{
+		Roles: make([]*dto.RoleResponse, 0, len(roles)),
+	}
+	for _, role := range roles {
+		res.Roles = append(res.Roles, dto.RoleEntityToRoleResponse(role))
+	}
+
+	return res, nil
+}
+
+// AdminAssignRole gives a user another role and signs them out of every session, so
+// the new permissions apply at once. Admins cannot change their own role, so nobody
+// can grant themselves more permissions or lock the last admin out by mistake.
+func (s *UserService) AdminAssignRole(ctx context.Context, req *dto.AdminAssignRoleRequest) (*dto.UserResponse, error) {
+	s.logger.WithContext(ctx).Info("Admin assigning role", "actor_id", req.ActorID, "user_id", req.UserID, "role", req.Role)
+
+	ctx, span := s.tracer.Start(ctx, "service.AdminAssignRole")
+	defer span.End()
+
+	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+		span.RecordError(err)
+		return nil, apperror.NewValidationError(err)
+	}
+
+	if err := s.requirePermission(ctx, req.ActorID, authz.RolesManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if req.ActorID == req.UserID {
+		return nil, apperror.NewAppError(apperror.ErrCodeInvalidInput, "Admins cannot change their own role")
 	}
 
+	var user *entity.User
 	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		user, err := s.getUserByEmail(txCtx, req.Email)
-		if err != nil {
-			if errors.Is(err, apperror.ErrUserNotFound) {
-				return nil // Silent fail for security
+		if _, err := s.roleRepo.GetByName(txCtx, req.Role); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return apperror.NewAppError(apperror.ErrCodeInvalidInput, "Unknown role")
 			}
 			return err
 		}
 
-		if user.IsEmailVerified() {
-			return apperror.NewAppError(apperror.ErrCodeBadRequest, "Email already verified")
+		var err error
+		user, err = s.getAdminTarget(txCtx, req.UserID)
+		if err != nil {
+			return err
 		}
 
-		// Generate and cache new OTP
-		otpCode, err := s.generateAndCacheOTP(txCtx, "email_verification", user.Email)
-		if err != nil {
+		if string(user.Role) == req.Role {
+			return nil
+		}
+
+		previousRole := user.Role
+		user.Role = entity.UserRole(req.Role)
+		user.UpdatedAt = time.Now()
+		if err := s.updateUser(txCtx, user); err != nil {
 			return err
 		}
 
-		// Queue verification email
-		return s.queueVerificationEmail(txCtx, user, otpCode)
-	})
+		// Access tokens carry the permissions of the old role, so the user is signed
+		// out everywhere instead of keeping them until the tokens expire
+		if err := s.revokeAllSessions(txCtx, user.ID); err != nil {
+			return err
+		}
 
+		metadata := map[string]string{
+			"previous_role": string(previousRole),
+			"role":          req.Role,
+		}
+		return s.recordAdminAction(txCtx, req.ActorID, &user.ID, entity.AdminActionAssignRole, req.Client, metadata)
+	})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
-	return err
+	s.logger.WithContext(ctx).Info("Role assigned by admin", "actor_id", req.ActorID, "user_id", user.ID, "role", user.Role)
+	return dto.UserEntityToUserResponse(user), nil
 }
 
-func (s *UserService) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error) {
-	s.logger.WithContext(ctx).Info("Refreshing token")
+// AdminCreateInvite emails an invite to register with the given address. Pending
+// invites to the same address are revoked, so only the newest link works. The token
+// only ever leaves the server in the email.
+func (s *UserService) AdminCreateInvite(ctx context.Context, req *dto.AdminCreateInviteRequest) (*dto.InviteResponse, error) {
+	s.logger.WithContext(ctx).Info("Admin creating invite", "actor_id", req.ActorID, "email", req.Email)
 
-	ctx, span := s.tracer.Start(ctx, "service.RefreshToken")
+	ctx, span := s.tracer.Start(ctx, "service.AdminCreateInvite")
 	defer span.End()
 
 	if err := s.validator.ValidateAndGetErrors(req); err != nil {
@@ -646,341 +4410,276 @@ func (s *UserService) RefreshToken(ctx context.Context, req *dto.RefreshTokenReq
 		return nil, apperror.NewValidationError(err)
 	}
 
-	// Verify refresh token
-	payload, err := s.verifyToken(ctx, req.RefreshToken)
-	if err != nil {
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
-	// Get user
-	user, err := s.getUserByID(ctx, payload.UserID)
-	if err != nil {
-		return nil, apperror.ErrUserNotFound
+	if s.cfg.RegistrationMode == config.RegistrationModeClosed {
+		return nil, apperror.ErrRegistrationClosed
 	}
 
-	// Validate user state
-	if err := s.validateUserState(user, true, true); err != nil {
+	if err := s.ensureEmailAvailable(ctx, req.Email); err != nil {
 		return nil, err
 	}
 
-	// Generate new tokens
-	newAccess, err := s.token.GenerateToken(user.ID, s.cfg.AuthAccessTokenExpiry)
+	inviter, err := s.getUserByID(ctx, req.ActorID)
 	if err != nil {
-		span.RecordError(err)
-		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate access token")
+		return nil, err
 	}
 
-	newRefresh, err := s.token.GenerateToken(user.ID, s.cfg.AuthRefreshTokenExpiry)
+	inviteToken, tokenHash, err := token.GenerateInviteToken()
 	if err != nil {
 		span.RecordError(err)
-		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate refresh token")
+		return nil, apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate invite token")
 	}
 
-	s.logger.WithContext(ctx).Info("Token refreshed successfully", "user_id", user.ID)
-	return &dto.RefreshTokenResponse{
-		AccessToken:           newAccess.Value,
-		RefreshToken:          newRefresh.Value,
-		AccessTokenExpiresAt:  newAccess.ExpiresAt,
-		RefreshTokenExpiresAt: newRefresh.ExpiresAt,
-	}, nil
-}
-
-func (s *UserService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
-	s.logger.WithContext(ctx).Info("Forgot password request", "email", req.Email)
-
-	ctx, span := s.tracer.Start(ctx, "service.ForgotPassword")
-	defer span.End()
-
-	if err := s.checkRateLimit(ctx, "forgot_password", req.Email); err != nil {
-		return err
-	}
-
-	if err := s.validator.ValidateAndGetErrors(req); err != nil {
-		span.RecordError(err)
-		return apperror.NewValidationError(err)
+	now := time.Now()
+	invite := &entity.Invite{
+		ID:        uuid.Must(uuid.NewV7()),
+		Email:     req.Email,
+		TokenHash: tokenHash,
+		InvitedBy: &req.ActorID,
+		ExpiresAt: now.Add(config.InviteExpiry),
+		CreatedAt: now,
 	}
 
-	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		user, err := s.getUserByEmail(txCtx, req.Email)
-		if err != nil {
-			if errors.Is(err, apperror.ErrUserNotFound) {
-				return nil // Silent fail for security
-			}
+	err = s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		if _, err := s.inviteRepo.RevokePendingByEmail(txCtx, invite.Email, now); err != nil {
 			return err
 		}
 
-		// Generate and cache OTP
-		otpCode, err := s.generateAndCacheOTP(txCtx, "password_reset", user.Email)
-		if err != nil {
+		if err := s.inviteRepo.Create(txCtx, invite); err != nil {
 			return err
 		}
 
-		// Queue forgot password email
-		return s.queueForgotPasswordEmail(txCtx, user, otpCode)
+		metadata := map[string]string{
+			"invite_id": invite.ID.String(),
+			"email":     invite.Email,
+		}
+		return s.recordAdminAction(txCtx, req.ActorID, nil, entity.AdminActionCreateInvite, req.Client, metadata)
 	})
 
+	// The email is queued once the invite is stored, so it never carries a token for
+	// an invite that was rolled back. Creating the invite again replaces an unsent one.
+	if err == nil {
+		err = s.queueInviteEmail(ctx, invite, inviter.FullName, inviteToken)
+	}
 	if err != nil {
 		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
-	return err
+	s.logger.WithContext(ctx).Info("Invite created by admin", "actor_id", req.ActorID, "invite_id", invite.ID)
+	return dto.InviteEntityToInviteResponse(invite, now), nil
 }
 
-func (s *UserService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
-	s.logger.WithContext(ctx).Info("Reset password", "email", req.Email)
-
-	ctx, span := s.tracer.Start(ctx, "service.ResetPassword")
+func (s *UserService) AdminListInvites(ctx context.Context, req *dto.AdminListInvitesRequest) (*dto.ListInvitesResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "service.AdminListInvites")
 	defer span.End()
 
-	if err := s.checkRateLimit(ctx, "reset_password", req.Email); err != nil {
-		return err
-	}
-
 	if err := s.validator.ValidateAndGetErrors(req); err != nil {
 		span.RecordError(err)
-		return apperror.NewValidationError(err)
+		return nil, apperror.NewValidationError(err)
 	}
 
-	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		user, err := s.getUserByEmail(txCtx, req.Email)
-		if err != nil {
-			if errors.Is(err, apperror.ErrUserNotFound) {
-				return apperror.ErrInvalidOTP
-			}
-			return err
-		}
-
-		// Get and verify OTP
-		cachedOTP, err := s.getCachedOTP(txCtx, "password_reset", req.Email)
-		if err != nil {
-			return err
-		}
-
-		if cachedOTP != req.OTP {
-			return apperror.ErrInvalidOTP
-		}
-
-		// Check if new password is different from old one
-		if password.CheckPasswordHash(req.NewPassword, user.Password) {
-			return apperror.NewAppError(apperror.ErrCodeInvalidInput, "New password must be different from the old password")
-		}
-
-		// Hash new password
-		hashedPassword, err := s.hashPassword(txCtx, req.NewPassword)
-		if err != nil {
-			return err
-		}
-
-		// Update user
-		user.Password = hashedPassword
-		user.UpdatedAt = time.Now()
-
-		if err := s.updateUser(txCtx, user); err != nil {
-			return err
-		}
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
 
-		// Clean up cache
-		return s.deleteCachedOTP(txCtx, "password_reset", req.Email)
+	res, err := s.inviteRepo.FindAll(ctx, &dto.InviteFilter{
+		Email:  req.Email,
+		Status: req.Status,
+		Filter: req.Filter,
 	})
-
 	if err != nil {
 		span.RecordError(err)
-		return err
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
-	s.logger.WithContext(ctx).Info("Password reset successfully", "email", req.Email)
-	return nil
+	metadata := map[string]string{
+		"email":  req.Email,
+		"status": req.Status,
+	}
+	if err := s.recordAdminAction(ctx, req.ActorID, nil, entity.AdminActionListInvites, req.Client, metadata); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return res, nil
 }
 
-func (s *UserService) Setup2FA(ctx context.Context, req *dto.Setup2FARequest) (*dto.Setup2FAResponse, error) {
-	s.logger.WithContext(ctx).Info("Setting up 2FA", "user_id", req.UserID)
+// AdminRevokeInvite withdraws a pending invite so its link stops working. Invites
+// that were already redeemed or revoked cannot be revoked again.
+func (s *UserService) AdminRevokeInvite(ctx context.Context, req *dto.AdminRevokeInviteRequest) error {
+	s.logger.WithContext(ctx).Info("Admin revoking invite", "actor_id", req.ActorID, "invite_id", req.InviteID)
 
-	ctx, span := s.tracer.Start(ctx, "service.Setup2FA")
+	ctx, span := s.tracer.Start(ctx, "service.AdminRevokeInvite")
 	defer span.End()
 
 	if err := s.validator.ValidateAndGetErrors(req); err != nil {
 		span.RecordError(err)
-		return nil, apperror.NewValidationError(err)
+		return apperror.NewValidationError(err)
 	}
 
-	var user *entity.User
-	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		var err error
-		user, err = s.userRepo.GetByID(txCtx, req.UserID)
-		if err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to get user", "error", err)
-			return apperror.ErrUserNotFound
-		}
-
-		if !user.IsEmailVerified() {
-			return apperror.ErrUserEmailNotVerified
-		}
-
-		if !user.IsActive() {
-			return apperror.ErrUserInactive
-		}
-
-		if user.IsTwoFactorEnabled() {
-			return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA already enabled")
-		}
+	if err := s.requirePermission(ctx, req.ActorID, authz.UsersManage); err != nil {
+		span.RecordError(err)
+		return err
+	}
 
-		key, err := s.generate2FASecret(txCtx, user.Email)
+	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
+		invite, err := s.inviteRepo.GetByID(txCtx, req.InviteID)
 		if err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to generate 2FA secret", "error", err)
-			return apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate 2FA secret")
+			return err
 		}
 
-		user.SetTwoFactorSecret(key.Secret())
-		user.EnableTwoFactor()
-
-		if err := s.userRepo.Update(txCtx, user); err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to update user", "error", err)
+		if err := s.inviteRepo.MarkRevoked(txCtx, invite.ID, time.Now()); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return apperror.ErrInvalidInvite
+			}
 			return err
 		}
 
-		return nil
+		metadata := map[string]string{
+			"invite_id": invite.ID.String(),
+			"email":     invite.Email,
+		}
+		return s.recordAdminAction(txCtx, req.ActorID, nil, entity.AdminActionRevokeInvite, req.Client, metadata)
 	})
 	if err != nil {
 		span.RecordError(err)
-		return nil, err
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
 
-	issuer := url.QueryEscape(s.cfg.AppName)
-	accountName := url.QueryEscape(user.Email)
-	secret := user.GetTwoFactorSecret()
+	s.logger.WithContext(ctx).Info("Invite revoked by admin", "actor_id", req.ActorID, "invite_id", req.InviteID)
+	return nil
+}
 
-	otpAuthURL := fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s&algorithm=SHA1&digits=6&period=30",
-		issuer, accountName, secret, issuer)
+// PurgeExpiredLoginAttempts deletes failure counters whose window has passed and
+// whose lockout, if any, is over. It is meant to be run periodically by the scheduler.
+func (s *UserService) PurgeExpiredLoginAttempts(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeExpiredLoginAttempts")
+	defer span.End()
 
-	qrCodeBase64, err := s.generateQRCode(ctx, otpAuthURL)
+	deleted, err := s.loginAttemptRepo.DeleteExpired(ctx, time.Now().Add(-config.LoginFailureWindow))
 	if err != nil {
 		span.RecordError(err)
-		return nil, err
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to purge expired login attempts", "error", err)
+		return err
 	}
 
-	s.logger.WithContext(ctx).Info("2FA setup successfully", "user_id", req.UserID)
-	return &dto.Setup2FAResponse{
-		Secret: user.GetTwoFactorSecret(),
-		QRCode: qrCodeBase64,
-	}, nil
+	s.logger.WithContext(ctx).Info("Expired login attempts purged", "deleted", deleted)
+	return nil
 }
 
-func (s *UserService) Verify2FA(ctx context.Context, req *dto.Verify2FARequest) (*dto.Verify2FAResponse, error) {
-	s.logger.WithContext(ctx).Info("Verifying 2FA code", "user_id", req.UserID)
-
-	ctx, span := s.tracer.Start(ctx, "service.Verify2FA")
+// PurgeExpiredSessions deletes sessions whose last refresh token has expired.
+// It is meant to be run periodically by the scheduler.
+func (s *UserService) PurgeExpiredSessions(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeExpiredSessions")
 	defer span.End()
 
-	if err := s.checkRateLimit(ctx, "verify_2fa", fmt.Sprintf("verify_2fa:%s", req.UserID)); err != nil {
+	deleted, err := s.sessionRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
 		span.RecordError(err)
-		return nil, err
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to purge expired sessions", "error", err)
+		return err
 	}
 
-	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+	s.logger.WithContext(ctx).Info("Expired sessions purged", "deleted", deleted)
+	return nil
+}
+
+// PurgeExpiredSecurityEvents deletes security events older than the retention period.
+func (s *UserService) PurgeExpiredSecurityEvents(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeExpiredSecurityEvents")
+	defer span.End()
+
+	deleted, err := s.securityEventRepo.DeleteExpired(ctx, time.Now().Add(-config.SecurityEventRetention))
+	if err != nil {
 		span.RecordError(err)
-		return nil, apperror.NewValidationError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to purge expired security events", "error", err)
+		return err
 	}
 
-	var user *entity.User
-	var accessToken, refreshToken *token.GenerateTokenResponse
-	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		var err error
-		user, err = s.userRepo.GetByID(txCtx, req.UserID)
-		if err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to get user", "error", err)
-			return apperror.ErrUserNotFound
-		}
+	s.logger.WithContext(ctx).Info("Expired security events purged", "deleted", deleted)
+	return nil
+}
 
-		if !user.IsEmailVerified() {
-			return apperror.ErrUserEmailNotVerified
-		}
-		if !user.IsActive() {
-			return apperror.ErrUserInactive
-		}
-		if !user.HasTwoFactorSecret() {
-			return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA not set up")
-		}
+// PurgeStaleKnownDevices forgets devices that have not been used to sign in for
+// longer than the retention period.
+func (s *UserService) PurgeStaleKnownDevices(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeStaleKnownDevices")
+	defer span.End()
 
-		if !totp.Validate(req.Code, user.GetTwoFactorSecret()) {
-			s.logger.WithContext(txCtx).Warn("Invalid 2FA code", "user_id", req.UserID)
-			return apperror.NewAppError(apperror.ErrCodeInvalidInput, "Invalid 2FA code")
-		}
+	deleted, err := s.knownDeviceRepo.DeleteNotSeenSince(ctx, time.Now().Add(-config.KnownDeviceRetention))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to purge stale known devices", "error", err)
+		return err
+	}
 
-		if !user.IsTwoFactorEnabled() {
-			user.EnableTwoFactor()
-			if err := s.userRepo.Update(txCtx, user); err != nil {
-				s.logger.WithContext(txCtx).Error("Failed to update user", "error", err)
-				return err
-			}
-		}
+	s.logger.WithContext(ctx).Info("Stale known devices purged", "deleted", deleted)
+	return nil
+}
 
-		accessToken, err = s.token.GenerateToken(user.ID, s.cfg.AuthAccessTokenExpiry)
-		if err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to generate access token", "error", err)
-			return apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate access token")
-		}
-		refreshToken, err = s.token.GenerateToken(user.ID, s.cfg.AuthRefreshTokenExpiry)
-		if err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to generate refresh token", "error", err)
-			return apperror.NewAppError(apperror.ErrCodeInternalError, "Failed to generate refresh token")
-		}
-		return nil
-	})
+// PurgeScheduledAccountDeletions deletes the accounts whose deletion grace period has
+// ended. It is meant to be run periodically by the scheduler.
+func (s *UserService) PurgeScheduledAccountDeletions(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeScheduledAccountDeletions")
+	defer span.End()
+
+	deleted, err := s.userRepo.DeleteScheduled(ctx, time.Now())
 	if err != nil {
 		span.RecordError(err)
-		return nil, err
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to delete scheduled accounts", "error", err)
+		return err
 	}
 
-	s.logger.WithContext(ctx).Info("2FA verified successfully", "user_id", req.UserID)
-	return &dto.Verify2FAResponse{
-		Verified:              true,
-		AccessToken:           accessToken.Value,
-		RefreshToken:          refreshToken.Value,
-		AccessTokenExpiresAt:  accessToken.ExpiresAt,
-		RefreshTokenExpiresAt: refreshToken.ExpiresAt,
-	}, nil
+	s.logger.WithContext(ctx).Info("Scheduled accounts deleted", "deleted", deleted)
+	return nil
 }
 
-func (s *UserService) Disable2FA(ctx context.Context, req *dto.Disable2FARequest) error {
-	s.logger.WithContext(ctx).Info("Disabling 2FA", "user_id", req.UserID)
-
-	ctx, span := s.tracer.Start(ctx, "service.Disable2FA")
+// PurgeExpiredEmailChangeRequests deletes email changes that were never confirmed.
+// It is meant to be run periodically by the scheduler.
+func (s *UserService) PurgeExpiredEmailChangeRequests(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeExpiredEmailChangeRequests")
 	defer span.End()
 
-	if err := s.validator.ValidateAndGetErrors(req); err != nil {
+	deleted, err := s.emailChangeRequestRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
 		span.RecordError(err)
-		return apperror.NewValidationError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to purge expired email change requests", "error", err)
+		return err
 	}
 
-	err := s.db.WithTransaction(ctx, func(txCtx context.Context) error {
-		user, err := s.userRepo.GetByID(txCtx, req.UserID)
-		if err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to get user", "error", err)
-			return apperror.ErrUserNotFound
-		}
+	s.logger.WithContext(ctx).Info("Expired email change requests purged", "deleted", deleted)
+	return nil
+}
 
-		if !user.IsEmailVerified() {
-			return apperror.ErrUserEmailNotVerified
-		}
-		if !user.IsActive() {
-			return apperror.ErrUserInactive
-		}
-		if !user.IsTwoFactorEnabled() {
-			return apperror.NewAppError(apperror.ErrCodeBadRequest, "2FA not enabled")
-		}
+// PurgeExpired2FAEnrollments deletes pending 2FA enrollments that were never confirmed.
+// It is meant to be run periodically by the scheduler.
+func (s *UserService) PurgeExpired2FAEnrollments(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeExpired2FAEnrollments")
+	defer span.End()
 
-		user.DisableTwoFactor()
-		if err := s.userRepo.Update(txCtx, user); err != nil {
-			s.logger.WithContext(txCtx).Error("Failed to update user", "error", err)
-			return err
-		}
-		return nil
-	})
+	deleted, err := s.twoFactorEnrollmentRepo.DeleteExpired(ctx, time.Now())
 	if err != nil {
 		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.logger.WithContext(ctx).Error("Failed to purge expired 2FA enrollments", "error", err)
 		return err
 	}
 
-	s.logger.WithContext(ctx).Info("2FA disabled successfully", "user_id", req.UserID)
+	s.logger.WithContext(ctx).Info("Expired 2FA enrollments purged", "deleted", deleted)
 	return nil
 }
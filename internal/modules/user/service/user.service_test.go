@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
+	"github.com/sammidev/goca/internal/modules/user/dto"
+	"github.com/sammidev/goca/internal/modules/user/entity"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/authz"
+	"github.com/sammidev/goca/internal/pkg/token"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+)
+
+// Repository palsu di bawah ini hanya mengimplementasikan method yang dipakai oleh
+// pengujian; method lain dari interface yang di-embed akan panic bila terpanggil.
+
+type fakeUserRepo struct {
+	UserRepository
+	users map[uuid.UUID]*entity.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return user, nil
+}
+
+type fakeLoginAttemptRepo struct {
+	LoginAttemptRepository
+	attempts map[uuid.UUID]*entity.LoginAttempt
+	err      error
+}
+
+func (r *fakeLoginAttemptRepo) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.LoginAttempt, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	attempt, ok := r.attempts[userID]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return attempt, nil
+}
+
+type fakeInviteRepo struct {
+	InviteRepository
+	invites map[string]*entity.Invite
+}
+
+func (r *fakeInviteRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invite, error) {
+	invite, ok := r.invites[tokenHash]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return invite, nil
+}
+
+type fakeRoleRepo struct {
+	RoleRepository
+	permissions map[string][]string
+}
+
+func (r *fakeRoleRepo) ListPermissions(ctx context.Context, roleName string) ([]string, error) {
+	return r.permissions[roleName], nil
+}
+
+type fakePersonalAccessTokenRepo struct {
+	PersonalAccessTokenRepository
+	tokens map[string]*entity.PersonalAccessToken
+}
+
+func (r *fakePersonalAccessTokenRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.PersonalAccessToken, error) {
+	pat, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return pat, nil
+}
+
+func (r *fakePersonalAccessTokenRepo) TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt, notBefore time.Time, ipAddress string) error {
+	return nil
+}
+
+func TestLoginBackoff(t *testing.T) {
+	Convey("Diberikan jumlah kegagalan login berturut-turut", t, func() {
+		cases := []struct {
+			failedCount int
+			expected    time.Duration
+		}{
+			{0, 0},
+			{config.LoginBackoffThreshold - 1, 0},
+			{config.LoginBackoffThreshold, config.LoginBackoffBaseDelay},
+			{config.LoginBackoffThreshold + 1, 2 * config.LoginBackoffBaseDelay},
+			{config.LoginBackoffThreshold + 2, 4 * config.LoginBackoffBaseDelay},
+			{config.LoginBackoffThreshold + 5, 32 * config.LoginBackoffBaseDelay},
+			{config.LoginBackoffThreshold + 6, config.LoginBackoffMaxDelay},
+			{1000, config.LoginBackoffMaxDelay},
+		}
+
+		Convey("Maka jeda berlipat dua setelah ambang batas dan tidak melebihi batas maksimum", func() {
+			for _, c := range cases {
+				So(loginBackoff(c.failedCount), ShouldEqual, c.expected)
+			}
+		})
+	})
+}
+
+func TestCheckLoginAllowed(t *testing.T) {
+	Convey("Diberikan riwayat kegagalan login user", t, func() {
+		now := time.Now()
+		past := now.Add(-time.Minute)
+		future := now.Add(time.Minute)
+
+		cases := []struct {
+			name      string
+			attempt   *entity.LoginAttempt
+			repoErr   error
+			code      apperror.ErrorCode
+			waitAtMax time.Duration
+		}{
+			{name: "tanpa riwayat kegagalan"},
+			{name: "kegagalan di bawah ambang batas", attempt: &entity.LoginAttempt{FailedCount: config.LoginBackoffThreshold - 1, LastFailedAt: now}},
+			{name: "masih dalam jeda backoff", attempt: &entity.LoginAttempt{FailedCount: config.LoginBackoffThreshold + 1, LastFailedAt: now}, code: apperror.ErrCodeLoginThrottled, waitAtMax: 2 * config.LoginBackoffBaseDelay},
+			{name: "jeda backoff sudah lewat", attempt: &entity.LoginAttempt{FailedCount: config.LoginBackoffThreshold + 1, LastFailedAt: past}},
+			{name: "akun masih terkunci", attempt: &entity.LoginAttempt{FailedCount: config.LoginBackoffThreshold, LastFailedAt: past, LockedUntil: &future}, code: apperror.ErrCodeAccountLocked, waitAtMax: time.Minute},
+			{name: "kunci akun sudah kedaluwarsa", attempt: &entity.LoginAttempt{FailedCount: config.LoginBackoffThreshold, LastFailedAt: past, LockedUntil: &past}},
+			{name: "repository gagal", repoErr: errors.New("database down")},
+		}
+
+		for _, c := range cases {
+			Convey("Ketika "+c.name, func() {
+				userID := uuid.New()
+				repo := &fakeLoginAttemptRepo{attempts: map[uuid.UUID]*entity.LoginAttempt{}, err: c.repoErr}
+				if c.attempt != nil {
+					repo.attempts[userID] = c.attempt
+				}
+				s := &UserService{tracer: otel.Tracer("user_service_test"), loginAttemptRepo: repo}
+
+				err := s.checkLoginAllowed(context.Background(), userID)
+
+				switch {
+				case c.repoErr != nil:
+					Convey("Maka error repository diteruskan", func() {
+						So(err, ShouldEqual, c.repoErr)
+					})
+				case c.code == "":
+					Convey("Maka login diizinkan", func() {
+						So(err, ShouldBeNil)
+					})
+				default:
+					Convey("Maka login ditolak beserta sisa waktu tunggu", func() {
+						var appErr *apperror.AppError
+						So(errors.As(err, &appErr), ShouldBeTrue)
+						So(appErr.Code, ShouldEqual, c.code)
+						So(appErr.RetryAfter, ShouldBeGreaterThan, 0)
+						So(appErr.RetryAfter, ShouldBeLessThanOrEqualTo, c.waitAtMax)
+					})
+				}
+			})
+		}
+	})
+}
+
+func TestIsEmailDomainAllowed(t *testing.T) {
+	Convey("Diberikan daftar domain yang diizinkan", t, func() {
+		domains := []string{"example.com", "corp.example.org"}
+
+		cases := []struct {
+			email    string
+			expected bool
+		}{
+			{"sammi@example.com", true},
+			{"Sammi@EXAMPLE.COM", true},
+			{"sammi@corp.example.org", true},
+			{"sammi@mail.example.com", false},
+			{"sammi@example.org", false},
+			{"sammi@example.com.evil.com", false},
+			{"sammi\"@example.com\"@evil.com", false},
+			{"example.com", false},
+			{"", false},
+		}
+
+		Convey("Maka hanya domain yang terdaftar persis yang diterima", func() {
+			for _, c := range cases {
+				So(isEmailDomainAllowed(c.email, domains), ShouldEqual, c.expected)
+			}
+		})
+
+		Convey("Maka daftar kosong menolak semua domain", func() {
+			So(isEmailDomainAllowed("sammi@example.com", nil), ShouldBeFalse)
+		})
+	})
+}
+
+func TestCheckRegistrationAllowed(t *testing.T) {
+	Convey("Diberikan mode registrasi dan undangan", t, func() {
+		now := time.Now()
+		inviteFor := func(email string, mutate func(*entity.Invite)) *entity.Invite {
+			invite := &entity.Invite{ID: uuid.New(), Email: email, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+			if mutate != nil {
+				mutate(invite)
+			}
+			return invite
+		}
+
+		cases := []struct {
+			name     string
+			mode     string
+			email    string
+			invite   *entity.Invite
+			token    string
+			expected error
+			redeems  bool
+		}{
+			{name: "mode open tanpa undangan", mode: config.RegistrationModeOpen, email: "sammi@other.com"},
+			{name: "mode open dengan undangan", mode: config.RegistrationModeOpen, email: "sammi@other.com", invite: inviteFor("sammi@other.com", nil), redeems: true},
+			{name: "mode closed dengan undangan", mode: config.RegistrationModeClosed, email: "sammi@example.com", invite: inviteFor("sammi@example.com", nil), expected: apperror.ErrRegistrationClosed},
+			{name: "mode invite_only tanpa undangan", mode: config.RegistrationModeInviteOnly, email: "sammi@example.com", expected: apperror.ErrInviteRequired},
+			{name: "mode invite_only dengan undangan", mode: config.RegistrationModeInviteOnly, email: "sammi@example.com", invite: inviteFor("SAMMI@example.com", nil), redeems: true},
+			{name: "mode domain_allowlist dengan domain terdaftar", mode: config.RegistrationModeDomainAllowlist, email: "sammi@example.com"},
+			{name: "mode domain_allowlist dengan domain lain", mode: config.RegistrationModeDomainAllowlist, email: "sammi@other.com", expected: apperror.ErrEmailDomainNotAllowed},
+			{name: "mode domain_allowlist dengan undangan untuk domain lain", mode: config.RegistrationModeDomainAllowlist, email: "sammi@other.com", invite: inviteFor("sammi@other.com", nil), redeems: true},
+			{name: "undangan untuk alamat lain", mode: config.RegistrationModeInviteOnly, email: "sammi@example.com", invite: inviteFor("other@example.com", nil), expected: apperror.ErrInvalidInvite},
+			{name: "undangan tidak dikenal", mode: config.RegistrationModeOpen, email: "sammi@example.com", token: "goca_inv_unknown", expected: apperror.ErrInvalidInvite},
+			{name: "undangan sudah dipakai", mode: config.RegistrationModeInviteOnly, email: "sammi@example.com", invite: inviteFor("sammi@example.com", func(i *entity.Invite) { i.RedeemedAt = &now }), expected: apperror.ErrInvalidInvite},
+			{name: "undangan sudah dicabut", mode: config.RegistrationModeInviteOnly, email: "sammi@example.com", invite: inviteFor("sammi@example.com", func(i *entity.Invite) { i.RevokedAt = &now }), expected: apperror.ErrInvalidInvite},
+			{name: "undangan sudah kedaluwarsa", mode: config.RegistrationModeInviteOnly, email: "sammi@example.com", invite: inviteFor("sammi@example.com", func(i *entity.Invite) { i.ExpiresAt = now.Add(-time.Minute) }), expected: apperror.ErrInviteExpired},
+		}
+
+		for _, c := range cases {
+			Convey("Ketika "+c.name, func() {
+				repo := &fakeInviteRepo{invites: map[string]*entity.Invite{}}
+				req := &dto.RegisterRequest{Email: c.email, InviteToken: c.token}
+				if c.invite != nil {
+					value, hash, err := token.GenerateInviteToken()
+					So(err, ShouldBeNil)
+					c.invite.TokenHash = hash
+					repo.invites[hash] = c.invite
+					req.InviteToken = value
+				}
+
+				s := &UserService{
+					cfg:        &config.Config{RegistrationMode: c.mode, RegistrationAllowedDomains: []string{"example.com"}},
+					inviteRepo: repo,
+				}
+
+				invite, err := s.checkRegistrationAllowed(context.Background(), req)
+
+				if c.expected != nil {
+					Convey("Maka registrasi ditolak", func() {
+						So(errors.Is(err, c.expected), ShouldBeTrue)
+						So(invite, ShouldBeNil)
+					})
+				} else {
+					Convey("Maka registrasi diizinkan", func() {
+						So(err, ShouldBeNil)
+						if c.redeems {
+							So(invite, ShouldEqual, c.invite)
+						} else {
+							So(invite, ShouldBeNil)
+						}
+					})
+				}
+			})
+		}
+	})
+}
+
+func TestAuthenticatePersonalAccessToken(t *testing.T) {
+	Convey("Diberikan personal access token milik user aktif", t, func() {
+		verifiedAt := time.Now()
+		user := &entity.User{ID: uuid.New(), Status: entity.UserStatusActive, Role: entity.UserRoleUser, EmailVerifiedAt: &verifiedAt}
+
+		value, hash, _, err := token.GeneratePersonalAccessToken()
+		So(err, ShouldBeNil)
+		pat := &entity.PersonalAccessToken{ID: uuid.New(), UserID: user.ID, TokenHash: hash, CreatedAt: time.Now()}
+
+		s := &UserService{
+			tracer:                  otel.Tracer("user_service_test"),
+			userRepo:                &fakeUserRepo{users: map[uuid.UUID]*entity.User{user.ID: user}},
+			personalAccessTokenRepo: &fakePersonalAccessTokenRepo{tokens: map[string]*entity.PersonalAccessToken{hash: pat}},
+			roleRepo: &fakeRoleRepo{permissions: map[string][]string{
+				string(entity.UserRoleUser):  {authz.ScopeNotesRead, authz.ScopeProfileRead},
+				string(entity.UserRoleAdmin): {authz.ScopeNotesRead, authz.ScopeProfileRead, authz.UsersRead},
+			}},
+		}
+
+		Convey("Ketika scope token berada di luar permission role pemiliknya", func() {
+			pat.Scopes = []string{authz.ScopeNotesRead, authz.UsersRead}
+			payload, err := s.AuthenticatePersonalAccessToken(context.Background(), value, "127.0.0.1")
+
+			Convey("Maka scope tetap dibawa tetapi permission hanya yang diberikan role", func() {
+				So(err, ShouldBeNil)
+				So(payload.Scopes, ShouldResemble, []string{authz.ScopeNotesRead, authz.UsersRead})
+				So(payload.Permissions, ShouldResemble, []string{authz.ScopeNotesRead})
+				So(payload.HasPermission(authz.UsersRead), ShouldBeFalse)
+			})
+		})
+
+		Convey("Ketika role pemiliknya memberikan semua scope token", func() {
+			user.Role = entity.UserRoleAdmin
+			pat.Scopes = []string{authz.ScopeNotesRead, authz.UsersRead}
+			payload, err := s.AuthenticatePersonalAccessToken(context.Background(), value, "127.0.0.1")
+
+			Convey("Maka semua scope menjadi permission", func() {
+				So(err, ShouldBeNil)
+				So(payload.Permissions, ShouldResemble, []string{authz.ScopeNotesRead, authz.UsersRead})
+			})
+		})
+
+		Convey("Ketika token sudah dicabut", func() {
+			revokedAt := time.Now()
+			pat.RevokedAt = &revokedAt
+			_, err := s.AuthenticatePersonalAccessToken(context.Background(), value, "127.0.0.1")
+
+			Convey("Maka token ditolak", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("Ketika pemilik token tidak lagi aktif", func() {
+			user.Status = entity.UserStatusSuspended
+			_, err := s.AuthenticatePersonalAccessToken(context.Background(), value, "127.0.0.1")
+
+			Convey("Maka token ditolak", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
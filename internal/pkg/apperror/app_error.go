@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/sammidev/goca/internal/pkg/validator"
 )
@@ -34,6 +35,19 @@ const (
 	ErrCodeInvalidOTP            ErrorCode = "INVALID_OTP"
 	ErrCodeOTPExpired            ErrorCode = "OTP_EXPIRED"
 	ErrCodeInvalidToken          ErrorCode = "INVALID_TOKEN"
+	ErrCodeRefreshTokenReused    ErrorCode = "REFRESH_TOKEN_REUSED"
+	ErrCodeReauthRequired        ErrorCode = "REAUTHENTICATION_REQUIRED"
+	ErrCodeAccountLocked         ErrorCode = "ACCOUNT_LOCKED"
+	ErrCodeLoginThrottled        ErrorCode = "LOGIN_THROTTLED"
+	ErrCodePasswordReused        ErrorCode = "PASSWORD_REUSED"
+	ErrCodePasswordExpired       ErrorCode = "PASSWORD_EXPIRED"
+	ErrCodeOTPAttemptsExceeded   ErrorCode = "OTP_ATTEMPTS_EXCEEDED"
+	ErrCodeOTPResendCooldown     ErrorCode = "OTP_RESEND_COOLDOWN"
+	ErrCodeRegistrationClosed    ErrorCode = "REGISTRATION_CLOSED"
+	ErrCodeInviteRequired        ErrorCode = "INVITE_REQUIRED"
+	ErrCodeEmailDomainNotAllowed ErrorCode = "EMAIL_DOMAIN_NOT_ALLOWED"
+	ErrCodeInvalidInvite         ErrorCode = "INVALID_INVITE"
+	ErrCodeInviteExpired         ErrorCode = "INVITE_EXPIRED"
 )
 
 func (e ErrorCode) String() string {
@@ -46,6 +60,8 @@ type AppError struct {
 	Message string    `json:"message"`
 	Details string    `json:"details,omitempty"`
 	Cause   error     `json:"-"`
+	// RetryAfter tells the client how long to wait before trying again, if known.
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error implements the error interfaces
@@ -61,6 +77,13 @@ func (e *AppError) Unwrap() error {
 	return e.Cause
 }
 
+// WithRetryAfter returns a copy of the error that carries a retry hint
+func (e *AppError) WithRetryAfter(retryAfter time.Duration) *AppError {
+	clone := *e
+	clone.RetryAfter = retryAfter
+	return &clone
+}
+
 // Is checks if the error matches the target
 func (e *AppError) Is(target error) bool {
 	var appErr *AppError
@@ -75,14 +98,18 @@ func (e *AppError) HTTPStatusCode() int {
 	switch e.Code {
 	case ErrCodeNotFound, ErrCodeUserNotFound:
 		return http.StatusNotFound
-	case ErrCodeInvalidInput, ErrCodeValidationFailed, ErrCodeInvalidOTP:
+	case ErrCodeInvalidInput, ErrCodeValidationFailed, ErrCodeInvalidOTP, ErrCodePasswordReused, ErrCodeInvalidInvite, ErrCodeInviteExpired:
 		return http.StatusBadRequest
-	case ErrCodeUnauthorized, ErrCodeUserIncorrectPassword, ErrCodeUserInactive, ErrCodeUserEmailNotVerified, ErrCodeInvalidToken:
+	case ErrCodeUnauthorized, ErrCodeUserIncorrectPassword, ErrCodeUserInactive, ErrCodeUserEmailNotVerified, ErrCodeInvalidToken, ErrCodeRefreshTokenReused:
 		return http.StatusUnauthorized
-	case ErrCodeForbidden:
+	case ErrCodeForbidden, ErrCodeReauthRequired, ErrCodePasswordExpired, ErrCodeRegistrationClosed, ErrCodeInviteRequired, ErrCodeEmailDomainNotAllowed:
 		return http.StatusForbidden
 	case ErrCodeConflict, ErrCodeUserAlreadyExists:
 		return http.StatusConflict
+	case ErrCodeAccountLocked:
+		return http.StatusLocked
+	case ErrCodeTooManyRequests, ErrCodeLoginThrottled, ErrCodeOTPAttemptsExceeded, ErrCodeOTPResendCooldown:
+		return http.StatusTooManyRequests
 	case ErrCodeExternalService:
 		return http.StatusServiceUnavailable
 	case ErrCodeDatabaseError, ErrCodeInternalError, ErrCodeOTPExpired:
@@ -152,6 +179,19 @@ var (
 	ErrInvalidOTP            = NewAppError(ErrCodeInvalidOTP, "Invalid OTP")
 	ErrOTPExpired            = NewAppError(ErrCodeOTPExpired, "OTP has expired")
 	ErrInvalidToken          = NewAppError(ErrCodeInvalidToken, "Invalid token")
+	ErrRefreshTokenReused    = NewAppError(ErrCodeRefreshTokenReused, "Refresh token has already been used, all sessions from this login were revoked")
+	ErrReauthRequired        = NewAppError(ErrCodeReauthRequired, "Recent re-authentication is required for this action")
+	ErrAccountLocked         = NewAppError(ErrCodeAccountLocked, "Account is temporarily locked after too many failed login attempts")
+	ErrLoginThrottled        = NewAppError(ErrCodeLoginThrottled, "Too many failed login attempts, please wait before trying again")
+	ErrPasswordReused        = NewAppError(ErrCodePasswordReused, "New password must not match the current or a recently used password")
+	ErrPasswordExpired       = NewAppError(ErrCodePasswordExpired, "Password has expired, reset it to sign in again")
+	ErrOTPAttemptsExceeded   = NewAppError(ErrCodeOTPAttemptsExceeded, "Too many invalid codes, please request a new one")
+	ErrOTPResendCooldown     = NewAppError(ErrCodeOTPResendCooldown, "A code was sent recently, please wait before requesting another one")
+	ErrRegistrationClosed    = NewAppError(ErrCodeRegistrationClosed, "Registration is closed")
+	ErrInviteRequired        = NewAppError(ErrCodeInviteRequired, "Registration requires an invite")
+	ErrEmailDomainNotAllowed = NewAppError(ErrCodeEmailDomainNotAllowed, "Registration is not open to this email domain")
+	ErrInvalidInvite         = NewAppError(ErrCodeInvalidInvite, "Invite is invalid, already used or for another email address")
+	ErrInviteExpired         = NewAppError(ErrCodeInviteExpired, "Invite has expired")
 )
 
 // IsAppError checks if an error is an modules error
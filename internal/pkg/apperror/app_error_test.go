@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/sammidev/goca/internal/pkg/validator"
 	. "github.com/smartystreets/goconvey/convey"
@@ -69,6 +70,20 @@ func TestAppErrors(t *testing.T) {
 				{"Conflict", NewAppError(ErrCodeConflict, ""), http.StatusConflict},
 				{"InternalError", NewAppError(ErrCodeInternalError, ""), http.StatusInternalServerError},
 				{"UserNotFound", NewAppError(ErrCodeUserNotFound, ""), http.StatusNotFound},
+				{"RefreshTokenReused", NewAppError(ErrCodeRefreshTokenReused, ""), http.StatusUnauthorized},
+				{"ReauthRequired", NewAppError(ErrCodeReauthRequired, ""), http.StatusForbidden},
+				{"TooManyRequests", NewAppError(ErrCodeTooManyRequests, ""), http.StatusTooManyRequests},
+				{"AccountLocked", NewAppError(ErrCodeAccountLocked, ""), http.StatusLocked},
+				{"LoginThrottled", NewAppError(ErrCodeLoginThrottled, ""), http.StatusTooManyRequests},
+				{"OTPAttemptsExceeded", NewAppError(ErrCodeOTPAttemptsExceeded, ""), http.StatusTooManyRequests},
+				{"OTPResendCooldown", NewAppError(ErrCodeOTPResendCooldown, ""), http.StatusTooManyRequests},
+				{"PasswordReused", NewAppError(ErrCodePasswordReused, ""), http.StatusBadRequest},
+				{"PasswordExpired", NewAppError(ErrCodePasswordExpired, ""), http.StatusForbidden},
+				{"RegistrationClosed", NewAppError(ErrCodeRegistrationClosed, ""), http.StatusForbidden},
+				{"InviteRequired", NewAppError(ErrCodeInviteRequired, ""), http.StatusForbidden},
+				{"EmailDomainNotAllowed", NewAppError(ErrCodeEmailDomainNotAllowed, ""), http.StatusForbidden},
+				{"InvalidInvite", NewAppError(ErrCodeInvalidInvite, ""), http.StatusBadRequest},
+				{"InviteExpired", NewAppError(ErrCodeInviteExpired, ""), http.StatusBadRequest},
 				{"DefaultInternalError", NewAppError(ErrorCode("UNKNOWN_CODE"), ""), http.StatusInternalServerError},
 			}
 
@@ -113,6 +128,15 @@ func TestAppErrors(t *testing.T) {
 				})
 			})
 
+			Convey("WithRetryAfter", func() {
+				Convey("Seharusnya mengembalikan salinan tanpa mengubah error asli", func() {
+					locked := ErrAccountLocked.WithRetryAfter(time.Minute)
+					So(locked.RetryAfter, ShouldEqual, time.Minute)
+					So(ErrAccountLocked.RetryAfter, ShouldEqual, 0)
+					So(errors.Is(locked, ErrAccountLocked), ShouldBeTrue)
+				})
+			})
+
 			Convey("WrapError", func() {
 				cause := errors.New("original error")
 
@@ -8,6 +8,15 @@ import (
 var EmbeddedFiles embed.FS
 
 const (
-	EmailVerificationTemplatePath   = "emails/email-verification.tmpl"
-	EmailForgotPasswordTemplatePath = "emails/email-forgot-password.tmpl"
+	EmailVerificationTemplatePath             = "emails/email-verification.tmpl"
+	EmailForgotPasswordTemplatePath           = "emails/email-forgot-password.tmpl"
+	EmailAccountLockedTemplatePath            = "emails/email-account-locked.tmpl"
+	EmailPasswordChangedTemplatePath          = "emails/email-password-changed.tmpl"
+	EmailChangeVerificationTemplatePath       = "emails/email-change-verification.tmpl"
+	EmailChangeNoticeTemplatePath             = "emails/email-change-notice.tmpl"
+	EmailAccountDeletionScheduledTemplatePath = "emails/email-account-deletion-scheduled.tmpl"
+	EmailNewLoginAlertTemplatePath            = "emails/email-new-login-alert.tmpl"
+	EmailVerificationLinkTemplatePath         = "emails/email-verification-link.tmpl"
+	EmailPasswordResetLinkTemplatePath        = "emails/email-password-reset-link.tmpl"
+	EmailInviteTemplatePath                   = "emails/email-invite.tmpl"
 )
@@ -30,6 +30,66 @@ func TestEmbeddedFiles(t *testing.T) {
 			})
 		})
 
+		Convey("When checking for account locked template", func() {
+			Convey("Then the file should exist and be readable", func() {
+				data, err := EmbeddedFiles.ReadFile(EmailAccountLockedTemplatePath)
+				So(err, ShouldBeNil)
+				So(len(data), ShouldBeGreaterThan, 0)
+			})
+		})
+
+		Convey("When checking for password changed template", func() {
+			Convey("Then the file should exist and be readable", func() {
+				data, err := EmbeddedFiles.ReadFile(EmailPasswordChangedTemplatePath)
+				So(err, ShouldBeNil)
+				So(len(data), ShouldBeGreaterThan, 0)
+			})
+		})
+
+		Convey("When checking for email change templates", func() {
+			Convey("Then the files should exist and be readable", func() {
+				for _, path := range []string{EmailChangeVerificationTemplatePath, EmailChangeNoticeTemplatePath} {
+					data, err := EmbeddedFiles.ReadFile(path)
+					So(err, ShouldBeNil)
+					So(len(data), ShouldBeGreaterThan, 0)
+				}
+			})
+		})
+
+		Convey("When checking for account deletion scheduled template", func() {
+			Convey("Then the file should exist and be readable", func() {
+				data, err := EmbeddedFiles.ReadFile(EmailAccountDeletionScheduledTemplatePath)
+				So(err, ShouldBeNil)
+				So(len(data), ShouldBeGreaterThan, 0)
+			})
+		})
+
+		Convey("When checking for new login alert template", func() {
+			Convey("Then the file should exist and be readable", func() {
+				data, err := EmbeddedFiles.ReadFile(EmailNewLoginAlertTemplatePath)
+				So(err, ShouldBeNil)
+				So(len(data), ShouldBeGreaterThan, 0)
+			})
+		})
+
+		Convey("When checking for link-based verification and password reset templates", func() {
+			Convey("Then the files should exist and be readable", func() {
+				for _, path := range []string{EmailVerificationLinkTemplatePath, EmailPasswordResetLinkTemplatePath} {
+					data, err := EmbeddedFiles.ReadFile(path)
+					So(err, ShouldBeNil)
+					So(len(data), ShouldBeGreaterThan, 0)
+				}
+			})
+		})
+
+		Convey("When checking for invite template", func() {
+			Convey("Then the file should exist and be readable", func() {
+				data, err := EmbeddedFiles.ReadFile(EmailInviteTemplatePath)
+				So(err, ShouldBeNil)
+				So(len(data), ShouldBeGreaterThan, 0)
+			})
+		})
+
 		Convey("When checking for non-existent file", func() {
 			Convey("Then it should return an error", func() {
 				_, err := EmbeddedFiles.ReadFile("emails/non-existent.tmpl")
@@ -0,0 +1,66 @@
+// Package authz holds the permission names and the helpers services use to check
+// them. Permissions are named "resource:action" or "resource:action:any", where
+// the ":any" variant lifts the usual restriction to resources the user owns.
+package authz
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NotesReadAny   = "notes:read:any"
+	NotesUpdateAny = "notes:update:any"
+	NotesDeleteAny = "notes:delete:any"
+	UsersRead      = "users:read"
+	UsersManage    = "users:manage"
+	RolesManage    = "roles:manage"
+
+	SecurityEventsRead = "security_events:read"
+)
+
+// Scopes that limit a personal access token to the user's own data. A token may
+// also be given permissions of its owner's role, e.g. users:read for an admin script.
+const (
+	ScopeNotesRead    = "notes:read"
+	ScopeNotesWrite   = "notes:write"
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+)
+
+// Scopes lists every scope that is not a role permission.
+var Scopes = []string{ScopeNotesRead, ScopeNotesWrite, ScopeProfileRead, ScopeProfileWrite}
+
+// IsScope reports whether name is one of Scopes.
+func IsScope(name string) bool {
+	return slices.Contains(Scopes, name)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the permissions of the current user.
+func NewContext(ctx context.Context, permissions []string) context.Context {
+	return context.WithValue(ctx, contextKey{}, permissions)
+}
+
+// FromContext returns the permissions stored by NewContext, or nil when there are none.
+func FromContext(ctx context.Context) []string {
+	permissions, _ := ctx.Value(contextKey{}).([]string)
+	return permissions
+}
+
+// HasPermission reports whether the current user holds the permission.
+func HasPermission(ctx context.Context, permission string) bool {
+	return slices.Contains(FromContext(ctx), permission)
+}
+
+// CanAccess reports whether the actor may act on a resource: either they own it,
+// or they hold the permission that covers resources of any owner.
+func CanAccess(ctx context.Context, actorID, ownerID uuid.UUID, anyPermission string) bool {
+	if actorID != uuid.Nil && actorID == ownerID {
+		return true
+	}
+	return HasPermission(ctx, anyPermission)
+}
@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAuthz(t *testing.T) {
+	Convey("Given a context", t, func() {
+		ownerID := uuid.New()
+		otherID := uuid.New()
+
+		Convey("Without permissions", func() {
+			ctx := context.Background()
+
+			So(FromContext(ctx), ShouldBeNil)
+			So(HasPermission(ctx, NotesDeleteAny), ShouldBeFalse)
+
+			Convey("The owner can access their own resource", func() {
+				So(CanAccess(ctx, ownerID, ownerID, NotesDeleteAny), ShouldBeTrue)
+			})
+
+			Convey("Another user cannot", func() {
+				So(CanAccess(ctx, otherID, ownerID, NotesDeleteAny), ShouldBeFalse)
+			})
+
+			Convey("A nil actor never counts as the owner", func() {
+				So(CanAccess(ctx, uuid.Nil, uuid.Nil, NotesDeleteAny), ShouldBeFalse)
+			})
+		})
+
+		Convey("With permissions", func() {
+			ctx := NewContext(context.Background(), []string{NotesDeleteAny, UsersRead})
+
+			So(FromContext(ctx), ShouldResemble, []string{NotesDeleteAny, UsersRead})
+			So(HasPermission(ctx, UsersRead), ShouldBeTrue)
+			So(HasPermission(ctx, UsersManage), ShouldBeFalse)
+
+			Convey("Another user can access the resource through the :any permission", func() {
+				So(CanAccess(ctx, otherID, ownerID, NotesDeleteAny), ShouldBeTrue)
+			})
+
+			Convey("But only for the action that is granted", func() {
+				So(CanAccess(ctx, otherID, ownerID, NotesUpdateAny), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestIsScope(t *testing.T) {
+	Convey("Given a scope name", t, func() {
+		Convey("Known scopes are accepted", func() {
+			for _, scope := range Scopes {
+				So(IsScope(scope), ShouldBeTrue)
+			}
+		})
+
+		Convey("Permissions and unknown names are not scopes", func() {
+			So(IsScope(UsersRead), ShouldBeFalse)
+			So(IsScope("notes:*"), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,39 @@
+// Package breach screens passwords against a corpus of known breached passwords kept
+// on local disk, so the check works without network access.
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+)
+
+// Checker reports whether a password appears in a breached-password corpus.
+type Checker interface {
+	IsBreached(password string) (bool, error)
+
+	// Close releases the underlying corpus.
+	Close() error
+}
+
+// New opens the corpus at path. An empty path disables screening and returns a
+// Checker that never reports a password as breached.
+func New(path string) (Checker, error) {
+	if path == "" {
+		return noopChecker{}, nil
+	}
+	return NewHashFileChecker(path)
+}
+
+type noopChecker struct{}
+
+func (noopChecker) IsBreached(string) (bool, error) { return false, nil }
+
+func (noopChecker) Close() error { return nil }
+
+// HashPassword returns the uppercase hex SHA-1 of password, the form breached
+// password corpora such as Have I Been Pwned are published in.
+func HashPassword(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
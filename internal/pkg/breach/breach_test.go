@@ -0,0 +1,89 @@
+package breach
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// writeCorpus writes the hashes of passwords, padded with filler hashes so the
+// binary search has to seek, sorted the way the published corpora are.
+func writeCorpus(t *testing.T, passwords []string, lineEnding string) string {
+	lines := make([]string, 0, len(passwords)+5000)
+	for _, password := range passwords {
+		lines = append(lines, HashPassword(password)+":42")
+	}
+	for i := range 5000 {
+		lines = append(lines, fmt.Sprintf("%s:%d", HashPassword(fmt.Sprintf("filler-%d", i)), i+1))
+	}
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, lineEnding)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHashFileChecker(t *testing.T) {
+	Convey("Diberikan korpus password yang bocor", t, func() {
+		breached := []string{"password", "123456", "qwerty", "P@ssw0rd"}
+
+		for _, lineEnding := range []string{"\n", "\r\n"} {
+			Convey(fmt.Sprintf("Ketika baris diakhiri dengan %q", lineEnding), func() {
+				checker, err := NewHashFileChecker(writeCorpus(t, breached, lineEnding))
+				So(err, ShouldBeNil)
+				defer checker.Close()
+
+				Convey("Maka password yang ada di korpus terdeteksi", func() {
+					for _, password := range breached {
+						found, err := checker.IsBreached(password)
+						So(err, ShouldBeNil)
+						So(found, ShouldBeTrue)
+					}
+				})
+
+				Convey("Maka baris pertama dan terakhir korpus juga terdeteksi", func() {
+					for _, password := range []string{"filler-0", "filler-4999"} {
+						found, err := checker.IsBreached(password)
+						So(err, ShouldBeNil)
+						So(found, ShouldBeTrue)
+					}
+				})
+
+				Convey("Maka password yang tidak ada di korpus lolos", func() {
+					found, err := checker.IsBreached("correct horse battery staple 2024!")
+					So(err, ShouldBeNil)
+					So(found, ShouldBeFalse)
+				})
+			})
+		}
+
+		Convey("Ketika file korpus tidak ada", func() {
+			_, err := New(filepath.Join(t.TempDir(), "missing.txt"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Ketika path korpus kosong", func() {
+			checker, err := New("")
+			So(err, ShouldBeNil)
+
+			found, err := checker.IsBreached("password")
+			So(err, ShouldBeNil)
+			So(found, ShouldBeFalse)
+		})
+	})
+}
+
+func TestHashPassword(t *testing.T) {
+	Convey("Diberikan sebuah password", t, func() {
+		Convey("Maka hash-nya adalah SHA-1 heksadesimal huruf besar", func() {
+			So(HashPassword("password"), ShouldEqual, "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8")
+		})
+	})
+}
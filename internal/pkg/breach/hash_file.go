@@ -0,0 +1,114 @@
+package breach
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// scanWindow is the size of the file region below which the binary search stops
+// seeking and reads lines sequentially.
+const scanWindow = 4 * 1024
+
+// HashFileChecker looks passwords up in a file of SHA-1 hashes sorted in ascending
+// order, one per line and optionally followed by ":<count>", like the "ordered by
+// hash" download of Have I Been Pwned. The file is binary searched on disk, so
+// even the full corpus is never loaded into memory.
+type HashFileChecker struct {
+	file *os.File
+	size int64
+}
+
+func NewHashFileChecker(path string) (*HashFileChecker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password corpus: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat breached password corpus: %w", err)
+	}
+
+	return &HashFileChecker{file: file, size: info.Size()}, nil
+}
+
+func (c *HashFileChecker) IsBreached(password string) (bool, error) {
+	target := HashPassword(password)
+
+	// Narrow down to a region whose first line sorts before the target and whose
+	// end is at or past it. Every read goes through ReadAt, so lookups can run concurrently.
+	lo, hi := int64(0), c.size
+	for hi-lo > scanWindow {
+		mid := lo + (hi-lo)/2
+		hash, err := c.hashAt(mid)
+		if err != nil {
+			return false, err
+		}
+		if hash == "" || hash >= target {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	reader, err := c.linesFrom(lo)
+	if err != nil {
+		return false, err
+	}
+	for {
+		hash, err := readHash(reader)
+		if err != nil {
+			return false, err
+		}
+		if hash == "" || hash > target {
+			return false, nil
+		}
+		if hash == target {
+			return true, nil
+		}
+	}
+}
+
+func (c *HashFileChecker) Close() error {
+	return c.file.Close()
+}
+
+// hashAt returns the hash on the first line starting at or after offset, or an
+// empty string when there is none.
+func (c *HashFileChecker) hashAt(offset int64) (string, error) {
+	reader, err := c.linesFrom(offset)
+	if err != nil {
+		return "", err
+	}
+	return readHash(reader)
+}
+
+// linesFrom returns a reader positioned at the first line starting at or after offset.
+func (c *HashFileChecker) linesFrom(offset int64) (*bufio.Reader, error) {
+	if offset == 0 {
+		return bufio.NewReader(io.NewSectionReader(c.file, 0, c.size)), nil
+	}
+
+	// Start one byte early so a line beginning exactly at offset is not skipped
+	reader := bufio.NewReader(io.NewSectionReader(c.file, offset-1, c.size-offset+1))
+	if _, err := reader.ReadString('\n'); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read breached password corpus: %w", err)
+	}
+	return reader, nil
+}
+
+// readHash reads the next line and returns its hash in uppercase, or an empty
+// string at the end of the file.
+func readHash(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read breached password corpus: %w", err)
+	}
+
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash), nil
+}
@@ -8,6 +8,15 @@ import (
 type Cache interface {
 	Set(ctx context.Context, key string, value any, exp time.Duration) error
 	Get(ctx context.Context, key string) (any, error)
+	// SetNX stores value only when key does not exist yet and reports whether it did.
+	SetNX(ctx context.Context, key string, value any, exp time.Duration) (bool, error)
 	Delete(ctx context.Context, key string) error
+	// GetDelete atomically returns the value at key and deletes it, so only one of
+	// several concurrent callers receives the value.
+	GetDelete(ctx context.Context, key string) (any, error)
+	// Increment atomically increments the counter at key and returns the new value.
+	// The expiration is applied in the same atomic step whenever the counter has none,
+	// so a counter can never be left without one.
+	Increment(ctx context.Context, key string, exp time.Duration) (int64, error)
 	Close() error
 }
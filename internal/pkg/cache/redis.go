@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,16 @@ import (
 	"github.com/sammidev/goca/internal/config"
 )
 
+// incrementScript increments the counter and sets its expiration when it has none
+// in one atomic step.
+var incrementScript = redis.NewScript(`
+local value = redis.call("INCR", KEYS[1])
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return value
+`)
+
 type RedisClient struct {
 	*redis.Client
 }
@@ -49,6 +60,23 @@ func (r *RedisClient) Get(ctx context.Context, key string) (interface{}, error)
 	return r.Client.Get(ctx, key).Result()
 }
 
+func (r *RedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (r *RedisClient) Delete(ctx context.Context, key string) error {
 	return r.Client.Del(ctx, key).Err()
 }
+
+func (r *RedisClient) GetDelete(ctx context.Context, key string) (interface{}, error) {
+	return r.Client.GetDel(ctx, key).Result()
+}
+
+func (r *RedisClient) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	return incrementScript.Run(ctx, r.Client, []string{key}, expiration.Milliseconds()).Int64()
+}
+
+// IsNotFound reports whether err means the requested key does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, redis.Nil)
+}
@@ -55,6 +55,24 @@ func TestRedisClient(t *testing.T) {
 			})
 		})
 
+		Convey("Metode SetNX", func() {
+			Convey("Seharusnya mengembalikan true jika kunci belum ada", func() {
+				mock.ExpectSetNX(key, value, exp).SetVal(true)
+				ok, err := redisClient.SetNX(ctx, key, value, exp)
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeTrue)
+				So(mock.ExpectationsWereMet(), ShouldBeNil)
+			})
+
+			Convey("Seharusnya mengembalikan false jika kunci sudah ada", func() {
+				mock.ExpectSetNX(key, value, exp).SetVal(false)
+				ok, err := redisClient.SetNX(ctx, key, value, exp)
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeFalse)
+				So(mock.ExpectationsWereMet(), ShouldBeNil)
+			})
+		})
+
 		Convey("Metode Delete", func() {
 			Convey("Seharusnya berhasil menghapus kunci jika ada", func() {
 				mock.ExpectDel(key).SetVal(1) // 1 artinya 1 kunci dihapus
@@ -71,5 +89,51 @@ func TestRedisClient(t *testing.T) {
 				So(mock.ExpectationsWereMet(), ShouldBeNil)
 			})
 		})
+
+		Convey("Metode GetDelete", func() {
+			Convey("Seharusnya mengembalikan nilai dan menghapus kunci dalam satu perintah", func() {
+				mock.ExpectGetDel(key).SetVal(value)
+				result, err := redisClient.GetDelete(ctx, key)
+				So(err, ShouldBeNil)
+				So(result, ShouldEqual, value)
+				So(mock.ExpectationsWereMet(), ShouldBeNil)
+			})
+
+			Convey("Seharusnya mengembalikan error redis.Nil jika kunci sudah diambil", func() {
+				mock.ExpectGetDel(key).RedisNil()
+				_, err := redisClient.GetDelete(ctx, key)
+				So(err, ShouldEqual, redis.Nil)
+				So(mock.ExpectationsWereMet(), ShouldBeNil)
+			})
+		})
+
+		Convey("Metode Increment", func() {
+			Convey("Seharusnya menambah counter dan memasang masa berlaku dalam satu skrip", func() {
+				mock.ExpectEvalSha(incrementScript.Hash(), []string{key}, exp.Milliseconds()).SetVal(int64(1))
+				value, err := redisClient.Increment(ctx, key, exp)
+				So(err, ShouldBeNil)
+				So(value, ShouldEqual, 1)
+				So(mock.ExpectationsWereMet(), ShouldBeNil)
+			})
+
+			Convey("Seharusnya mengembalikan error jika Redis gagal menambah counter", func() {
+				mock.ExpectEvalSha(incrementScript.Hash(), []string{key}, exp.Milliseconds()).SetErr(errors.New("incr failed"))
+				_, err := redisClient.Increment(ctx, key, exp)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "incr failed")
+				So(mock.ExpectationsWereMet(), ShouldBeNil)
+			})
+		})
+
+		Convey("Fungsi IsNotFound", func() {
+			Convey("Seharusnya mengembalikan true untuk redis.Nil", func() {
+				So(IsNotFound(redis.Nil), ShouldBeTrue)
+			})
+
+			Convey("Seharusnya mengembalikan false untuk error lain", func() {
+				So(IsNotFound(errors.New("connection refused")), ShouldBeFalse)
+				So(IsNotFound(nil), ShouldBeFalse)
+			})
+		})
 	})
 }
@@ -0,0 +1,81 @@
+// Package geoip resolves client IP addresses to an approximate location using a
+// local MaxMind-format (MMDB) database, so lookups work without network access.
+package geoip
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// Location is the approximate place an IP address belongs to. Fields the database
+// does not know about are left empty.
+type Location struct {
+	City        string
+	Country     string
+	CountryCode string
+}
+
+// String describes the location the way it is shown to users, e.g. "Jakarta, Indonesia".
+// It returns an empty string for an unknown location.
+func (l Location) String() string {
+	parts := make([]string, 0, 2)
+	if l.City != "" {
+		parts = append(parts, l.City)
+	}
+	if l.Country != "" {
+		parts = append(parts, l.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Locator looks up the location of an IP address.
+type Locator interface {
+	// Lookup returns the location of ip. Unparseable, private and unknown addresses
+	// return a zero Location without an error.
+	Lookup(ip string) (Location, error)
+
+	// Close releases the underlying database.
+	Close() error
+}
+
+// New opens the MMDB file at path. An empty path disables lookups and returns a
+// Locator that never knows the location.
+func New(path string) (Locator, error) {
+	if path == "" {
+		return noopLocator{}, nil
+	}
+	return NewMMDBLocator(path)
+}
+
+type noopLocator struct{}
+
+func (noopLocator) Lookup(string) (Location, error) { return Location{}, nil }
+
+func (noopLocator) Close() error { return nil }
+
+const (
+	ipv4RangeBits = 24
+	ipv6RangeBits = 48
+)
+
+// IPRange returns the network an IP address belongs to: its /24 for IPv4 and its
+// /48 for IPv6, so a client that moves within its provider's range is still recognised.
+// It returns an empty string when ip cannot be parsed.
+func IPRange(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	bits := ipv6RangeBits
+	if addr.Is4() {
+		bits = ipv4RangeBits
+	}
+
+	prefix, err := addr.WithZone("").Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
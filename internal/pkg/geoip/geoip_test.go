@@ -0,0 +1,72 @@
+package geoip
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIPRange(t *testing.T) {
+	Convey("Diberikan berbagai alamat IP", t, func() {
+		cases := []struct {
+			name string
+			ip   string
+			want string
+		}{
+			{name: "IPv4", ip: "203.0.113.77", want: "203.0.113.0/24"},
+			{name: "IPv4 yang dipetakan ke IPv6", ip: "::ffff:203.0.113.77", want: "203.0.113.0/24"},
+			{name: "IPv6", ip: "2001:db8:1234:5678::1", want: "2001:db8:1234::/48"},
+			{name: "alamat tidak valid", ip: "bukan-ip", want: ""},
+			{name: "alamat kosong", ip: "", want: ""},
+		}
+
+		for _, tc := range cases {
+			Convey("Ketika alamatnya "+tc.name, func() {
+				So(IPRange(tc.ip), ShouldEqual, tc.want)
+			})
+		}
+
+		Convey("Ketika dua alamat berada di jaringan yang sama", func() {
+			So(IPRange("198.51.100.1"), ShouldEqual, IPRange("198.51.100.254"))
+			So(IPRange("198.51.100.1"), ShouldNotEqual, IPRange("198.51.101.1"))
+		})
+	})
+}
+
+func TestLocation(t *testing.T) {
+	Convey("Diberikan sebuah lokasi", t, func() {
+		Convey("Ketika kota dan negara diketahui", func() {
+			So(Location{City: "Jakarta", Country: "Indonesia"}.String(), ShouldEqual, "Jakarta, Indonesia")
+		})
+
+		Convey("Ketika hanya negara yang diketahui", func() {
+			So(Location{Country: "Indonesia", CountryCode: "ID"}.String(), ShouldEqual, "Indonesia")
+		})
+
+		Convey("Ketika lokasi tidak diketahui", func() {
+			So(Location{}.String(), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestNew(t *testing.T) {
+	Convey("Diberikan path database GeoIP", t, func() {
+		Convey("Ketika path kosong", func() {
+			locator, err := New("")
+			So(err, ShouldBeNil)
+
+			Convey("Maka lookup tidak pernah mengetahui lokasi", func() {
+				location, err := locator.Lookup("203.0.113.77")
+				So(err, ShouldBeNil)
+				So(location, ShouldResemble, Location{})
+				So(locator.Close(), ShouldBeNil)
+			})
+		})
+
+		Convey("Ketika file database tidak ada", func() {
+			_, err := New(filepath.Join(t.TempDir(), "missing.mmdb"))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// localeEnglish is the name locale read from the database. Every MaxMind database
+// carries English names, other locales are optional.
+const localeEnglish = "en"
+
+// MMDBLocator reads locations from a GeoIP2 or GeoLite2 City database.
+type MMDBLocator struct {
+	reader *geoip2.Reader
+}
+
+// NewMMDBLocator opens the City database at path.
+func NewMMDBLocator(path string) (*MMDBLocator, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	return &MMDBLocator{reader: reader}, nil
+}
+
+func (l *MMDBLocator) Lookup(ip string) (Location, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil || addr.IsPrivate() || addr.IsLoopback() || addr.IsUnspecified() {
+		return Location{}, nil
+	}
+
+	record, err := l.reader.City(addr)
+	if err != nil {
+		return Location{}, fmt.Errorf("failed to look up IP address: %w", err)
+	}
+
+	return Location{
+		City:        record.City.Names[localeEnglish],
+		Country:     record.Country.Names[localeEnglish],
+		CountryCode: record.Country.IsoCode,
+	}, nil
+}
+
+func (l *MMDBLocator) Close() error {
+	return l.reader.Close()
+}
@@ -0,0 +1,355 @@
+// Package otpcode issues and verifies one-time codes sent to users by email. Codes are
+// never stored in plain text: the cache only holds an HMAC of the code bound to the
+// purpose and subject it was issued for, so a leaked cache entry cannot be replayed
+// and a code issued for one flow is never accepted by another.
+//
+// A flow can also be delivered as a signed link instead of a code. The link's token
+// ID is then kept in place of the code, so both deliveries share the same expiry,
+// resend cooldown, attempt limit and revocation, and issuing one replaces the other.
+package otpcode
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sammidev/goca/internal/config"
+	"github.com/sammidev/goca/internal/pkg/cache"
+	"github.com/sammidev/goca/internal/pkg/random"
+)
+
+// Purpose names the flow a code was issued for.
+type Purpose string
+
+const (
+	PurposeEmailVerification Purpose = "email_verification"
+	PurposePasswordReset     Purpose = "password_reset"
+	PurposeEmailChange       Purpose = "email_change"
+)
+
+// Delivery is how the secret for a flow reaches the user.
+type Delivery string
+
+const (
+	// DeliveryCode emails a numeric code that the user types in.
+	DeliveryCode Delivery = "otp"
+	// DeliveryLink emails a signed, single-use link.
+	DeliveryLink Delivery = "link"
+)
+
+// ParseDelivery parses a configured delivery mode, defaulting to DeliveryCode.
+func ParseDelivery(value string) (Delivery, error) {
+	switch delivery := Delivery(strings.ToLower(strings.TrimSpace(value))); delivery {
+	case "":
+		return DeliveryCode, nil
+	case DeliveryCode, DeliveryLink:
+		return delivery, nil
+	default:
+		return "", fmt.Errorf("unsupported delivery mode %q, expected %q or %q", value, DeliveryCode, DeliveryLink)
+	}
+}
+
+const keyPrefix = "otp"
+
+var (
+	// ErrExpired is returned when no code is pending, because it expired, was used
+	// or was never issued.
+	ErrExpired = errors.New("otpcode: code expired or not issued")
+	// ErrInvalid is returned for a wrong code that still has attempts left.
+	ErrInvalid = errors.New("otpcode: invalid code")
+	// ErrTooManyAttempts is returned once a code has been guessed wrong too often.
+	// The code is invalidated and a new one has to be requested.
+	ErrTooManyAttempts = errors.New("otpcode: too many invalid attempts")
+)
+
+// CooldownError is returned by Issue when the previous code for the same purpose
+// and subject was sent too recently.
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("otpcode: code was sent recently, retry after %s", e.RetryAfter)
+}
+
+// Store issues and verifies one-time codes.
+type Store interface {
+	// Delivery returns how the secret for the purpose is sent to the user.
+	Delivery(purpose Purpose) Delivery
+
+	// Issue generates a new code for the subject, replacing any pending one.
+	Issue(ctx context.Context, purpose Purpose, subject string) (string, error)
+
+	// IssueLink keeps the ID of a signed link as the pending secret for the
+	// subject, replacing any pending code or link. The ID is verified like a code.
+	IssueLink(ctx context.Context, purpose Purpose, subject, linkID string) error
+
+	// Verify checks code against the pending code without consuming it, so a flow
+	// that fails afterwards can be retried with the same code. Every call counts
+	// as an attempt.
+	Verify(ctx context.Context, purpose Purpose, subject, code string) error
+
+	// Consume takes a verified code atomically, right before the flow it guards
+	// completes. Only one caller gets the code: a concurrent or later call with the
+	// same code gets ErrExpired.
+	Consume(ctx context.Context, purpose Purpose, subject, code string) error
+
+	// Revoke discards the pending code, once it has been used or is no longer wanted.
+	Revoke(ctx context.Context, purpose Purpose, subject string) error
+
+	// Cancel discards the pending code together with its cooldown, for a code that
+	// was issued but never sent, so a retry is not held back.
+	Cancel(ctx context.Context, purpose Purpose, subject string) error
+}
+
+// CacheStore keeps codes in the cache until they expire.
+type CacheStore struct {
+	cache       cache.Cache
+	secret      []byte
+	length      int
+	ttl         time.Duration
+	cooldown    time.Duration
+	maxAttempts int64
+	deliveries  map[Purpose]Delivery
+}
+
+var _ Store = (*CacheStore)(nil)
+
+func NewCacheStore(cache cache.Cache, cfg *config.Config) (*CacheStore, error) {
+	if cfg.AuthOTPSecret == "" {
+		return nil, errors.New("AUTH_OTP_SECRET is required to hash one-time codes")
+	}
+
+	emailVerification, err := ParseDelivery(cfg.AuthEmailVerificationMode)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_EMAIL_VERIFICATION_MODE: %w", err)
+	}
+	passwordReset, err := ParseDelivery(cfg.AuthPasswordResetMode)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_PASSWORD_RESET_MODE: %w", err)
+	}
+
+	return &CacheStore{
+		cache:       cache,
+		secret:      []byte(cfg.AuthOTPSecret),
+		length:      config.OTPCodeLength,
+		ttl:         config.EmailConfirmationExpInMin,
+		cooldown:    config.OTPResendCooldown,
+		maxAttempts: config.OTPMaxAttempts,
+		deliveries: map[Purpose]Delivery{
+			PurposeEmailVerification: emailVerification,
+			PurposePasswordReset:     passwordReset,
+		},
+	}, nil
+}
+
+// Delivery returns the configured delivery for the purpose. Purposes without a
+// setting, such as email changes, always use codes.
+func (s *CacheStore) Delivery(purpose Purpose) Delivery {
+	if delivery, ok := s.deliveries[purpose]; ok {
+		return delivery
+	}
+	return DeliveryCode
+}
+
+func (s *CacheStore) Issue(ctx context.Context, purpose Purpose, subject string) (string, error) {
+	if err := s.startCooldown(ctx, purpose, subject); err != nil {
+		return "", err
+	}
+
+	code, err := random.GenerateNumericOTP(s.length)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.store(ctx, purpose, subject, code); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (s *CacheStore) IssueLink(ctx context.Context, purpose Purpose, subject, linkID string) error {
+	if linkID == "" {
+		return errors.New("otpcode: link ID is required")
+	}
+
+	if err := s.startCooldown(ctx, purpose, subject); err != nil {
+		return err
+	}
+
+	return s.store(ctx, purpose, subject, linkID)
+}
+
+func (s *CacheStore) Verify(ctx context.Context, purpose Purpose, subject, code string) error {
+	value, err := s.cache.Get(ctx, codeKey(purpose, subject))
+	if err != nil {
+		if cache.IsNotFound(err) {
+			return ErrExpired
+		}
+		return err
+	}
+
+	_, digest, ok := parseRecord(fmt.Sprint(value))
+	if !ok {
+		return ErrExpired
+	}
+
+	// Attempts are counted before comparing, so concurrent guesses cannot get
+	// past the limit
+	attempts, err := s.cache.Increment(ctx, attemptsKey(purpose, subject), s.ttl)
+	if err != nil {
+		return err
+	}
+	if attempts > s.maxAttempts {
+		_ = s.Revoke(ctx, purpose, subject)
+		return ErrTooManyAttempts
+	}
+
+	if !hmac.Equal([]byte(digest), []byte(s.digest(purpose, subject, code))) {
+		if attempts == s.maxAttempts {
+			_ = s.Revoke(ctx, purpose, subject)
+			return ErrTooManyAttempts
+		}
+		return ErrInvalid
+	}
+
+	// The caller knows the code, so a flow that fails for another reason, such as
+	// a rejected password, does not use up the attempts
+	if err := s.cache.Delete(ctx, attemptsKey(purpose, subject)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *CacheStore) Consume(ctx context.Context, purpose Purpose, subject, code string) error {
+	value, err := s.cache.GetDelete(ctx, codeKey(purpose, subject))
+	if err != nil {
+		if cache.IsNotFound(err) {
+			return ErrExpired
+		}
+		return err
+	}
+
+	record := fmt.Sprint(value)
+	issuedAt, digest, ok := parseRecord(record)
+	if !ok {
+		return ErrExpired
+	}
+
+	if !hmac.Equal([]byte(digest), []byte(s.digest(purpose, subject, code))) {
+		// A newer code replaced the verified one, it is put back unless yet another
+		// code was issued in the meantime
+		if remaining := time.Until(issuedAt.Add(s.ttl)); remaining > 0 {
+			if _, err := s.cache.SetNX(ctx, codeKey(purpose, subject), record, remaining); err != nil {
+				return err
+			}
+		}
+		return ErrExpired
+	}
+
+	return s.cache.Delete(ctx, attemptsKey(purpose, subject))
+}
+
+func (s *CacheStore) Revoke(ctx context.Context, purpose Purpose, subject string) error {
+	for _, key := range []string{codeKey(purpose, subject), attemptsKey(purpose, subject)} {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CacheStore) Cancel(ctx context.Context, purpose Purpose, subject string) error {
+	if err := s.Revoke(ctx, purpose, subject); err != nil {
+		return err
+	}
+	return s.cache.Delete(ctx, cooldownKey(purpose, subject))
+}
+
+// startCooldown returns a CooldownError when a secret for the purpose and subject
+// was issued within the cooldown.
+func (s *CacheStore) startCooldown(ctx context.Context, purpose Purpose, subject string) error {
+	// The counter is created with the cooldown as its lifetime, so only the first
+	// request in each cooldown window gets through, even when several race
+	sent, err := s.cache.Increment(ctx, cooldownKey(purpose, subject), s.cooldown)
+	if err != nil {
+		return err
+	}
+	if sent > 1 {
+		return &CooldownError{RetryAfter: s.retryAfter(ctx, purpose, subject)}
+	}
+	return nil
+}
+
+// store replaces the pending secret and starts its attempts from zero.
+func (s *CacheStore) store(ctx context.Context, purpose Purpose, subject, secret string) error {
+	if err := s.cache.Delete(ctx, attemptsKey(purpose, subject)); err != nil {
+		return err
+	}
+
+	record := fmt.Sprintf("%d:%s", time.Now().Unix(), s.digest(purpose, subject, secret))
+	return s.cache.Set(ctx, codeKey(purpose, subject), record, s.ttl)
+}
+
+// retryAfter estimates how long the cooldown still runs from when the pending code
+// was issued, falling back to the full cooldown when that is unknown.
+func (s *CacheStore) retryAfter(ctx context.Context, purpose Purpose, subject string) time.Duration {
+	value, err := s.cache.Get(ctx, codeKey(purpose, subject))
+	if err != nil {
+		return s.cooldown
+	}
+
+	issuedAt, _, ok := parseRecord(fmt.Sprint(value))
+	if !ok {
+		return s.cooldown
+	}
+
+	remaining := time.Until(issuedAt.Add(s.cooldown))
+	if remaining <= 0 || remaining > s.cooldown {
+		return s.cooldown
+	}
+	return remaining
+}
+
+// digest binds the code to its purpose and subject, so the same digits issued for
+// another flow or another user never produce the same value.
+func (s *CacheStore) digest(purpose Purpose, subject, code string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(string(purpose) + "\x00" + subject + "\x00" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseRecord splits a stored "<issued unix>:<digest>" value.
+func parseRecord(record string) (time.Time, string, bool) {
+	issued, digest, ok := strings.Cut(record, ":")
+	if !ok || digest == "" {
+		return time.Time{}, "", false
+	}
+
+	unix, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return time.Unix(unix, 0), digest, true
+}
+
+func codeKey(purpose Purpose, subject string) string {
+	return fmt.Sprintf("%s:%s:%s", keyPrefix, purpose, subject)
+}
+
+func attemptsKey(purpose Purpose, subject string) string {
+	return codeKey(purpose, subject) + ":attempts"
+}
+
+func cooldownKey(purpose Purpose, subject string) string {
+	return codeKey(purpose, subject) + ":cooldown"
+}
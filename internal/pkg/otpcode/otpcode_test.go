@@ -0,0 +1,287 @@
+package otpcode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sammidev/goca/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// memoryCache adalah implementasi cache.Cache di memori untuk pengujian.
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string]any)}
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value any, exp time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return value, nil
+}
+
+func (m *memoryCache) GetDelete(ctx context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	delete(m.values, key)
+	return value, nil
+}
+
+func (m *memoryCache) SetNX(ctx context.Context, key string, value any, exp time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.values[key]; ok {
+		return false, nil
+	}
+	m.values[key] = value
+	return true, nil
+}
+
+func (m *memoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memoryCache) Increment(ctx context.Context, key string, exp time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, _ := m.values[key].(int64)
+	value++
+	m.values[key] = value
+	return value, nil
+}
+
+func (m *memoryCache) Close() error { return nil }
+
+// wrongCode mengembalikan kode yang pasti berbeda dari code.
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func TestNewCacheStore(t *testing.T) {
+	Convey("Diberikan konfigurasi tanpa secret OTP", t, func() {
+		_, err := NewCacheStore(newMemoryCache(), &config.Config{})
+
+		Convey("Maka store tidak bisa dibuat", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Diberikan mode pengiriman yang tidak dikenal", t, func() {
+		_, err := NewCacheStore(newMemoryCache(), &config.Config{AuthOTPSecret: "test-secret", AuthPasswordResetMode: "sms"})
+
+		Convey("Maka store tidak bisa dibuat", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Diberikan mode tautan untuk reset password", t, func() {
+		store, err := NewCacheStore(newMemoryCache(), &config.Config{AuthOTPSecret: "test-secret", AuthPasswordResetMode: " Link "})
+		So(err, ShouldBeNil)
+
+		Convey("Maka hanya reset password yang dikirim lewat tautan", func() {
+			So(store.Delivery(PurposePasswordReset), ShouldEqual, DeliveryLink)
+			So(store.Delivery(PurposeEmailVerification), ShouldEqual, DeliveryCode)
+			So(store.Delivery(PurposeEmailChange), ShouldEqual, DeliveryCode)
+		})
+	})
+}
+
+func TestCacheStore(t *testing.T) {
+	Convey("Diberikan store OTP berbasis cache", t, func() {
+		ctx := context.Background()
+		memory := newMemoryCache()
+		store, err := NewCacheStore(memory, &config.Config{AuthOTPSecret: "test-secret"})
+		So(err, ShouldBeNil)
+
+		subject := "sammi@example.com"
+
+		Convey("Ketika kode diterbitkan", func() {
+			code, err := store.Issue(ctx, PurposePasswordReset, subject)
+			So(err, ShouldBeNil)
+			So(code, ShouldHaveLength, config.OTPCodeLength)
+
+			Convey("Maka kode tidak disimpan dalam bentuk asli", func() {
+				value, err := memory.Get(ctx, codeKey(PurposePasswordReset, subject))
+				So(err, ShouldBeNil)
+				So(value, ShouldNotContainSubstring, code)
+			})
+
+			Convey("Maka kode yang benar diterima", func() {
+				So(store.Verify(ctx, PurposePasswordReset, subject, code), ShouldBeNil)
+			})
+
+			Convey("Maka kode tidak berlaku untuk tujuan lain", func() {
+				So(store.Verify(ctx, PurposeEmailVerification, subject, code), ShouldEqual, ErrExpired)
+			})
+
+			Convey("Maka kode tidak berlaku untuk subjek lain", func() {
+				err := store.Verify(ctx, PurposePasswordReset, "other@example.com", code)
+				So(err, ShouldEqual, ErrExpired)
+			})
+
+			Convey("Maka kode yang salah ditolak", func() {
+				So(store.Verify(ctx, PurposePasswordReset, subject, wrongCode(code)), ShouldEqual, ErrInvalid)
+			})
+
+			Convey("Maka kode dibatalkan setelah terlalu banyak percobaan salah", func() {
+				for i := 1; i < config.OTPMaxAttempts; i++ {
+					So(store.Verify(ctx, PurposePasswordReset, subject, wrongCode(code)), ShouldEqual, ErrInvalid)
+				}
+				So(store.Verify(ctx, PurposePasswordReset, subject, wrongCode(code)), ShouldEqual, ErrTooManyAttempts)
+				So(store.Verify(ctx, PurposePasswordReset, subject, code), ShouldEqual, ErrExpired)
+			})
+
+			Convey("Maka kode yang benar mengatur ulang jumlah percobaan", func() {
+				for i := 0; i < config.OTPMaxAttempts; i++ {
+					So(store.Verify(ctx, PurposePasswordReset, subject, code), ShouldBeNil)
+				}
+			})
+
+			Convey("Maka kode tidak berlaku lagi setelah dicabut", func() {
+				So(store.Revoke(ctx, PurposePasswordReset, subject), ShouldBeNil)
+				So(store.Verify(ctx, PurposePasswordReset, subject, code), ShouldEqual, ErrExpired)
+			})
+
+			Convey("Maka kode hanya bisa dipakai sekali", func() {
+				So(store.Consume(ctx, PurposePasswordReset, subject, code), ShouldBeNil)
+				So(store.Consume(ctx, PurposePasswordReset, subject, code), ShouldEqual, ErrExpired)
+				So(store.Verify(ctx, PurposePasswordReset, subject, code), ShouldEqual, ErrExpired)
+			})
+
+			Convey("Maka pemakaian serentak hanya berhasil sekali", func() {
+				var (
+					wg        sync.WaitGroup
+					mu        sync.Mutex
+					successes int
+				)
+				for range 10 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if store.Consume(ctx, PurposePasswordReset, subject, code) == nil {
+							mu.Lock()
+							successes++
+							mu.Unlock()
+						}
+					}()
+				}
+				wg.Wait()
+				So(successes, ShouldEqual, 1)
+			})
+
+			Convey("Maka kode yang salah tidak menghapus kode yang tertunda", func() {
+				So(store.Consume(ctx, PurposePasswordReset, subject, wrongCode(code)), ShouldEqual, ErrExpired)
+				So(store.Verify(ctx, PurposePasswordReset, subject, code), ShouldBeNil)
+			})
+
+			Convey("Maka kode yang dibatalkan tidak menahan kode baru", func() {
+				So(store.Cancel(ctx, PurposePasswordReset, subject), ShouldBeNil)
+				So(store.Verify(ctx, PurposePasswordReset, subject, code), ShouldEqual, ErrExpired)
+
+				_, err := store.Issue(ctx, PurposePasswordReset, subject)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Maka kode baru ditolak selama masa tunggu", func() {
+				_, err := store.Issue(ctx, PurposePasswordReset, subject)
+
+				var cooldownErr *CooldownError
+				So(errors.As(err, &cooldownErr), ShouldBeTrue)
+				So(cooldownErr.RetryAfter, ShouldBeGreaterThan, 0)
+				So(cooldownErr.RetryAfter, ShouldBeLessThanOrEqualTo, config.OTPResendCooldown)
+			})
+
+			Convey("Maka kode untuk tujuan lain tetap bisa diterbitkan", func() {
+				_, err := store.Issue(ctx, PurposeEmailVerification, subject)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("Ketika kode baru diterbitkan setelah masa tunggu", func() {
+			first, err := store.Issue(ctx, PurposePasswordReset, subject)
+			So(err, ShouldBeNil)
+			So(store.Verify(ctx, PurposePasswordReset, subject, wrongCode(first)), ShouldEqual, ErrInvalid)
+
+			So(memory.Delete(ctx, cooldownKey(PurposePasswordReset, subject)), ShouldBeNil)
+			second, err := store.Issue(ctx, PurposePasswordReset, subject)
+			So(err, ShouldBeNil)
+
+			Convey("Maka jumlah percobaan dimulai dari awal", func() {
+				_, err := memory.Get(ctx, attemptsKey(PurposePasswordReset, subject))
+				So(err, ShouldEqual, redis.Nil)
+			})
+
+			Convey("Maka hanya kode terbaru yang berlaku", func() {
+				So(store.Verify(ctx, PurposePasswordReset, subject, second), ShouldBeNil)
+				if first != second {
+					So(store.Verify(ctx, PurposePasswordReset, subject, first), ShouldEqual, ErrInvalid)
+				}
+			})
+		})
+
+		Convey("Ketika tautan diterbitkan", func() {
+			linkID := "0b9f5c2e-4d1a-4c3e-9a57-6f0d2b8e1c44"
+			So(store.IssueLink(ctx, PurposeEmailVerification, subject, linkID), ShouldBeNil)
+
+			Convey("Maka ID tautan diterima seperti kode", func() {
+				So(store.Verify(ctx, PurposeEmailVerification, subject, linkID), ShouldBeNil)
+			})
+
+			Convey("Maka tautan tidak berlaku lagi setelah dicabut", func() {
+				So(store.Revoke(ctx, PurposeEmailVerification, subject), ShouldBeNil)
+				So(store.Verify(ctx, PurposeEmailVerification, subject, linkID), ShouldEqual, ErrExpired)
+			})
+
+			Convey("Maka kode baru ditolak selama masa tunggu", func() {
+				_, err := store.Issue(ctx, PurposeEmailVerification, subject)
+
+				var cooldownErr *CooldownError
+				So(errors.As(err, &cooldownErr), ShouldBeTrue)
+			})
+
+			Convey("Maka kode yang diterbitkan setelah masa tunggu menggantikan tautan", func() {
+				So(memory.Delete(ctx, cooldownKey(PurposeEmailVerification, subject)), ShouldBeNil)
+				code, err := store.Issue(ctx, PurposeEmailVerification, subject)
+				So(err, ShouldBeNil)
+
+				So(store.Verify(ctx, PurposeEmailVerification, subject, linkID), ShouldEqual, ErrInvalid)
+				So(store.Verify(ctx, PurposeEmailVerification, subject, code), ShouldBeNil)
+			})
+		})
+
+		Convey("Ketika belum ada kode yang diterbitkan", func() {
+			Convey("Maka verifikasi menganggap kode kedaluwarsa", func() {
+				So(store.Verify(ctx, PurposeEmailChange, subject, "123456"), ShouldEqual, ErrExpired)
+			})
+		})
+	})
+}
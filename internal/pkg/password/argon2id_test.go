@@ -0,0 +1,88 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testArgon2idParams keeps the tests fast, real hashes use DefaultArgon2idParams.
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2idHasher(t *testing.T) {
+	Convey("Diberikan Argon2idHasher", t, func() {
+		password := "mySecretPassword123"
+		hasher, err := NewArgon2idHasher(testArgon2idParams)
+		So(err, ShouldBeNil)
+
+		Convey("Fungsi Hash", func() {
+			hash, err := hasher.Hash(password)
+			So(err, ShouldBeNil)
+
+			Convey("Hash harus berformat PHC dengan parameter yang dikonfigurasi", func() {
+				So(hash, ShouldStartWith, "$argon2id$v=19$m=1024,t=1,p=1$")
+				So(strings.Split(hash, "$"), ShouldHaveLength, 6)
+			})
+
+			Convey("Password yang sama harus menghasilkan hash berbeda karena salt acak", func() {
+				other, err := hasher.Hash(password)
+				So(err, ShouldBeNil)
+				So(other, ShouldNotEqual, hash)
+			})
+		})
+
+		Convey("Fungsi Verify", func() {
+			hash, _ := hasher.Hash(password)
+
+			Convey("Ketika password yang benar diberikan", func() {
+				match, err := hasher.Verify(password, hash)
+				So(err, ShouldBeNil)
+				So(match, ShouldBeTrue)
+			})
+
+			Convey("Ketika password yang salah diberikan", func() {
+				match, err := hasher.Verify("wrongPassword", hash)
+				So(err, ShouldBeNil)
+				So(match, ShouldBeFalse)
+			})
+
+			Convey("Ketika hash dibuat dengan parameter lain", func() {
+				stronger, _ := NewArgon2idHasher(Argon2idParams{Memory: 2048, Iterations: 2, Parallelism: 1})
+				otherHash, _ := stronger.Hash(password)
+
+				match, err := hasher.Verify(password, otherHash)
+				So(err, ShouldBeNil)
+				So(match, ShouldBeTrue)
+			})
+
+			Convey("Ketika hash rusak", func() {
+				_, err := hasher.Verify(password, "$argon2id$v=19$m=1024,t=1,p=1$bukan-base64!")
+				So(err, ShouldEqual, ErrMalformedHash)
+			})
+
+			Convey("Ketika versi argon2 tidak didukung", func() {
+				_, err := hasher.Verify(password, strings.Replace(hash, "v=19", "v=16", 1))
+				So(err, ShouldEqual, ErrUnsupportedHash)
+			})
+		})
+
+		Convey("Fungsi NeedsRehash", func() {
+			hash, _ := hasher.Hash(password)
+
+			Convey("Ketika parameter hash sama dengan konfigurasi", func() {
+				So(hasher.NeedsRehash(hash), ShouldBeFalse)
+			})
+
+			Convey("Ketika konfigurasi berubah", func() {
+				stronger, _ := NewArgon2idHasher(Argon2idParams{Memory: 2048, Iterations: 1, Parallelism: 1})
+				So(stronger.NeedsRehash(hash), ShouldBeTrue)
+			})
+		})
+
+		Convey("Ketika memory terlalu kecil untuk paralelisme", func() {
+			_, err := NewArgon2idHasher(Argon2idParams{Memory: 8, Parallelism: 4})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -1,21 +1,49 @@
 package password
 
 import (
+	"errors"
+	"fmt"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword hashes the given password using bcrypt.
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// BcryptHasher produces hashes in bcrypt's own modular crypt format, e.g.
+// $2a$10$<salt and key>, which the PHC string format adopts as-is for bcrypt.
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a hasher for the given cost, bcrypt.DefaultCost when zero.
+func NewBcryptHasher(cost int) (*BcryptHasher, error) {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return &BcryptHasher{cost: cost}, nil
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	if err != nil {
 		return "", err
 	}
 	return string(bytes), nil
 }
 
-// CheckPasswordHash compares a plain password with a hashed password.
-// It returns true if they match, false otherwise.
-func CheckPasswordHash(password, hash string) bool {
+func (h *BcryptHasher) Verify(password, hash string) (bool, error) {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	}
+	return false, ErrMalformedHash
+}
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
 }
@@ -7,53 +7,64 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func TestPasswordHashing(t *testing.T) {
-
-	Convey("Diberikan fungsi-fungsi hashing password", t, func() {
+func TestBcryptHasher(t *testing.T) {
+	Convey("Diberikan BcryptHasher", t, func() {
 		password := "mySecretPassword123"
+		hasher, err := NewBcryptHasher(bcrypt.MinCost)
+		So(err, ShouldBeNil)
 
-		Convey("Fungsi HashPassword", func() {
+		Convey("Fungsi Hash", func() {
 			Convey("Ketika password yang valid diberikan", func() {
-				hash, err := HashPassword(password)
+				hash, err := hasher.Hash(password)
 
 				Convey("Seharusnya tidak mengembalikan error", func() {
 					So(err, ShouldBeNil)
 				})
-				Convey("Seharusnya mengembalikan hash yang tidak kosong", func() {
-					So(hash, ShouldNotBeEmpty)
-				})
-				Convey("Hash yang dihasilkan harus merupakan hash bcrypt yang valid", func() {
-					// Membandingkan hash dengan password kosong akan gagal, tapi ini memvalidasi format hash.
-					err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(""))
-					So(err, ShouldNotBeNil)
+				Convey("Hash yang dihasilkan harus merupakan hash bcrypt dengan cost yang dikonfigurasi", func() {
+					cost, err := bcrypt.Cost([]byte(hash))
+					So(err, ShouldBeNil)
+					So(cost, ShouldEqual, bcrypt.MinCost)
 				})
 			})
 		})
 
-		Convey("Fungsi CheckPasswordHash", func() {
-			// Hasilkan hash terlebih dahulu untuk digunakan dalam perbandingan
-			hashedPassword, _ := HashPassword(password)
+		Convey("Fungsi Verify", func() {
+			hash, _ := hasher.Hash(password)
 
 			Convey("Ketika password yang benar diberikan", func() {
-				match := CheckPasswordHash(password, hashedPassword)
-				Convey("Seharusnya mengembalikan true", func() {
-					So(match, ShouldBeTrue)
-				})
+				match, err := hasher.Verify(password, hash)
+				So(err, ShouldBeNil)
+				So(match, ShouldBeTrue)
 			})
 
 			Convey("Ketika password yang salah diberikan", func() {
-				match := CheckPasswordHash("wrongPassword", hashedPassword)
-				Convey("Seharusnya mengembalikan false", func() {
-					So(match, ShouldBeFalse)
-				})
+				match, err := hasher.Verify("wrongPassword", hash)
+				So(err, ShouldBeNil)
+				So(match, ShouldBeFalse)
 			})
 
 			Convey("Ketika hash yang tidak valid diberikan", func() {
-				match := CheckPasswordHash(password, "notAValidHash")
-				Convey("Seharusnya mengembalikan false", func() {
-					So(match, ShouldBeFalse)
-				})
+				match, err := hasher.Verify(password, "notAValidHash")
+				So(err, ShouldEqual, ErrMalformedHash)
+				So(match, ShouldBeFalse)
 			})
 		})
+
+		Convey("Fungsi NeedsRehash", func() {
+			Convey("Ketika hash dibuat dengan cost yang sama", func() {
+				hash, _ := hasher.Hash(password)
+				So(hasher.NeedsRehash(hash), ShouldBeFalse)
+			})
+
+			Convey("Ketika hash dibuat dengan cost yang berbeda", func() {
+				hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost+1)
+				So(hasher.NeedsRehash(string(hash)), ShouldBeTrue)
+			})
+		})
+
+		Convey("Ketika cost di luar batas bcrypt", func() {
+			_, err := NewBcryptHasher(bcrypt.MaxCost + 1)
+			So(err, ShouldNotBeNil)
+		})
 	})
 }
@@ -0,0 +1,111 @@
+// Package password hashes and verifies user passwords. Hashes are stored in PHC
+// string format, which records the algorithm and its parameters next to the salt,
+// so the parameters for new hashes can change without invalidating older ones.
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sammidev/goca/internal/config"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	// ErrUnsupportedHash is returned for hashes of an algorithm this package does not know.
+	ErrUnsupportedHash = errors.New("password: unsupported hash algorithm")
+	// ErrMalformedHash is returned for hashes that cannot be decoded.
+	ErrMalformedHash = errors.New("password: malformed hash")
+)
+
+// Hasher hashes passwords and verifies them against stored hashes.
+type Hasher interface {
+	// Hash returns the PHC-formatted hash of password.
+	Hash(password string) (string, error)
+
+	// Verify reports whether password matches hash. A mismatch is not an error,
+	// errors are reserved for hashes that cannot be read.
+	Verify(password, hash string) (bool, error)
+
+	// NeedsRehash reports whether hash was produced with a different algorithm or
+	// different parameters than the ones used for new hashes.
+	NeedsRehash(hash string) bool
+}
+
+// New returns a Hasher that produces new hashes with the configured algorithm and
+// parameters, and still verifies hashes of every supported algorithm.
+func New(cfg *config.Config) (Hasher, error) {
+	bcryptHasher, err := NewBcryptHasher(cfg.PasswordBcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	argon2idHasher, err := NewArgon2idHasher(Argon2idParams{
+		Memory:      cfg.PasswordArgon2Memory,
+		Iterations:  cfg.PasswordArgon2Iterations,
+		Parallelism: cfg.PasswordArgon2Parallelism,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h := &hasher{
+		hashers: map[string]Hasher{
+			AlgorithmArgon2id: argon2idHasher,
+			AlgorithmBcrypt:   bcryptHasher,
+		},
+	}
+
+	switch cfg.PasswordHashAlgorithm {
+	case "", AlgorithmArgon2id:
+		h.algorithm = AlgorithmArgon2id
+	case AlgorithmBcrypt:
+		h.algorithm = AlgorithmBcrypt
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.PasswordHashAlgorithm)
+	}
+
+	return h, nil
+}
+
+// hasher dispatches verification to the algorithm a hash was made with.
+type hasher struct {
+	algorithm string
+	hashers   map[string]Hasher
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	return h.hashers[h.algorithm].Hash(password)
+}
+
+func (h *hasher) Verify(password, hash string) (bool, error) {
+	algorithm, err := Identify(hash)
+	if err != nil {
+		return false, err
+	}
+	return h.hashers[algorithm].Verify(password, hash)
+}
+
+func (h *hasher) NeedsRehash(hash string) bool {
+	algorithm, err := Identify(hash)
+	if err != nil || algorithm != h.algorithm {
+		return true
+	}
+	return h.hashers[algorithm].NeedsRehash(hash)
+}
+
+// Identify returns the algorithm a hash was produced with.
+func Identify(hash string) (string, error) {
+	switch {
+	case strings.HasPrefix(hash, "$"+AlgorithmArgon2id+"$"):
+		return AlgorithmArgon2id, nil
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return AlgorithmBcrypt, nil
+	}
+	return "", ErrUnsupportedHash
+}
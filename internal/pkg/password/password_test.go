@@ -0,0 +1,86 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/sammidev/goca/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testConfig(algorithm string) *config.Config {
+	return &config.Config{
+		PasswordHashAlgorithm:     algorithm,
+		PasswordArgon2Memory:      testArgon2idParams.Memory,
+		PasswordArgon2Iterations:  testArgon2idParams.Iterations,
+		PasswordArgon2Parallelism: testArgon2idParams.Parallelism,
+		PasswordBcryptCost:        bcrypt.MinCost,
+	}
+}
+
+func TestNew(t *testing.T) {
+	Convey("Diberikan konfigurasi hashing password", t, func() {
+		password := "mySecretPassword123"
+
+		Convey("Ketika algoritma tidak diisi", func() {
+			hasher, err := New(testConfig(""))
+			So(err, ShouldBeNil)
+
+			Convey("Maka hash baru memakai argon2id", func() {
+				hash, err := hasher.Hash(password)
+				So(err, ShouldBeNil)
+
+				algorithm, err := Identify(hash)
+				So(err, ShouldBeNil)
+				So(algorithm, ShouldEqual, AlgorithmArgon2id)
+			})
+		})
+
+		Convey("Ketika algoritma tidak dikenal", func() {
+			_, err := New(testConfig("md5"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Ketika hash lama dibuat dengan bcrypt dan konfigurasi memakai argon2id", func() {
+			legacy, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+			hasher, err := New(testConfig(AlgorithmArgon2id))
+			So(err, ShouldBeNil)
+
+			Convey("Maka hash lama tetap bisa diverifikasi", func() {
+				match, err := hasher.Verify(password, string(legacy))
+				So(err, ShouldBeNil)
+				So(match, ShouldBeTrue)
+			})
+
+			Convey("Maka hash lama perlu di-hash ulang", func() {
+				So(hasher.NeedsRehash(string(legacy)), ShouldBeTrue)
+			})
+
+			Convey("Maka hash baru tidak perlu di-hash ulang", func() {
+				hash, _ := hasher.Hash(password)
+				So(hasher.NeedsRehash(hash), ShouldBeFalse)
+			})
+		})
+
+		Convey("Ketika konfigurasi kembali ke bcrypt", func() {
+			argon2idHasher, _ := New(testConfig(AlgorithmArgon2id))
+			hash, _ := argon2idHasher.Hash(password)
+			hasher, err := New(testConfig(AlgorithmBcrypt))
+			So(err, ShouldBeNil)
+
+			Convey("Maka hash argon2id tetap bisa diverifikasi tetapi perlu di-hash ulang", func() {
+				match, err := hasher.Verify(password, hash)
+				So(err, ShouldBeNil)
+				So(match, ShouldBeTrue)
+				So(hasher.NeedsRehash(hash), ShouldBeTrue)
+			})
+		})
+
+		Convey("Ketika hash berasal dari algoritma yang tidak didukung", func() {
+			hasher, _ := New(testConfig(""))
+			_, err := hasher.Verify(password, "$1$salt$hash")
+			So(err, ShouldEqual, ErrUnsupportedHash)
+			So(hasher.NeedsRehash("$1$salt$hash"), ShouldBeTrue)
+		})
+	})
+}
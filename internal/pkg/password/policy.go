@@ -0,0 +1,222 @@
+package password
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ccojocar/zxcvbn-go"
+	"github.com/sammidev/goca/internal/config"
+)
+
+// Rule names a single password policy requirement. Rules are stable identifiers
+// that callers can localize or return to clients as they are.
+type Rule string
+
+const (
+	RuleMinLength    Rule = "min_length"
+	RuleLowercase    Rule = "lowercase"
+	RuleUppercase    Rule = "uppercase"
+	RuleDigit        Rule = "digit"
+	RuleSpecial      Rule = "special"
+	RuleBannedWord   Rule = "banned_word"
+	RulePersonalInfo Rule = "personal_info"
+	RuleStrength     Rule = "strength"
+)
+
+const (
+	// MaxStrengthScore is the highest score the strength estimator gives.
+	MaxStrengthScore = 4
+
+	// defaultMinLength is used when the configured minimum length is not set.
+	defaultMinLength = 6
+
+	// minPersonalTokenLength keeps very short name parts, such as initials, from
+	// rejecting almost every password.
+	minPersonalTokenLength = 3
+)
+
+// Violation is a rule a password failed. Param holds the rule's threshold, such as
+// the minimum length, and is empty for rules without one.
+type Violation struct {
+	Rule  Rule
+	Param string
+}
+
+// Policy describes what a password must satisfy. Zero values disable a rule, except
+// MinLength, which always applies.
+type Policy struct {
+	MinLength    int
+	MinLowercase int
+	MinUppercase int
+	MinDigits    int
+	MinSpecial   int
+	// BannedWords are rejected anywhere in the password, ignoring case.
+	BannedWords []string
+	// MinScore is the lowest accepted strength score, from 0 to MaxStrengthScore.
+	MinScore int
+}
+
+// DefaultPolicy returns the policy used when none is configured: at least six
+// characters with a lowercase letter, an uppercase letter, a digit and a symbol.
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:    defaultMinLength,
+		MinLowercase: 1,
+		MinUppercase: 1,
+		MinDigits:    1,
+		MinSpecial:   1,
+	}
+}
+
+// StrongPolicy returns the stricter fixed policy behind the strong_password tag.
+func StrongPolicy() Policy {
+	return Policy{
+		MinLength:    8,
+		MinLowercase: 2,
+		MinUppercase: 2,
+		MinDigits:    2,
+		MinSpecial:   2,
+		BannedWords: []string{
+			"123456", "654321", "abcdef", "fedcba",
+			"qwerty", "asdfgh", "zxcvbn", "password",
+			"admin", "user", "guest", "test",
+		},
+	}
+}
+
+// NewPolicy builds the password policy from configuration. It starts from
+// DefaultPolicy and overrides only the keys that are set explicitly, so a missing
+// key keeps its default instead of disabling the rule.
+func NewPolicy(cfg *config.Config) (Policy, error) {
+	policy := DefaultPolicy()
+
+	overrides := []struct {
+		key    string
+		value  int
+		target *int
+	}{
+		{"PASSWORD_MIN_LENGTH", cfg.PasswordMinLength, &policy.MinLength},
+		{"PASSWORD_MIN_LOWERCASE", cfg.PasswordMinLowercase, &policy.MinLowercase},
+		{"PASSWORD_MIN_UPPERCASE", cfg.PasswordMinUppercase, &policy.MinUppercase},
+		{"PASSWORD_MIN_DIGITS", cfg.PasswordMinDigits, &policy.MinDigits},
+		{"PASSWORD_MIN_SPECIAL", cfg.PasswordMinSpecial, &policy.MinSpecial},
+		{"PASSWORD_MIN_STRENGTH", cfg.PasswordMinStrength, &policy.MinScore},
+	}
+	for _, override := range overrides {
+		if cfg.IsSet(override.key) {
+			*override.target = override.value
+		}
+	}
+
+	for _, word := range cfg.PasswordBannedWords {
+		if word = strings.TrimSpace(word); word != "" {
+			policy.BannedWords = append(policy.BannedWords, word)
+		}
+	}
+
+	if policy.MinLength < 0 || policy.MinLowercase < 0 || policy.MinUppercase < 0 || policy.MinDigits < 0 || policy.MinSpecial < 0 {
+		return Policy{}, fmt.Errorf("password policy minimums must not be negative")
+	}
+	if policy.MinScore < 0 || policy.MinScore > MaxStrengthScore {
+		return Policy{}, fmt.Errorf("password minimum strength must be between 0 and %d, got %d", MaxStrengthScore, policy.MinScore)
+	}
+
+	return policy, nil
+}
+
+// Check returns every rule the password fails, in a fixed order, or nil when it
+// satisfies the policy. personalInfo holds the account's own details, such as its
+// name and email address, which must not appear in the password.
+func (p Policy) Check(password string, personalInfo ...string) []Violation {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, Violation{Rule: RuleMinLength, Param: strconv.Itoa(p.MinLength)})
+	}
+
+	var lower, upper, digits, special int
+	for _, char := range password {
+		switch {
+		case unicode.IsLower(char):
+			lower++
+		case unicode.IsUpper(char):
+			upper++
+		case unicode.IsNumber(char):
+			digits++
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			special++
+		}
+	}
+
+	classes := []struct {
+		rule  Rule
+		count int
+		min   int
+	}{
+		{RuleLowercase, lower, p.MinLowercase},
+		{RuleUppercase, upper, p.MinUppercase},
+		{RuleDigit, digits, p.MinDigits},
+		{RuleSpecial, special, p.MinSpecial},
+	}
+	for _, class := range classes {
+		if class.count < class.min {
+			violations = append(violations, Violation{Rule: class.rule, Param: strconv.Itoa(class.min)})
+		}
+	}
+
+	lowerPassword := strings.ToLower(password)
+	if containsAny(lowerPassword, p.BannedWords) {
+		violations = append(violations, Violation{Rule: RuleBannedWord})
+	}
+
+	personalTokens := personalInfoTokens(personalInfo)
+	if containsAny(lowerPassword, personalTokens) {
+		violations = append(violations, Violation{Rule: RulePersonalInfo})
+	}
+
+	if p.MinScore > 0 && Strength(password, append(personalTokens, p.BannedWords...)...) < p.MinScore {
+		violations = append(violations, Violation{Rule: RuleStrength, Param: strconv.Itoa(p.MinScore)})
+	}
+
+	return violations
+}
+
+// Strength estimates how hard the password is to guess on a zxcvbn scale from 0
+// (trivial) to MaxStrengthScore (very strong). userInputs are words an attacker
+// would try first, such as the user's name.
+func Strength(password string, userInputs ...string) int {
+	return zxcvbn.PasswordStrength(password, userInputs).Score
+}
+
+// personalInfoTokens splits names and email addresses into lowercase words. Only the
+// local part of an email address is used, since the domain is usually shared.
+func personalInfoTokens(personalInfo []string) []string {
+	var tokens []string
+	for _, info := range personalInfo {
+		if at := strings.LastIndex(info, "@"); at >= 0 {
+			info = info[:at]
+		}
+
+		words := strings.FieldsFunc(strings.ToLower(info), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, word := range words {
+			if utf8.RuneCountInString(word) >= minPersonalTokenLength {
+				tokens = append(tokens, word)
+			}
+		}
+	}
+	return tokens
+}
+
+func containsAny(lowerPassword string, words []string) bool {
+	for _, word := range words {
+		if word != "" && strings.Contains(lowerPassword, strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/sammidev/goca/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func violatedRules(violations []Violation) []Rule {
+	rules := make([]Rule, 0, len(violations))
+	for _, violation := range violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestPolicyCheck(t *testing.T) {
+	Convey("Diberikan kebijakan password default", t, func() {
+		policy := DefaultPolicy()
+
+		Convey("Ketika password memenuhi semua aturan", func() {
+			Convey("Maka tidak ada pelanggaran", func() {
+				So(policy.Check("Password123@"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("Ketika password terlalu pendek dan tanpa huruf besar maupun simbol", func() {
+			violations := policy.Check("abc1")
+
+			Convey("Maka setiap aturan yang gagal dilaporkan", func() {
+				So(violatedRules(violations), ShouldResemble, []Rule{RuleMinLength, RuleUppercase, RuleSpecial})
+				So(violations[0].Param, ShouldEqual, "6")
+			})
+		})
+
+		Convey("Ketika panjang password dihitung", func() {
+			Convey("Maka karakter multibyte dihitung sebagai satu karakter", func() {
+				So(violatedRules(policy.Check("Ää1@ä")), ShouldContain, RuleMinLength)
+				So(violatedRules(policy.Check("Ää1@äö")), ShouldNotContain, RuleMinLength)
+			})
+		})
+
+		Convey("Ketika password memuat nama atau email pengguna", func() {
+			violations := policy.Check("Sammi2024!", "sammi.aldhi@example.com", "Sammi")
+
+			Convey("Maka aturan data pribadi dilanggar", func() {
+				So(violatedRules(violations), ShouldResemble, []Rule{RulePersonalInfo})
+			})
+		})
+
+		Convey("Ketika password hanya memuat domain email atau inisial", func() {
+			Convey("Maka aturan data pribadi tidak dilanggar", func() {
+				So(policy.Check("Example-Jo9", "jo@example.com"), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Diberikan kebijakan dengan kata terlarang dan skor kekuatan minimal", t, func() {
+		policy := Policy{
+			MinLength:   8,
+			BannedWords: []string{"Goca"},
+			MinScore:    3,
+		}
+
+		Convey("Ketika password memuat kata terlarang dengan huruf berbeda", func() {
+			Convey("Maka aturan kata terlarang dilanggar", func() {
+				So(violatedRules(policy.Check("myGOCAaccount-x7Qp!")), ShouldContain, RuleBannedWord)
+			})
+		})
+
+		Convey("Ketika password mudah ditebak", func() {
+			violations := policy.Check("password1")
+
+			Convey("Maka aturan kekuatan dilanggar", func() {
+				So(violatedRules(violations), ShouldContain, RuleStrength)
+			})
+		})
+
+		Convey("Ketika password acak dan panjang", func() {
+			Convey("Maka tidak ada pelanggaran", func() {
+				So(policy.Check("v8#Lq2!xTz9@Wm4k"), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Diberikan kebijakan password kuat", t, func() {
+		policy := StrongPolicy()
+
+		Convey("Ketika password memuat pola umum", func() {
+			Convey("Maka aturan kata terlarang dilanggar", func() {
+				So(violatedRules(policy.Check("AAbb11@@qwerty")), ShouldResemble, []Rule{RuleBannedWord})
+			})
+		})
+	})
+}
+
+func TestNewPolicy(t *testing.T) {
+	Convey("Diberikan konfigurasi kebijakan password", t, func() {
+		Convey("Ketika aturan kebijakan tidak diisi", func() {
+			policy, err := NewPolicy(&config.Config{PasswordBannedWords: []string{" goca ", ""}})
+			So(err, ShouldBeNil)
+
+			Convey("Maka kebijakan bawaan dipakai", func() {
+				expected := DefaultPolicy()
+				expected.BannedWords = policy.BannedWords
+				So(policy, ShouldResemble, expected)
+			})
+
+			Convey("Maka kata terlarang kosong diabaikan", func() {
+				So(policy.BannedWords, ShouldResemble, []string{"goca"})
+			})
+		})
+
+		Convey("Ketika sebagian aturan diisi secara eksplisit", func() {
+			cfg := (&config.Config{PasswordMinLength: 12, PasswordMinSpecial: 0}).
+				MarkSet("PASSWORD_MIN_LENGTH", "PASSWORD_MIN_SPECIAL")
+			policy, err := NewPolicy(cfg)
+			So(err, ShouldBeNil)
+
+			Convey("Maka hanya aturan tersebut yang berubah", func() {
+				So(policy.MinLength, ShouldEqual, 12)
+				So(policy.MinSpecial, ShouldEqual, 0)
+				So(policy.MinLowercase, ShouldEqual, DefaultPolicy().MinLowercase)
+				So(policy.MinUppercase, ShouldEqual, DefaultPolicy().MinUppercase)
+				So(policy.MinDigits, ShouldEqual, DefaultPolicy().MinDigits)
+			})
+		})
+
+		Convey("Ketika skor kekuatan minimal di luar rentang", func() {
+			cfg := (&config.Config{PasswordMinStrength: MaxStrengthScore + 1}).MarkSet("PASSWORD_MIN_STRENGTH")
+			_, err := NewPolicy(cfg)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Ketika jumlah minimal bernilai negatif", func() {
+			cfg := (&config.Config{PasswordMinDigits: -1}).MarkSet("PASSWORD_MIN_DIGITS")
+			_, err := NewPolicy(cfg)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestStrength(t *testing.T) {
+	Convey("Diberikan estimator kekuatan password", t, func() {
+		Convey("Ketika password umum dinilai", func() {
+			Convey("Maka skornya rendah", func() {
+				So(Strength("password"), ShouldEqual, 0)
+			})
+		})
+
+		Convey("Ketika password memuat data pengguna", func() {
+			Convey("Maka skornya tidak lebih tinggi dari tanpa data pengguna", func() {
+				So(Strength("sammialdhi", "sammi", "aldhi"), ShouldBeLessThanOrEqualTo, Strength("sammialdhi"))
+			})
+		})
+
+		Convey("Ketika password acak dan panjang", func() {
+			Convey("Maka skornya maksimal", func() {
+				So(Strength("v8#Lq2!xTz9@Wm4k"), ShouldEqual, MaxStrengthScore)
+			})
+		})
+	})
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseUUIDParam(t *testing.T) {
+	Convey("Testing ParseUUIDParam", t, func() {
+		var parsed uuid.UUID
+		var parseErr error
+
+		app := fiber.New()
+		app.Get("/items/:id?", func(c *fiber.Ctx) error {
+			parsed, parseErr = ParseUUIDParam(c, "id")
+			return nil
+		})
+
+		Convey("A valid UUID is parsed", func() {
+			id := uuid.New()
+			_, err := app.Test(httptest.NewRequest("GET", "/items/"+id.String(), nil))
+			So(err, ShouldBeNil)
+			So(parseErr, ShouldBeNil)
+			So(parsed, ShouldEqual, id)
+		})
+
+		Convey("A malformed UUID is rejected", func() {
+			_, err := app.Test(httptest.NewRequest("GET", "/items/not-a-uuid", nil))
+			So(err, ShouldBeNil)
+			So(parseErr, ShouldNotBeNil)
+			So(parsed, ShouldEqual, uuid.Nil)
+		})
+
+		Convey("A missing param is rejected", func() {
+			_, err := app.Test(httptest.NewRequest("GET", "/items", nil))
+			So(err, ShouldBeNil)
+			So(parseErr, ShouldNotBeNil)
+		})
+	})
+}
@@ -2,7 +2,9 @@ package response
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sammidev/goca/internal/config"
@@ -58,6 +60,10 @@ func HandleErrorAPI(c *fiber.Ctx, err error) error {
 		statusCode = appErr.HTTPStatusCode()
 		responseErrorCode = appErr.Code
 		responseErrorMessage = appErr.Message
+
+		if appErr.RetryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
 	}
 
 	resp := NewErrorResponse(responseErrorMessage, responseErrorCode.String(), opts...)
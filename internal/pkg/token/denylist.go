@@ -0,0 +1,139 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
+	"github.com/sammidev/goca/internal/pkg/cache"
+)
+
+// Denylist menyimpan token yang dicabut sebelum masa berlakunya habis.
+type Denylist interface {
+	// RevokeToken mencabut satu token berdasarkan ID (jti) hingga token tersebut kedaluwarsa.
+	RevokeToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error
+	// ClaimToken mencabut satu token seperti RevokeToken dan melaporkan apakah token
+	// belum dicabut sebelumnya. Dari beberapa pemanggil bersamaan hanya satu yang
+	// mendapat true, sehingga cocok untuk tautan sekali pakai.
+	ClaimToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) (bool, error)
+	// RevokeAllForUser mencabut semua token milik user yang diterbitkan sebelum saat ini.
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+	// RevokeSession mencabut semua token yang terikat ke satu sesi login.
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+	// IsRevoked memeriksa apakah payload berasal dari token yang sudah dicabut.
+	IsRevoked(ctx context.Context, payload *Payload) (bool, error)
+}
+
+const (
+	denylistTokenKeyPrefix   = "token_denylist:jti"
+	denylistUserKeyPrefix    = "token_denylist:user"
+	denylistSessionKeyPrefix = "token_denylist:session"
+)
+
+// CacheDenylist adalah implementasi Denylist yang disimpan di cache (Redis).
+type CacheDenylist struct {
+	cache cache.Cache
+	// userTTL adalah umur maksimum access token, sehingga penanda "issued before"
+	// per user dan penanda sesi bisa dihapus setelah semua token lama pasti kedaluwarsa.
+	userTTL time.Duration
+}
+
+var _ Denylist = (*CacheDenylist)(nil)
+
+func NewCacheDenylist(cache cache.Cache, cfg *config.Config) *CacheDenylist {
+	return &CacheDenylist{
+		cache:   cache,
+		userTTL: max(cfg.AuthAccessTokenExpiry, cfg.AuthAccessTokenExpiryExtended),
+	}
+}
+
+func (d *CacheDenylist) RevokeToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.cache.Set(ctx, tokenKey(tokenID), 1, ttl)
+}
+
+func (d *CacheDenylist) ClaimToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+	return d.cache.SetNX(ctx, tokenKey(tokenID), 1, ttl)
+}
+
+// RevokeAllForUser menyimpan UUIDv7 baru sebagai batas pencabutan. ID token juga
+// UUIDv7, sehingga token yang terbit sesaat sebelum atau sesudah pencabutan tetap
+// bisa dibedakan, bahkan dalam detik atau milidetik yang sama.
+func (d *CacheDenylist) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return d.cache.Set(ctx, userKey(userID), uuid.Must(uuid.NewV7()).String(), d.userTTL)
+}
+
+func (d *CacheDenylist) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	return d.cache.Set(ctx, sessionKey(sessionID), 1, d.userTTL)
+}
+
+func (d *CacheDenylist) IsRevoked(ctx context.Context, payload *Payload) (bool, error) {
+	if _, err := d.cache.Get(ctx, tokenKey(payload.ID)); err == nil {
+		return true, nil
+	} else if !cache.IsNotFound(err) {
+		return false, err
+	}
+
+	if payload.SessionID != uuid.Nil {
+		if _, err := d.cache.Get(ctx, sessionKey(payload.SessionID)); err == nil {
+			return true, nil
+		} else if !cache.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	value, err := d.cache.Get(ctx, userKey(payload.UserID))
+	if err != nil {
+		if cache.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return issuedBefore(payload, fmt.Sprint(value))
+}
+
+// issuedBefore memeriksa apakah token terbit sebelum batas dari RevokeAllForUser.
+// Token ber-ID UUIDv7 dibandingkan lewat komponen waktu ID-nya; token lama dengan ID
+// acak dibandingkan lewat waktu terbitnya. Batas lama berupa detik Unix tetap dibaca
+// sampai kedaluwarsa.
+func issuedBefore(payload *Payload, marker string) (bool, error) {
+	revokedBefore, err := uuid.Parse(marker)
+	if err != nil {
+		seconds, err := strconv.ParseInt(marker, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return payload.IssuedAt.Unix() < seconds, nil
+	}
+
+	if payload.ID.Version() == 7 {
+		return bytes.Compare(payload.ID[:8], revokedBefore[:8]) < 0, nil
+	}
+
+	sec, nsec := revokedBefore.Time().UnixTime()
+	return payload.IssuedAt.Before(time.Unix(sec, nsec)), nil
+}
+
+func tokenKey(tokenID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", denylistTokenKeyPrefix, tokenID)
+}
+
+func sessionKey(sessionID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", denylistSessionKeyPrefix, sessionID)
+}
+
+func userKey(userID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", denylistUserKeyPrefix, userID)
+}
@@ -0,0 +1,235 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sammidev/goca/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// memoryCache adalah implementasi cache.Cache di memori untuk pengujian.
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string]any
+	err    error
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string]any)}
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value any, exp time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	value, ok := m.values[key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return value, nil
+}
+
+func (m *memoryCache) GetDelete(ctx context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	value, ok := m.values[key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	delete(m.values, key)
+	return value, nil
+}
+
+func (m *memoryCache) SetNX(ctx context.Context, key string, value any, exp time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.values[key]; ok {
+		return false, nil
+	}
+	m.values[key] = value
+	return true, nil
+}
+
+func (m *memoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memoryCache) Increment(ctx context.Context, key string, exp time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, _ := m.values[key].(int64)
+	value++
+	m.values[key] = value
+	return value, nil
+}
+
+func (m *memoryCache) Close() error { return nil }
+
+func TestCacheDenylist(t *testing.T) {
+	cfg := &config.Config{
+		AuthAccessTokenExpiry:         30 * time.Minute,
+		AuthAccessTokenExpiryExtended: 24 * time.Hour,
+	}
+
+	Convey("Given a cache backed denylist", t, func() {
+		ctx := context.Background()
+		store := newMemoryCache()
+		denylist := NewCacheDenylist(store, cfg)
+
+		payload := &Payload{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			IssuedAt:  time.Now().Add(-time.Minute),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		Convey("A token that was never revoked is accepted", func() {
+			revoked, err := denylist.IsRevoked(ctx, payload)
+			So(err, ShouldBeNil)
+			So(revoked, ShouldBeFalse)
+		})
+
+		Convey("A revoked token ID is rejected", func() {
+			So(denylist.RevokeToken(ctx, payload.ID, payload.ExpiresAt), ShouldBeNil)
+
+			revoked, err := denylist.IsRevoked(ctx, payload)
+			So(err, ShouldBeNil)
+			So(revoked, ShouldBeTrue)
+		})
+
+		Convey("A token can only be claimed once", func() {
+			claimed, err := denylist.ClaimToken(ctx, payload.ID, payload.ExpiresAt)
+			So(err, ShouldBeNil)
+			So(claimed, ShouldBeTrue)
+
+			claimed, err = denylist.ClaimToken(ctx, payload.ID, payload.ExpiresAt)
+			So(err, ShouldBeNil)
+			So(claimed, ShouldBeFalse)
+
+			revoked, err := denylist.IsRevoked(ctx, payload)
+			So(err, ShouldBeNil)
+			So(revoked, ShouldBeTrue)
+		})
+
+		Convey("Revoking an already expired token is a no-op", func() {
+			So(denylist.RevokeToken(ctx, payload.ID, time.Now().Add(-time.Second)), ShouldBeNil)
+			So(store.values, ShouldBeEmpty)
+		})
+
+		Convey("Revoking all tokens of a user", func() {
+			So(denylist.RevokeAllForUser(ctx, payload.UserID), ShouldBeNil)
+
+			Convey("Rejects tokens issued before the revocation", func() {
+				revoked, err := denylist.IsRevoked(ctx, payload)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeTrue)
+			})
+
+			Convey("Accepts tokens issued afterwards", func() {
+				fresh := *payload
+				fresh.ID = uuid.New()
+				fresh.IssuedAt = time.Now().Add(time.Second)
+
+				revoked, err := denylist.IsRevoked(ctx, &fresh)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeFalse)
+			})
+
+			Convey("Rejects a token issued just before the revocation", func() {
+				store.values = make(map[string]any)
+				recent := *payload
+				recent.ID = uuid.Must(uuid.NewV7())
+				recent.IssuedAt = time.Now().Truncate(time.Second)
+				So(denylist.RevokeAllForUser(ctx, payload.UserID), ShouldBeNil)
+
+				revoked, err := denylist.IsRevoked(ctx, &recent)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeTrue)
+			})
+
+			Convey("Accepts a token issued just after the revocation", func() {
+				fresh := *payload
+				fresh.ID = uuid.Must(uuid.NewV7())
+				fresh.IssuedAt = time.Now().Truncate(time.Second)
+
+				revoked, err := denylist.IsRevoked(ctx, &fresh)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeFalse)
+			})
+
+			Convey("Does not affect other users", func() {
+				other := *payload
+				other.UserID = uuid.New()
+
+				revoked, err := denylist.IsRevoked(ctx, &other)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeFalse)
+			})
+		})
+
+		Convey("Revoking a session", func() {
+			payload.SessionID = uuid.New()
+			So(denylist.RevokeSession(ctx, payload.SessionID), ShouldBeNil)
+
+			Convey("Rejects tokens bound to that session", func() {
+				revoked, err := denylist.IsRevoked(ctx, payload)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeTrue)
+			})
+
+			Convey("Accepts tokens of other sessions", func() {
+				other := *payload
+				other.SessionID = uuid.New()
+
+				revoked, err := denylist.IsRevoked(ctx, &other)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeFalse)
+			})
+
+			Convey("Accepts tokens without a session", func() {
+				legacy := *payload
+				legacy.SessionID = uuid.Nil
+
+				revoked, err := denylist.IsRevoked(ctx, &legacy)
+				So(err, ShouldBeNil)
+				So(revoked, ShouldBeFalse)
+			})
+		})
+
+		Convey("A revocation stored in Unix seconds is still honoured", func() {
+			So(store.Set(ctx, userKey(payload.UserID), time.Now().Unix(), time.Hour), ShouldBeNil)
+
+			revoked, err := denylist.IsRevoked(ctx, payload)
+			So(err, ShouldBeNil)
+			So(revoked, ShouldBeTrue)
+		})
+
+		Convey("Cache failures are reported instead of accepting the token", func() {
+			store.err = errors.New("connection refused")
+
+			_, err := denylist.IsRevoked(ctx, payload)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
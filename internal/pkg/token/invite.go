@@ -0,0 +1,35 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/sammidev/goca/internal/pkg/random"
+)
+
+const (
+	// InviteTokenPrefix membuat token undangan mudah dibedakan dari token lain dan
+	// mudah dikenali oleh secret scanner.
+	InviteTokenPrefix = "goca_inv_"
+	inviteTokenLength = 40
+)
+
+// GenerateInviteToken membuat token undangan baru. Nilai token hanya dikirim lewat
+// email ke penerima undangan, yang disimpan adalah hash-nya.
+func GenerateInviteToken() (value, hash string, err error) {
+	secret, err := random.String(inviteTokenLength)
+	if err != nil {
+		return "", "", err
+	}
+
+	value = InviteTokenPrefix + secret
+	return value, HashInviteToken(value), nil
+}
+
+// HashInviteToken menghitung hash SHA-256 dari token undangan untuk mencarinya di
+// database. Seperti personal access token, token dibuat acak dengan entropi tinggi
+// sehingga hash yang cepat sudah cukup.
+func HashInviteToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,31 @@
+package token
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInviteToken(t *testing.T) {
+	Convey("Given invite token helpers", t, func() {
+		Convey("When generating a token", func() {
+			value, hash, err := GenerateInviteToken()
+
+			Convey("Then it should carry the prefix and match its hash", func() {
+				So(err, ShouldBeNil)
+				So(strings.HasPrefix(value, InviteTokenPrefix), ShouldBeTrue)
+				So(len(value), ShouldEqual, len(InviteTokenPrefix)+inviteTokenLength)
+				So(hash, ShouldEqual, HashInviteToken(value))
+				So(hash, ShouldNotContainSubstring, value)
+			})
+
+			Convey("Then another token should be different", func() {
+				other, otherHash, err := GenerateInviteToken()
+				So(err, ShouldBeNil)
+				So(other, ShouldNotEqual, value)
+				So(otherHash, ShouldNotEqual, hash)
+			})
+		})
+	})
+}
@@ -32,22 +32,17 @@ var _ Token = (*JWT)(nil)
 // Dibuat unexported karena ini adalah detail implementasi.
 type customClaims struct {
 	jwt.RegisteredClaims
-	UserID uuid.UUID `json:"user_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	TokenType TokenType `json:"token_type"`
+	SessionID string    `json:"sid,omitempty"`
+	// Permissions disingkat agar ukuran token tetap kecil.
+	Permissions []string `json:"perms,omitempty"`
 }
 
-func (j *JWT) GenerateToken(userID uuid.UUID, exp time.Duration) (*GenerateTokenResponse, error) {
-	now := time.Now()
-	expiration := now.Add(exp)
-
-	claims := &customClaims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        userID.String(),
-			Issuer:    j.issuer,
-			ExpiresAt: jwt.NewNumericDate(expiration),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-		},
+func (j *JWT) GenerateToken(userID uuid.UUID, tokenType TokenType, exp time.Duration, opts ...GenerateOption) (*GenerateTokenResponse, error) {
+	claims, err := newCustomClaims(j.issuer, userID, tokenType, exp, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -56,10 +51,7 @@ func (j *JWT) GenerateToken(userID uuid.UUID, exp time.Duration) (*GenerateToken
 		return nil, err
 	}
 
-	return &GenerateTokenResponse{
-		Value:     signedToken,
-		ExpiresAt: expiration,
-	}, nil
+	return newGenerateTokenResponse(claims, signedToken), nil
 }
 
 func (j *JWT) VerifyBearerToken(bearerToken string) (*Payload, error) {
@@ -67,17 +59,65 @@ func (j *JWT) VerifyBearerToken(bearerToken string) (*Payload, error) {
 	if err != nil {
 		return nil, err
 	}
-	return j.VerifyToken(tokenStr)
+	return j.VerifyToken(tokenStr, TokenTypeAccess)
 }
 
-func (j *JWT) VerifyToken(tokenStr string) (*Payload, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &customClaims{}, func(token *jwt.Token) (any, error) {
+func (j *JWT) VerifyToken(tokenStr string, tokenType TokenType) (*Payload, error) {
+	return parseCustomClaims(tokenStr, j.issuer, tokenType, func(token *jwt.Token) (any, error) {
 		// Memastikan algoritma penandatanganan adalah HMAC
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("algoritma signing tidak terduga: %v", token.Header["alg"])
 		}
 		return j.secretKey, nil
 	})
+}
+
+func (j *JWT) extractBearerToken(authHeader string) (string, error) {
+	return extractBearerToken(authHeader)
+}
+
+// newCustomClaims menyiapkan claims untuk token baru dengan jti acak.
+func newCustomClaims(issuer string, userID uuid.UUID, tokenType TokenType, exp time.Duration, opts []GenerateOption) (*customClaims, error) {
+	if !tokenType.IsValid() {
+		return nil, ErrInvalidTokenType
+	}
+
+	now := time.Now()
+	options := newGenerateOptions(opts)
+
+	claims := &customClaims{
+		UserID:    userID,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.Must(uuid.NewV7()).String(),
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{tokenType.Audience()},
+			ExpiresAt: jwt.NewNumericDate(now.Add(exp)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+	if options.sessionID != uuid.Nil {
+		claims.SessionID = options.sessionID.String()
+	}
+	claims.Permissions = options.permissions
+
+	return claims, nil
+}
+
+func newGenerateTokenResponse(claims *customClaims, signedToken string) *GenerateTokenResponse {
+	return &GenerateTokenResponse{
+		ID:        uuid.MustParse(claims.ID),
+		Value:     signedToken,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+}
+
+// parseCustomClaims memverifikasi tanda tangan, issuer, audience dan tipe token,
+// lalu mengubah claims menjadi Payload.
+func parseCustomClaims(tokenStr, issuer string, tokenType TokenType, keyFunc jwt.Keyfunc) (*Payload, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &customClaims{}, keyFunc,
+		jwt.WithIssuer(issuer), jwt.WithAudience(tokenType.Audience()))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -87,17 +127,40 @@ func (j *JWT) VerifyToken(tokenStr string) (*Payload, error) {
 	}
 
 	claims, ok := token.Claims.(*customClaims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || claims.IssuedAt == nil || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.TokenType != tokenType {
+		return nil, ErrInvalidTokenType
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
 		return nil, ErrInvalidToken
 	}
 
+	var sessionID uuid.UUID
+	if claims.SessionID != "" {
+		sessionID, err = uuid.Parse(claims.SessionID)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+
 	return &Payload{
-		UserID:    claims.UserID,
-		ExpiresAt: claims.ExpiresAt.Time,
+		ID:          tokenID,
+		UserID:      claims.UserID,
+		Type:        claims.TokenType,
+		Audience:    tokenType.Audience(),
+		IssuedAt:    claims.IssuedAt.Time,
+		ExpiresAt:   claims.ExpiresAt.Time,
+		SessionID:   sessionID,
+		Permissions: claims.Permissions,
 	}, nil
 }
 
-func (j *JWT) extractBearerToken(authHeader string) (string, error) {
+func extractBearerToken(authHeader string) (string, error) {
 	if authHeader == "" {
 		return "", ErrInvalidTokenFormat
 	}
@@ -0,0 +1,81 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
+)
+
+// AsymmetricJWT menandatangani token dengan private key dari KeyRing (RS256, ES256
+// atau EdDSA). Header kid menunjukkan kunci mana yang dipakai sehingga token lama
+// tetap bisa diverifikasi selama kuncinya masih ada di key ring.
+type AsymmetricJWT struct {
+	keyRing *KeyRing
+	issuer  string
+}
+
+func NewAsymmetricJWT(cfg *config.Config, keyRing *KeyRing) *AsymmetricJWT {
+	return &AsymmetricJWT{
+		keyRing: keyRing,
+		issuer:  cfg.AppName,
+	}
+}
+
+var (
+	_ Token          = (*AsymmetricJWT)(nil)
+	_ KeySetProvider = (*AsymmetricJWT)(nil)
+)
+
+func (j *AsymmetricJWT) GenerateToken(userID uuid.UUID, tokenType TokenType, exp time.Duration, opts ...GenerateOption) (*GenerateTokenResponse, error) {
+	claims, err := newCustomClaims(j.issuer, userID, tokenType, exp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	key := j.keyRing.Active()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+
+	signedToken, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGenerateTokenResponse(claims, signedToken), nil
+}
+
+func (j *AsymmetricJWT) VerifyBearerToken(bearerToken string) (*Payload, error) {
+	tokenStr, err := extractBearerToken(bearerToken)
+	if err != nil {
+		return nil, err
+	}
+	return j.VerifyToken(tokenStr, TokenTypeAccess)
+}
+
+func (j *AsymmetricJWT) VerifyToken(tokenStr string, tokenType TokenType) (*Payload, error) {
+	return parseCustomClaims(tokenStr, j.issuer, tokenType, func(token *jwt.Token) (any, error) {
+		keyID, ok := token.Header["kid"].(string)
+		if !ok || keyID == "" {
+			return nil, fmt.Errorf("header kid tidak ditemukan")
+		}
+
+		key, ok := j.keyRing.Get(keyID)
+		if !ok {
+			return nil, fmt.Errorf("kunci %q tidak dikenal", keyID)
+		}
+
+		// Algoritma di header harus sama dengan algoritma kunci agar tidak bisa ditukar
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("algoritma signing tidak terduga: %v", token.Header["alg"])
+		}
+		return key.PublicKey, nil
+	})
+}
+
+// JWKS mengembalikan public key yang masih diterima untuk verifikasi.
+func (j *AsymmetricJWT) JWKS() *JSONWebKeySet {
+	return j.keyRing.JWKS()
+}
@@ -0,0 +1,131 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAsymmetricJWT(t *testing.T) {
+	cfg := &config.Config{AppName: "test-app"}
+
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		Convey("Given an asymmetric JWT instance using "+algorithm, t, func() {
+			current := newTestSigningKey(t, "current", algorithm)
+			previous := newTestSigningKey(t, "previous", algorithm)
+
+			ring, err := NewKeyRing(current.ID, current, previous)
+			So(err, ShouldBeNil)
+			jwtService := NewAsymmetricJWT(cfg, ring)
+
+			userID := uuid.New()
+
+			Convey("When generating a token", func() {
+				resp, err := jwtService.GenerateToken(userID, TokenTypeAccess, time.Hour)
+				So(err, ShouldBeNil)
+
+				Convey("Then it is signed with the active key and carries its kid", func() {
+					parsed, _, err := jwt.NewParser().ParseUnverified(resp.Value, &customClaims{})
+					So(err, ShouldBeNil)
+					So(parsed.Header["kid"], ShouldEqual, current.ID)
+					So(parsed.Header["alg"], ShouldEqual, algorithm)
+				})
+
+				Convey("Then it can be verified", func() {
+					payload, err := jwtService.VerifyBearerToken("Bearer " + resp.Value)
+					So(err, ShouldBeNil)
+					So(payload.ID, ShouldEqual, resp.ID)
+					So(payload.UserID, ShouldEqual, userID)
+					So(payload.Type, ShouldEqual, TokenTypeAccess)
+				})
+
+				Convey("Then it is rejected as another token type", func() {
+					_, err := jwtService.VerifyToken(resp.Value, TokenTypeReauth)
+					So(err, ShouldEqual, ErrInvalidTokenType)
+				})
+
+				Convey("Then it is rejected once its key leaves the ring", func() {
+					rotated, err := NewKeyRing(previous.ID, previous)
+					So(err, ShouldBeNil)
+
+					_, err = NewAsymmetricJWT(cfg, rotated).VerifyToken(resp.Value, TokenTypeAccess)
+					So(err, ShouldEqual, ErrInvalidToken)
+				})
+			})
+
+			Convey("When the active key is rotated", func() {
+				old, err := jwtService.GenerateToken(userID, TokenTypeAccess, time.Hour)
+				So(err, ShouldBeNil)
+
+				rotated, err := NewKeyRing(previous.ID, current, previous)
+				So(err, ShouldBeNil)
+				rotatedService := NewAsymmetricJWT(cfg, rotated)
+
+				Convey("Then tokens signed with the old key stay valid", func() {
+					payload, err := rotatedService.VerifyToken(old.Value, TokenTypeAccess)
+					So(err, ShouldBeNil)
+					So(payload.UserID, ShouldEqual, userID)
+				})
+
+				Convey("Then new tokens are signed with the new key", func() {
+					resp, err := rotatedService.GenerateToken(userID, TokenTypeAccess, time.Hour)
+					So(err, ShouldBeNil)
+
+					parsed, _, err := jwt.NewParser().ParseUnverified(resp.Value, &customClaims{})
+					So(err, ShouldBeNil)
+					So(parsed.Header["kid"], ShouldEqual, previous.ID)
+
+					_, err = jwtService.VerifyToken(resp.Value, TokenTypeAccess)
+					So(err, ShouldBeNil)
+				})
+			})
+
+			Convey("When verifying a forged token", func() {
+				Convey("Then a token without kid is rejected", func() {
+					claims, err := newCustomClaims(cfg.AppName, userID, TokenTypeAccess, time.Hour, nil)
+					So(err, ShouldBeNil)
+					signed, err := jwt.NewWithClaims(jwt.GetSigningMethod(algorithm), claims).SignedString(current.PrivateKey)
+					So(err, ShouldBeNil)
+
+					_, err = jwtService.VerifyToken(signed, TokenTypeAccess)
+					So(err, ShouldEqual, ErrInvalidToken)
+				})
+
+				Convey("Then an HS256 token using the kid of a known key is rejected", func() {
+					claims, err := newCustomClaims(cfg.AppName, userID, TokenTypeAccess, time.Hour, nil)
+					So(err, ShouldBeNil)
+					token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+					token.Header["kid"] = current.ID
+					signed, err := token.SignedString([]byte(strings.Repeat("x", 32)))
+					So(err, ShouldBeNil)
+
+					_, err = jwtService.VerifyToken(signed, TokenTypeAccess)
+					So(err, ShouldEqual, ErrInvalidToken)
+				})
+
+				Convey("Then a token signed by an unknown key with a known kid is rejected", func() {
+					stranger := newTestSigningKey(t, current.ID, algorithm)
+					strangerRing, err := NewKeyRing(stranger.ID, stranger)
+					So(err, ShouldBeNil)
+
+					resp, err := NewAsymmetricJWT(cfg, strangerRing).GenerateToken(userID, TokenTypeAccess, time.Hour)
+					So(err, ShouldBeNil)
+
+					_, err = jwtService.VerifyToken(resp.Value, TokenTypeAccess)
+					So(err, ShouldEqual, ErrInvalidToken)
+				})
+			})
+
+			Convey("When publishing the JWKS", func() {
+				Convey("Then it contains every key in the ring", func() {
+					So(jwtService.JWKS().Keys, ShouldHaveLength, 2)
+				})
+			})
+		})
+	}
+}
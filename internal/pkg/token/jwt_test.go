@@ -29,7 +29,7 @@ func TestJWT(t *testing.T) {
 
 		Convey("When generating a token", func() {
 			Convey("Then it should generate a valid token with correct payload", func() {
-				resp, err := jwtService.GenerateToken(userID, expDuration)
+				resp, err := jwtService.GenerateToken(userID, TokenTypeAccess, expDuration)
 				So(err, ShouldBeNil)
 				So(resp, ShouldNotBeNil)
 				So(resp.Value, ShouldNotBeEmpty)
@@ -45,11 +45,33 @@ func TestJWT(t *testing.T) {
 				So(token.Valid, ShouldBeTrue)
 				So(claims.UserID, ShouldEqual, userID)
 				So(claims.Issuer, ShouldEqual, cfg.AppName)
+				So(claims.TokenType, ShouldEqual, TokenTypeAccess)
+				So(claims.Audience, ShouldResemble, jwt.ClaimStrings{AudienceAPI})
+			})
+
+			Convey("Then it should fail with unknown token type", func() {
+				_, err := jwtService.GenerateToken(userID, TokenType("unknown"), expDuration)
+				So(err, ShouldEqual, ErrInvalidTokenType)
+			})
+
+			Convey("Then every token should get its own unique ID", func() {
+				first, err := jwtService.GenerateToken(userID, TokenTypeAccess, expDuration)
+				So(err, ShouldBeNil)
+				second, err := jwtService.GenerateToken(userID, TokenTypeAccess, expDuration)
+				So(err, ShouldBeNil)
+
+				So(first.ID, ShouldNotEqual, uuid.Nil)
+				So(first.ID, ShouldNotEqual, second.ID)
+				So(first.Value, ShouldNotEqual, second.Value)
+
+				payload, err := jwtService.VerifyToken(first.Value, TokenTypeAccess)
+				So(err, ShouldBeNil)
+				So(payload.ID, ShouldEqual, first.ID)
 			})
 
 			Convey("Then it should fail with invalid userID", func() {
 				invalidUserID := uuid.Nil
-				resp, err := jwtService.GenerateToken(invalidUserID, expDuration)
+				resp, err := jwtService.GenerateToken(invalidUserID, TokenTypeAccess, expDuration)
 				So(err, ShouldBeNil) // Should still generate, as nil UUID is technically valid
 				So(resp, ShouldNotBeNil)
 				So(resp.Value, ShouldNotBeEmpty)
@@ -57,7 +79,7 @@ func TestJWT(t *testing.T) {
 		})
 
 		Convey("When verifying a bearer token", func() {
-			tokenResp, err := jwtService.GenerateToken(userID, expDuration)
+			tokenResp, err := jwtService.GenerateToken(userID, TokenTypeAccess, expDuration)
 			So(err, ShouldBeNil)
 
 			Convey("With valid bearer token", func() {
@@ -87,26 +109,80 @@ func TestJWT(t *testing.T) {
 		})
 
 		Convey("When verifying a token directly", func() {
-			tokenResp, err := jwtService.GenerateToken(userID, expDuration)
+			tokenResp, err := jwtService.GenerateToken(userID, TokenTypeAccess, expDuration)
 			So(err, ShouldBeNil)
 
 			Convey("With valid token", func() {
-				payload, err := jwtService.VerifyToken(tokenResp.Value)
+				payload, err := jwtService.VerifyToken(tokenResp.Value, TokenTypeAccess)
 				So(err, ShouldBeNil)
 				So(payload, ShouldNotBeNil)
 				So(payload.UserID, ShouldEqual, userID)
 				So(payload.ExpiresAt, ShouldHappenOnOrAfter, time.Now())
 			})
 
+			Convey("With a token bound to a session", func() {
+				sessionID := uuid.New()
+				sessionToken, err := jwtService.GenerateToken(userID, TokenTypeAccess, expDuration, WithSessionID(sessionID))
+				So(err, ShouldBeNil)
+
+				payload, err := jwtService.VerifyToken(sessionToken.Value, TokenTypeAccess)
+				So(err, ShouldBeNil)
+				So(payload.SessionID, ShouldEqual, sessionID)
+
+				payload, err = jwtService.VerifyToken(tokenResp.Value, TokenTypeAccess)
+				So(err, ShouldBeNil)
+				So(payload.SessionID, ShouldEqual, uuid.Nil)
+			})
+
+			Convey("With a token carrying permissions", func() {
+				permToken, err := jwtService.GenerateToken(userID, TokenTypeAccess, expDuration, WithPermissions("notes:read:any", "users:read"))
+				So(err, ShouldBeNil)
+
+				payload, err := jwtService.VerifyToken(permToken.Value, TokenTypeAccess)
+				So(err, ShouldBeNil)
+				So(payload.Permissions, ShouldResemble, []string{"notes:read:any", "users:read"})
+				So(payload.HasPermission("users:read"), ShouldBeTrue)
+				So(payload.HasPermission("users:manage"), ShouldBeFalse)
+
+				payload, err = jwtService.VerifyToken(tokenResp.Value, TokenTypeAccess)
+				So(err, ShouldBeNil)
+				So(payload.Permissions, ShouldBeEmpty)
+				So(payload.HasPermission("users:read"), ShouldBeFalse)
+			})
+
+			Convey("With a token of another type", func() {
+				refreshToken, err := jwtService.GenerateToken(userID, TokenTypeRefresh, expDuration)
+				So(err, ShouldBeNil)
+
+				payload, err := jwtService.VerifyToken(refreshToken.Value, TokenTypeRefresh)
+				So(err, ShouldBeNil)
+				So(payload.Type, ShouldEqual, TokenTypeRefresh)
+				So(payload.Audience, ShouldEqual, AudienceAuth)
+
+				_, err = jwtService.VerifyToken(refreshToken.Value, TokenTypeAccess)
+				So(err, ShouldNotBeNil)
+
+				_, err = jwtService.VerifyBearerToken(fmt.Sprintf("Bearer %s", refreshToken.Value))
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("With a token of the same audience but another type", func() {
+				challengeToken, err := jwtService.GenerateToken(userID, TokenTypeMFAChallenge, expDuration)
+				So(err, ShouldBeNil)
+
+				_, err = jwtService.VerifyToken(challengeToken.Value, TokenTypeRefresh)
+				So(err, ShouldEqual, ErrInvalidTokenType)
+			})
+
 			Convey("With expired token", func() {
-				expiredToken, err := jwtService.GenerateToken(userID, -time.Hour)
+				expiredToken, err := jwtService.GenerateToken(userID, TokenTypeAccess, -time.Hour)
 				So(err, ShouldBeNil)
-				_, err = jwtService.VerifyToken(expiredToken.Value)
+				_, err = jwtService.VerifyToken(expiredToken.Value, TokenTypeAccess)
 				So(err, ShouldEqual, ErrExpiredToken)
 			})
 
 			Convey("With invalid token", func() {
-				_, err := jwtService.VerifyToken("invalid.token.string")
+				_, err := jwtService.VerifyToken("invalid.token.string", TokenTypeAccess)
 				So(err, ShouldEqual, ErrInvalidToken)
 			})
 
@@ -119,10 +195,10 @@ func TestJWT(t *testing.T) {
 				otherJWT, err := NewJWT(otherCfg)
 				So(err, ShouldBeNil)
 
-				tokenResp, err := otherJWT.GenerateToken(userID, expDuration)
+				tokenResp, err := otherJWT.GenerateToken(userID, TokenTypeAccess, expDuration)
 				So(err, ShouldBeNil)
 
-				_, err = jwtService.VerifyToken(tokenResp.Value)
+				_, err = jwtService.VerifyToken(tokenResp.Value, TokenTypeAccess)
 				So(err, ShouldEqual, ErrInvalidToken)
 			})
 		})
@@ -0,0 +1,247 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Algoritma penandatanganan asimetris yang didukung.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey adalah satu kunci di dalam key ring. Kunci tanpa PrivateKey hanya
+// dipakai untuk verifikasi, misalnya kunci lama yang sedang dipensiunkan.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// CanSign memeriksa apakah kunci bisa dipakai untuk menandatangani token.
+func (k *SigningKey) CanSign() bool {
+	return k.PrivateKey != nil
+}
+
+// KeyRing menyimpan satu kunci aktif untuk menandatangani token baru dan
+// sejumlah kunci lain yang masih diterima saat verifikasi. Dengan begitu kunci
+// baru bisa dipublikasikan lebih dulu dan kunci lama tetap berlaku sampai semua
+// token yang ditandatanganinya kedaluwarsa.
+type KeyRing struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewKeyRing membuat key ring dengan activeKeyID sebagai kunci penandatangan.
+func NewKeyRing(activeKeyID string, keys ...*SigningKey) (*KeyRing, error) {
+	ring := &KeyRing{
+		keys: make(map[string]*SigningKey, len(keys)),
+	}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("kunci JWT harus memiliki ID")
+		}
+		if _, exists := ring.keys[key.ID]; exists {
+			return nil, fmt.Errorf("ID kunci JWT %q terdaftar lebih dari sekali", key.ID)
+		}
+		if err := checkKeyAlgorithm(key.Algorithm, key.PublicKey); err != nil {
+			return nil, fmt.Errorf("kunci JWT %q: %w", key.ID, err)
+		}
+		ring.keys[key.ID] = key
+	}
+
+	active, ok := ring.keys[activeKeyID]
+	if !ok {
+		return nil, fmt.Errorf("kunci JWT aktif %q tidak ditemukan", activeKeyID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("kunci JWT aktif %q tidak memiliki private key", activeKeyID)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+// LoadKeyRing membaca semua file PEM di dir. Nama file tanpa ekstensi menjadi kid.
+// File berisi private key (PKCS#8) bisa dipakai untuk menandatangani, sedangkan file
+// berisi public key (PKIX) hanya dipakai untuk verifikasi.
+func LoadKeyRing(dir, activeKeyID, algorithm string) (*KeyRing, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca direktori kunci JWT: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("tidak ada kunci JWT di %s", dir)
+	}
+
+	keys := make([]*SigningKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca kunci JWT %s: %w", path, err)
+		}
+
+		key, err := ParseSigningKeyPEM(strings.TrimSuffix(filepath.Base(path), ".pem"), algorithm, data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeyRing(activeKeyID, keys...)
+}
+
+// ParseSigningKeyPEM mengubah blok PEM menjadi SigningKey.
+func ParseSigningKeyPEM(keyID, algorithm string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("kunci JWT %q bukan PEM yang valid", keyID)
+	}
+
+	key := &SigningKey{ID: keyID, Algorithm: algorithm}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca private key JWT %q: %w", keyID, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("private key JWT %q tidak didukung", keyID)
+		}
+		key.PrivateKey = signer
+		key.PublicKey = signer.Public()
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca public key JWT %q: %w", keyID, err)
+		}
+		key.PublicKey = parsed
+	default:
+		return nil, fmt.Errorf("tipe PEM %q pada kunci JWT %q tidak didukung", block.Type, keyID)
+	}
+
+	if err := checkKeyAlgorithm(algorithm, key.PublicKey); err != nil {
+		return nil, fmt.Errorf("kunci JWT %q: %w", keyID, err)
+	}
+
+	return key, nil
+}
+
+// Active mengembalikan kunci yang dipakai untuk menandatangani token baru.
+func (r *KeyRing) Active() *SigningKey {
+	return r.active
+}
+
+// Get mencari kunci berdasarkan kid.
+func (r *KeyRing) Get(keyID string) (*SigningKey, bool) {
+	key, ok := r.keys[keyID]
+	return key, ok
+}
+
+// JWKS mengembalikan semua public key dalam format JSON Web Key Set.
+func (r *KeyRing) JWKS() *JSONWebKeySet {
+	ids := make([]string, 0, len(r.keys))
+	for id := range r.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ids))}
+	for _, id := range ids {
+		set.Keys = append(set.Keys, newJSONWebKey(r.keys[id]))
+	}
+	return set
+}
+
+// JSONWebKeySet adalah dokumen JWKS (RFC 7517).
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey adalah representasi public key dalam format JWK.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// KeySetProvider diimplementasikan oleh Token yang bisa mempublikasikan public key-nya.
+type KeySetProvider interface {
+	JWKS() *JSONWebKeySet
+}
+
+func newJSONWebKey(key *SigningKey) JSONWebKey {
+	jwk := JSONWebKey{
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: key.Algorithm,
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encode(pub.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// Bentuk uncompressed: 0x04 || X || Y
+		point, _ := pub.Bytes()
+		size := (len(point) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		jwk.X = encode(point[1 : 1+size])
+		jwk.Y = encode(point[1+size:])
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encode(pub)
+	}
+
+	return jwk
+}
+
+// checkKeyAlgorithm memastikan tipe kunci cocok dengan algoritma yang dikonfigurasi.
+func checkKeyAlgorithm(algorithm string, publicKey crypto.PublicKey) error {
+	switch algorithm {
+	case AlgorithmRS256:
+		if pub, ok := publicKey.(*rsa.PublicKey); !ok || pub.Size() < 256 {
+			return fmt.Errorf("algoritma %s membutuhkan kunci RSA minimal 2048 bit", algorithm)
+		}
+	case AlgorithmES256:
+		if pub, ok := publicKey.(*ecdsa.PublicKey); !ok || pub.Curve != elliptic.P256() {
+			return fmt.Errorf("algoritma %s membutuhkan kunci ECDSA P-256", algorithm)
+		}
+	case AlgorithmEdDSA:
+		if _, ok := publicKey.(ed25519.PublicKey); !ok {
+			return fmt.Errorf("algoritma %s membutuhkan kunci Ed25519", algorithm)
+		}
+	default:
+		return fmt.Errorf("algoritma JWT %q tidak didukung", algorithm)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestSigningKey(t *testing.T, keyID, algorithm string) *SigningKey {
+	t.Helper()
+
+	var signer crypto.Signer
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &SigningKey{ID: keyID, Algorithm: algorithm, PrivateKey: signer, PublicKey: signer.Public()}
+}
+
+func writeTestKeyPEM(t *testing.T, dir string, key *SigningKey, publicOnly bool) {
+	t.Helper()
+
+	var block *pem.Block
+	if publicOnly {
+		der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, key.ID+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyRing(t *testing.T) {
+	Convey("Given signing keys", t, func() {
+		current := newTestSigningKey(t, "2026-10", AlgorithmES256)
+		previous := newTestSigningKey(t, "2026-07", AlgorithmES256)
+
+		Convey("When building a key ring", func() {
+			Convey("Then the active key is used for signing and the others for verification", func() {
+				ring, err := NewKeyRing(current.ID, current, previous)
+				So(err, ShouldBeNil)
+				So(ring.Active(), ShouldEqual, current)
+
+				key, ok := ring.Get(previous.ID)
+				So(ok, ShouldBeTrue)
+				So(key, ShouldEqual, previous)
+
+				_, ok = ring.Get("unknown")
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("Then it should fail when the active key is missing", func() {
+				_, err := NewKeyRing("unknown", current, previous)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then it should fail when the active key has no private key", func() {
+				verifyOnly := &SigningKey{ID: previous.ID, Algorithm: previous.Algorithm, PublicKey: previous.PublicKey}
+				_, err := NewKeyRing(verifyOnly.ID, current, verifyOnly)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then it should fail with duplicate key IDs", func() {
+				_, err := NewKeyRing(current.ID, current, current)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then it should fail when a key does not match its algorithm", func() {
+				mismatched := newTestSigningKey(t, "ed", AlgorithmEdDSA)
+				mismatched.Algorithm = AlgorithmES256
+				_, err := NewKeyRing(current.ID, current, mismatched)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When loading keys from a directory", func() {
+			dir := t.TempDir()
+			writeTestKeyPEM(t, dir, current, false)
+			writeTestKeyPEM(t, dir, previous, true)
+
+			Convey("Then private keys can sign and public keys only verify", func() {
+				ring, err := LoadKeyRing(dir, current.ID, AlgorithmES256)
+				So(err, ShouldBeNil)
+				So(ring.Active().ID, ShouldEqual, current.ID)
+				So(ring.Active().CanSign(), ShouldBeTrue)
+
+				key, ok := ring.Get(previous.ID)
+				So(ok, ShouldBeTrue)
+				So(key.CanSign(), ShouldBeFalse)
+			})
+
+			Convey("Then a public key cannot be the active key", func() {
+				_, err := LoadKeyRing(dir, previous.ID, AlgorithmES256)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then it should fail when the algorithm does not match the keys", func() {
+				_, err := LoadKeyRing(dir, current.ID, AlgorithmRS256)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then it should fail with an empty directory", func() {
+				_, err := LoadKeyRing(t.TempDir(), current.ID, AlgorithmES256)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When publishing the JWKS", func() {
+			rsaKey := newTestSigningKey(t, "rsa", AlgorithmRS256)
+			edKey := newTestSigningKey(t, "ed", AlgorithmEdDSA)
+
+			Convey("Then every key is published without private material", func() {
+				ring, err := NewKeyRing(current.ID, current, previous)
+				So(err, ShouldBeNil)
+
+				set := ring.JWKS()
+				So(set.Keys, ShouldHaveLength, 2)
+				So(set.Keys[0].KeyID, ShouldEqual, previous.ID)
+				So(set.Keys[1].KeyID, ShouldEqual, current.ID)
+
+				jwk := set.Keys[1]
+				So(jwk.KeyType, ShouldEqual, "EC")
+				So(jwk.Curve, ShouldEqual, "P-256")
+				So(jwk.Algorithm, ShouldEqual, AlgorithmES256)
+				So(jwk.Use, ShouldEqual, "sig")
+				So(jwk.X, ShouldHaveLength, 43)
+				So(jwk.Y, ShouldHaveLength, 43)
+			})
+
+			Convey("Then RSA keys expose their modulus and exponent", func() {
+				ring, err := NewKeyRing(rsaKey.ID, rsaKey)
+				So(err, ShouldBeNil)
+
+				jwk := ring.JWKS().Keys[0]
+				So(jwk.KeyType, ShouldEqual, "RSA")
+				So(jwk.N, ShouldNotBeEmpty)
+				So(jwk.E, ShouldEqual, "AQAB")
+			})
+
+			Convey("Then Ed25519 keys are published as OKP", func() {
+				ring, err := NewKeyRing(edKey.ID, edKey)
+				So(err, ShouldBeNil)
+
+				jwk := ring.JWKS().Keys[0]
+				So(jwk.KeyType, ShouldEqual, "OKP")
+				So(jwk.Curve, ShouldEqual, "Ed25519")
+				So(jwk.X, ShouldHaveLength, 43)
+			})
+		})
+	})
+}
@@ -0,0 +1,199 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
+)
+
+// Format token yang didukung oleh AUTH_TOKEN_FORMAT.
+const (
+	FormatJWT            = "jwt"
+	FormatPASETOV4Local  = "paseto_v4_local"
+	FormatPASETOV4Public = "paseto_v4_public"
+)
+
+// Claim kustom yang dipakai di dalam token PASETO.
+const (
+	// claimTokenType menyimpan TokenType.
+	claimTokenType = "token_type"
+	// claimSessionID menyimpan ID sesi login, sama seperti claim sid pada JWT.
+	claimSessionID = "sid"
+	// claimPermissions menyimpan permission user, sama seperti claim perms pada JWT.
+	claimPermissions = "perms"
+)
+
+// PASETO adalah implementasi Token menggunakan PASETO v4. Versi dan algoritma
+// ditentukan oleh header token dan kunci yang dipakai, sehingga tidak ada
+// pilihan algoritma yang bisa dimanipulasi dari sisi token.
+type PASETO struct {
+	issuer string
+	encode func(token paseto.Token) string
+	decode func(parser paseto.Parser, tokenStr string) (*paseto.Token, error)
+}
+
+var _ Token = (*PASETO)(nil)
+
+// NewPASETOV4Local membuat token v4.local (terenkripsi) dengan kunci simetris
+// 32 byte dalam format hex.
+func NewPASETOV4Local(cfg *config.Config) (*PASETO, error) {
+	key, err := paseto.V4SymmetricKeyFromHex(cfg.AuthPASETOKey)
+	if err != nil {
+		return nil, fmt.Errorf("kunci PASETO v4.local harus berupa 32 byte dalam format hex: %w", err)
+	}
+
+	return &PASETO{
+		issuer: cfg.AppName,
+		encode: func(token paseto.Token) string {
+			return token.V4Encrypt(key, nil)
+		},
+		decode: func(parser paseto.Parser, tokenStr string) (*paseto.Token, error) {
+			return parser.ParseV4Local(key, tokenStr, nil)
+		},
+	}, nil
+}
+
+// NewPASETOV4Public membuat token v4.public (ditandatangani Ed25519) dengan
+// secret key 64 byte dalam format hex.
+func NewPASETOV4Public(cfg *config.Config) (*PASETO, error) {
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(cfg.AuthPASETOKey)
+	if err != nil {
+		return nil, fmt.Errorf("kunci PASETO v4.public harus berupa secret key Ed25519 64 byte dalam format hex: %w", err)
+	}
+	publicKey := secretKey.Public()
+
+	return &PASETO{
+		issuer: cfg.AppName,
+		encode: func(token paseto.Token) string {
+			return token.V4Sign(secretKey, nil)
+		},
+		decode: func(parser paseto.Parser, tokenStr string) (*paseto.Token, error) {
+			return parser.ParseV4Public(publicKey, tokenStr, nil)
+		},
+	}, nil
+}
+
+func (p *PASETO) GenerateToken(userID uuid.UUID, tokenType TokenType, exp time.Duration, opts ...GenerateOption) (*GenerateTokenResponse, error) {
+	if !tokenType.IsValid() {
+		return nil, ErrInvalidTokenType
+	}
+
+	options := newGenerateOptions(opts)
+
+	now := time.Now()
+	tokenID := uuid.Must(uuid.NewV7())
+	expiresAt := now.Add(exp)
+
+	token := paseto.NewToken()
+	token.SetJti(tokenID.String())
+	token.SetIssuer(p.issuer)
+	token.SetSubject(userID.String())
+	token.SetAudience(tokenType.Audience())
+	token.SetIssuedAt(now)
+	token.SetNotBefore(now)
+	token.SetExpiration(expiresAt)
+	token.SetString(claimTokenType, string(tokenType))
+	if options.sessionID != uuid.Nil {
+		token.SetString(claimSessionID, options.sessionID.String())
+	}
+	if len(options.permissions) > 0 {
+		if err := token.Set(claimPermissions, options.permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	return &GenerateTokenResponse{
+		ID:        tokenID,
+		Value:     p.encode(token),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (p *PASETO) VerifyBearerToken(bearerToken string) (*Payload, error) {
+	tokenStr, err := extractBearerToken(bearerToken)
+	if err != nil {
+		return nil, err
+	}
+	return p.VerifyToken(tokenStr, TokenTypeAccess)
+}
+
+func (p *PASETO) VerifyToken(tokenStr string, tokenType TokenType) (*Payload, error) {
+	// Masa berlaku diperiksa manual agar bisa dibedakan menjadi ErrExpiredToken
+	parser := paseto.NewParserWithoutExpiryCheck()
+	parser.AddRule(
+		paseto.IssuedBy(p.issuer),
+		paseto.ForAudience(tokenType.Audience()),
+		paseto.NotBeforeNbf(),
+	)
+
+	token, err := p.decode(parser, tokenStr)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	expiresAt, err := token.GetExpiration()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !time.Now().Before(expiresAt) {
+		return nil, ErrExpiredToken
+	}
+
+	issuedAt, err := token.GetIssuedAt()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claimedType, err := token.GetString(claimTokenType)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if TokenType(claimedType) != tokenType {
+		return nil, ErrInvalidTokenType
+	}
+
+	tokenID, err := parseUUIDClaim(token.GetJti())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := parseUUIDClaim(token.GetSubject())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var sessionID uuid.UUID
+	if rawSessionID, err := token.GetString(claimSessionID); err == nil {
+		sessionID, err = uuid.Parse(rawSessionID)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	// Token tanpa claim perms (atau dengan isi yang tidak valid) dianggap tidak punya permission
+	var permissions []string
+	if err := token.Get(claimPermissions, &permissions); err != nil {
+		permissions = nil
+	}
+
+	return &Payload{
+		ID:          tokenID,
+		UserID:      userID,
+		Type:        tokenType,
+		Audience:    tokenType.Audience(),
+		IssuedAt:    issuedAt,
+		ExpiresAt:   expiresAt,
+		SessionID:   sessionID,
+		Permissions: permissions,
+	}, nil
+}
+
+func parseUUIDClaim(value string, err error) (uuid.UUID, error) {
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(value)
+}
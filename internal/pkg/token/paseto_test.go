@@ -0,0 +1,168 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPASETO(t *testing.T) {
+	localCfg := &config.Config{
+		AppName:       "test-app",
+		AuthPASETOKey: paseto.NewV4SymmetricKey().ExportHex(),
+	}
+	publicCfg := &config.Config{
+		AppName:       "test-app",
+		AuthPASETOKey: paseto.NewV4AsymmetricSecretKey().ExportHex(),
+	}
+
+	constructors := map[string]func() (*PASETO, error){
+		"v4.local":  func() (*PASETO, error) { return NewPASETOV4Local(localCfg) },
+		"v4.public": func() (*PASETO, error) { return NewPASETOV4Public(publicCfg) },
+	}
+
+	for purpose, newPASETO := range constructors {
+		Convey("Given a PASETO "+purpose+" instance", t, func() {
+			pasetoService, err := newPASETO()
+			So(err, ShouldBeNil)
+
+			userID := uuid.New()
+
+			Convey("When generating a token", func() {
+				resp, err := pasetoService.GenerateToken(userID, TokenTypeAccess, time.Hour)
+				So(err, ShouldBeNil)
+
+				Convey("Then it uses the PASETO v4 header", func() {
+					So(strings.HasPrefix(resp.Value, purpose+"."), ShouldBeTrue)
+				})
+
+				Convey("Then it can be verified with the same payload semantics as JWT", func() {
+					payload, err := pasetoService.VerifyBearerToken("Bearer " + resp.Value)
+					So(err, ShouldBeNil)
+					So(payload.ID, ShouldEqual, resp.ID)
+					So(payload.UserID, ShouldEqual, userID)
+					So(payload.Type, ShouldEqual, TokenTypeAccess)
+					So(payload.Audience, ShouldEqual, AudienceAPI)
+					So(payload.ExpiresAt.Unix(), ShouldEqual, resp.ExpiresAt.Unix())
+				})
+
+				Convey("Then it is not bound to a session", func() {
+					payload, err := pasetoService.VerifyToken(resp.Value, TokenTypeAccess)
+					So(err, ShouldBeNil)
+					So(payload.SessionID, ShouldEqual, uuid.Nil)
+				})
+
+				Convey("Then it is rejected as another token type", func() {
+					_, err := pasetoService.VerifyToken(resp.Value, TokenTypeReauth)
+					So(err, ShouldEqual, ErrInvalidTokenType)
+				})
+
+				Convey("Then it is rejected for another audience", func() {
+					_, err := pasetoService.VerifyToken(resp.Value, TokenTypeRefresh)
+					So(err, ShouldEqual, ErrInvalidToken)
+				})
+
+				Convey("Then a tampered token is rejected", func() {
+					tampered := resp.Value[:len(resp.Value)-2] + "AA"
+					_, err := pasetoService.VerifyToken(tampered, TokenTypeAccess)
+					So(err, ShouldEqual, ErrInvalidToken)
+				})
+
+				Convey("Then a token from another key is rejected", func() {
+					other, err := NewPASETOV4Local(&config.Config{
+						AppName:       "test-app",
+						AuthPASETOKey: paseto.NewV4SymmetricKey().ExportHex(),
+					})
+					So(err, ShouldBeNil)
+
+					_, err = other.VerifyToken(resp.Value, TokenTypeAccess)
+					So(err, ShouldEqual, ErrInvalidToken)
+				})
+			})
+
+			Convey("When generating a token bound to a session", func() {
+				sessionID := uuid.New()
+				resp, err := pasetoService.GenerateToken(userID, TokenTypeAccess, time.Hour, WithSessionID(sessionID))
+				So(err, ShouldBeNil)
+
+				payload, err := pasetoService.VerifyToken(resp.Value, TokenTypeAccess)
+				So(err, ShouldBeNil)
+				So(payload.SessionID, ShouldEqual, sessionID)
+			})
+
+			Convey("When generating a token with permissions", func() {
+				resp, err := pasetoService.GenerateToken(userID, TokenTypeAccess, time.Hour, WithPermissions("notes:delete:any"))
+				So(err, ShouldBeNil)
+
+				payload, err := pasetoService.VerifyToken(resp.Value, TokenTypeAccess)
+				So(err, ShouldBeNil)
+				So(payload.Permissions, ShouldResemble, []string{"notes:delete:any"})
+				So(payload.HasPermission("notes:delete:any"), ShouldBeTrue)
+			})
+
+			Convey("When the token is expired", func() {
+				resp, err := pasetoService.GenerateToken(userID, TokenTypeAccess, -time.Minute)
+				So(err, ShouldBeNil)
+
+				_, err = pasetoService.VerifyToken(resp.Value, TokenTypeAccess)
+				So(err, ShouldEqual, ErrExpiredToken)
+			})
+
+			Convey("When generating a token of unknown type", func() {
+				_, err := pasetoService.GenerateToken(userID, TokenType("unknown"), time.Hour)
+				So(err, ShouldEqual, ErrInvalidTokenType)
+			})
+
+			Convey("When verifying garbage", func() {
+				_, err := pasetoService.VerifyToken("invalid.token", TokenTypeAccess)
+				So(err, ShouldEqual, ErrInvalidToken)
+
+				_, err = pasetoService.VerifyBearerToken("invalid")
+				So(err, ShouldEqual, ErrInvalidTokenFormat)
+			})
+		})
+	}
+
+	Convey("Given an invalid PASETO key", t, func() {
+		_, err := NewPASETOV4Local(&config.Config{AuthPASETOKey: "abcd"})
+		So(err, ShouldNotBeNil)
+
+		_, err = NewPASETOV4Public(&config.Config{AuthPASETOKey: paseto.NewV4SymmetricKey().ExportHex()})
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestNew(t *testing.T) {
+	Convey("Given the token configuration", t, func() {
+		Convey("JWT with HS256 is the default", func() {
+			tokenMaker, err := New(&config.Config{AuthJWTSecret: strings.Repeat("x", 32)})
+			So(err, ShouldBeNil)
+			So(tokenMaker, ShouldHaveSameTypeAs, &JWT{})
+		})
+
+		Convey("PASETO can be selected", func() {
+			tokenMaker, err := New(&config.Config{
+				AuthTokenFormat: FormatPASETOV4Local,
+				AuthPASETOKey:   paseto.NewV4SymmetricKey().ExportHex(),
+			})
+			So(err, ShouldBeNil)
+			So(tokenMaker, ShouldHaveSameTypeAs, &PASETO{})
+		})
+
+		Convey("Construction errors do not leak a typed nil", func() {
+			tokenMaker, err := New(&config.Config{AuthTokenFormat: FormatPASETOV4Public})
+			So(err, ShouldNotBeNil)
+			So(tokenMaker == nil, ShouldBeTrue)
+		})
+
+		Convey("Unknown formats are rejected", func() {
+			_, err := New(&config.Config{AuthTokenFormat: "macaroon"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
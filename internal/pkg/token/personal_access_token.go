@@ -0,0 +1,58 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"github.com/sammidev/goca/internal/pkg/random"
+)
+
+// TokenTypePersonalAccess menandai payload yang berasal dari personal access token.
+// Token ini tidak ditandatangani seperti tipe lain, melainkan dicocokkan dengan hash
+// yang disimpan di database, sehingga tidak termasuk dalam TokenType.IsValid.
+const TokenTypePersonalAccess TokenType = "personal_access"
+
+const (
+	// PersonalAccessTokenPrefix membuat personal access token mudah dibedakan dari
+	// access token biasa dan mudah dikenali oleh secret scanner.
+	PersonalAccessTokenPrefix = "goca_pat_"
+	personalAccessTokenLength = 40
+	personalAccessTokenHint   = 4
+)
+
+// GeneratePersonalAccessToken membuat personal access token baru. Nilai token hanya
+// ditampilkan sekali ke user, yang disimpan adalah hash dan hint-nya.
+func GeneratePersonalAccessToken() (value, hash, hint string, err error) {
+	secret, err := random.String(personalAccessTokenLength)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	value = PersonalAccessTokenPrefix + secret
+	return value, HashPersonalAccessToken(value), secret[len(secret)-personalAccessTokenHint:], nil
+}
+
+// HashPersonalAccessToken menghitung hash SHA-256 dari token. Hash yang cepat sudah
+// cukup karena token dibuat acak dengan entropi tinggi, dan hasilnya deterministik
+// sehingga bisa dipakai untuk mencari token di database.
+func HashPersonalAccessToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsPersonalAccessToken memeriksa apakah string berbentuk personal access token.
+func IsPersonalAccessToken(value string) bool {
+	return strings.HasPrefix(value, PersonalAccessTokenPrefix)
+}
+
+// IsPersonalAccessToken memeriksa apakah payload berasal dari personal access token.
+func (p *Payload) IsPersonalAccessToken() bool {
+	return p.Type == TokenTypePersonalAccess
+}
+
+// HasScope memeriksa apakah personal access token membawa scope tertentu.
+func (p *Payload) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
@@ -0,0 +1,57 @@
+package token
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPersonalAccessToken(t *testing.T) {
+	Convey("Given personal access token helpers", t, func() {
+		Convey("When generating a token", func() {
+			value, hash, hint, err := GeneratePersonalAccessToken()
+
+			Convey("Then it should carry the prefix and match its hash and hint", func() {
+				So(err, ShouldBeNil)
+				So(IsPersonalAccessToken(value), ShouldBeTrue)
+				So(len(value), ShouldEqual, len(PersonalAccessTokenPrefix)+personalAccessTokenLength)
+				So(hash, ShouldEqual, HashPersonalAccessToken(value))
+				So(strings.HasSuffix(value, hint), ShouldBeTrue)
+				So(len(hint), ShouldEqual, personalAccessTokenHint)
+			})
+
+			Convey("Then another token should be different", func() {
+				other, otherHash, _, err := GeneratePersonalAccessToken()
+				So(err, ShouldBeNil)
+				So(other, ShouldNotEqual, value)
+				So(otherHash, ShouldNotEqual, hash)
+			})
+		})
+
+		Convey("When checking a value without the prefix", func() {
+			Convey("Then it should not be treated as a personal access token", func() {
+				So(IsPersonalAccessToken("eyJhbGciOiJIUzI1NiJ9.e30.sig"), ShouldBeFalse)
+				So(IsPersonalAccessToken(""), ShouldBeFalse)
+			})
+		})
+
+		Convey("When checking the payload of a personal access token", func() {
+			payload := &Payload{Type: TokenTypePersonalAccess, Scopes: []string{"notes:read"}}
+
+			Convey("Then only the granted scopes should be present", func() {
+				So(payload.IsPersonalAccessToken(), ShouldBeTrue)
+				So(payload.HasScope("notes:read"), ShouldBeTrue)
+				So(payload.HasScope("notes:write"), ShouldBeFalse)
+			})
+		})
+
+		Convey("When checking the payload of an access token", func() {
+			payload := &Payload{Type: TokenTypeAccess}
+
+			Convey("Then it should not be a personal access token", func() {
+				So(payload.IsPersonalAccessToken(), ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -2,30 +2,171 @@ package token
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
 )
 
 // Token adalah interfaces untuk manajemen token.
 type Token interface {
-	// GenerateToken membuat token baru untuk user ID tertentu.
-	GenerateToken(userID uuid.UUID, exp time.Duration) (*GenerateTokenResponse, error)
-	// VerifyBearerToken memverifikasi token dari header otorisasi "Bearer".
+	// GenerateToken membuat token baru dengan tipe tertentu untuk user ID tertentu.
+	GenerateToken(userID uuid.UUID, tokenType TokenType, exp time.Duration, opts ...GenerateOption) (*GenerateTokenResponse, error)
+	// VerifyBearerToken memverifikasi access token dari header otorisasi "Bearer".
 	VerifyBearerToken(authHeader string) (*Payload, error)
-	// VerifyToken memverifikasi token string dan mengembalikan payload-nya.
-	VerifyToken(token string) (*Payload, error)
+	// VerifyToken memverifikasi token string, memastikan tipenya sesuai, dan mengembalikan payload-nya.
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
+}
+
+// GenerateOption mengatur claim opsional pada token yang dibuat.
+type GenerateOption func(*generateOptions)
+
+type generateOptions struct {
+	sessionID   uuid.UUID
+	permissions []string
+}
+
+// WithSessionID mengikat token ke sesi login sehingga token ikut ditolak
+// ketika sesinya dicabut.
+func WithSessionID(sessionID uuid.UUID) GenerateOption {
+	return func(o *generateOptions) {
+		o.sessionID = sessionID
+	}
+}
+
+// WithPermissions menyematkan permission milik user ke dalam token sehingga
+// middleware bisa memeriksanya tanpa query ke database.
+func WithPermissions(permissions ...string) GenerateOption {
+	return func(o *generateOptions) {
+		o.permissions = permissions
+	}
+}
+
+func newGenerateOptions(opts []GenerateOption) generateOptions {
+	var o generateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// AlgorithmHS256 adalah algoritma default yang memakai shared secret AUTH_JWT_SECRET.
+const AlgorithmHS256 = "HS256"
+
+// New membuat implementasi Token sesuai AUTH_TOKEN_FORMAT dan, untuk JWT, AUTH_JWT_ALGORITHM.
+func New(cfg *config.Config) (Token, error) {
+	switch cfg.AuthTokenFormat {
+	case "", FormatJWT:
+		return newJWT(cfg)
+	case FormatPASETOV4Local:
+		return checkNew(NewPASETOV4Local(cfg))
+	case FormatPASETOV4Public:
+		return checkNew(NewPASETOV4Public(cfg))
+	default:
+		return nil, fmt.Errorf("format token %q tidak didukung", cfg.AuthTokenFormat)
+	}
+}
+
+func newJWT(cfg *config.Config) (Token, error) {
+	switch cfg.AuthJWTAlgorithm {
+	case "", AlgorithmHS256:
+		return checkNew(NewJWT(cfg))
+	case AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA:
+		keyRing, err := LoadKeyRing(cfg.AuthJWTKeysDir, cfg.AuthJWTSigningKeyID, cfg.AuthJWTAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		return NewAsymmetricJWT(cfg, keyRing), nil
+	default:
+		return nil, fmt.Errorf("algoritma JWT %q tidak didukung", cfg.AuthJWTAlgorithm)
+	}
+}
+
+// checkNew mencegah pointer nil bertipe lolos sebagai Token yang tidak nil.
+func checkNew[T Token](t T, err error) (Token, error) {
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// TokenType membedakan kegunaan token sehingga satu jenis token
+// tidak bisa dipakai di tempat jenis token lainnya.
+type TokenType string
+
+const (
+	TokenTypeAccess       TokenType = "access"
+	TokenTypeRefresh      TokenType = "refresh"
+	TokenTypeMFAChallenge TokenType = "mfa_challenge"
+	TokenTypeEmailVerify  TokenType = "email_verify"
+	// TokenTypeReauth membuktikan bahwa user baru saja memasukkan ulang kredensialnya
+	// dan dibutuhkan oleh aksi sensitif.
+	TokenTypeReauth TokenType = "reauth"
+	// TokenTypeAccountUnlock dikirim lewat email untuk membuka akun yang terkunci.
+	TokenTypeAccountUnlock TokenType = "account_unlock"
+	// TokenTypeEmailChangeCancel dikirim ke alamat email lama untuk membatalkan perubahan email.
+	TokenTypeEmailChangeCancel TokenType = "email_change_cancel"
+	// TokenTypeLoginAlert dikirim bersama peringatan login dari perangkat baru untuk
+	// mengakhiri semua sesi jika login tersebut bukan dilakukan oleh user.
+	TokenTypeLoginAlert TokenType = "login_alert"
+	// TokenTypePasswordReset dikirim lewat email sebagai tautan reset password
+	// ketika reset password memakai mode tautan.
+	TokenTypePasswordReset TokenType = "password_reset"
+)
+
+// Audience penerima token.
+const (
+	// AudienceAPI adalah audience untuk token yang dipakai mengakses API.
+	AudienceAPI = "api"
+	// AudienceAuth adalah audience untuk token yang hanya diterima oleh endpoint otentikasi.
+	AudienceAuth = "auth"
+)
+
+// IsValid memeriksa apakah tipe token dikenali.
+func (t TokenType) IsValid() bool {
+	switch t {
+	case TokenTypeAccess, TokenTypeRefresh, TokenTypeMFAChallenge, TokenTypeEmailVerify, TokenTypeReauth, TokenTypeAccountUnlock,
+		TokenTypeEmailChangeCancel, TokenTypeLoginAlert, TokenTypePasswordReset:
+		return true
+	}
+	return false
+}
+
+// Audience mengembalikan audience yang dituju oleh tipe token.
+func (t TokenType) Audience() string {
+	if t == TokenTypeAccess || t == TokenTypeReauth {
+		return AudienceAPI
+	}
+	return AudienceAuth
 }
 
 type GenerateTokenResponse struct {
+	ID        uuid.UUID `json:"id"`
 	Value     string    `json:"token"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // Payload berisi data yang ada di dalam body token.
 type Payload struct {
+	ID        uuid.UUID `json:"id"`
 	UserID    uuid.UUID `json:"user_id"`
+	Type      TokenType `json:"type"`
+	Audience  string    `json:"audience"`
+	IssuedAt  time.Time `json:"issued_at"`
 	ExpiresAt time.Time `json:"expires_at"`
+	// SessionID bernilai uuid.Nil untuk token yang tidak terikat ke sesi login.
+	SessionID uuid.UUID `json:"session_id"`
+	// Permissions adalah permission user pada saat token dibuat.
+	Permissions []string `json:"permissions"`
+	// Scopes membatasi personal access token dan kosong untuk token jenis lain.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// HasPermission memeriksa apakah token membawa permission tertentu.
+func (p *Payload) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
 }
 
 // Errors standar untuk operasi token.
@@ -33,4 +174,5 @@ var (
 	ErrInvalidTokenFormat = errors.New("token format tidak valid")
 	ErrInvalidToken       = errors.New("token tidak valid")
 	ErrExpiredToken       = errors.New("token sudah kedaluwarsa")
+	ErrInvalidTokenType   = errors.New("tipe token tidak sesuai")
 )
@@ -0,0 +1,113 @@
+// Package useragent turns a User-Agent header into a short, human readable
+// description of the client, e.g. "Chrome on macOS". It only recognises the
+// common browsers and platforms and is not meant for analytics.
+package useragent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const Unknown = "Unknown"
+
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeOther   = "other"
+)
+
+// Device is the client information extracted from a User-Agent header.
+type Device struct {
+	Browser string
+	OS      string
+	Type    string
+}
+
+// String describes the device the way it is shown to users.
+func (d Device) String() string {
+	switch {
+	case d.Browser == Unknown && d.OS == Unknown:
+		return Unknown
+	case d.OS == Unknown:
+		return d.Browser
+	case d.Browser == Unknown:
+		return d.OS
+	}
+	return d.Browser + " on " + d.OS
+}
+
+// Fingerprint identifies the kind of device, not the exact client: it only covers
+// the browser, operating system and device type, so a browser update keeps it.
+func (d Device) Fingerprint() string {
+	sum := sha256.Sum256([]byte(d.Browser + "|" + d.OS + "|" + d.Type))
+	return hex.EncodeToString(sum[:])
+}
+
+type rule struct {
+	token string
+	name  string
+}
+
+// Order matters: most browsers also advertise the engines they are based on,
+// so Edge and Opera must be matched before Chrome, and Chrome before Safari.
+var browserRules = []rule{
+	{"Edg/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"Safari/", "Safari"},
+	{"PostmanRuntime/", "Postman"},
+	{"curl/", "curl"},
+	{"okhttp/", "OkHttp"},
+}
+
+var osRules = []rule{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Parse extracts the browser, operating system and device type from a User-Agent header.
+func Parse(userAgent string) Device {
+	device := Device{
+		Browser: match(userAgent, browserRules),
+		OS:      match(userAgent, osRules),
+	}
+	device.Type = deviceType(userAgent, device)
+	return device
+}
+
+func match(userAgent string, rules []rule) string {
+	for _, r := range rules {
+		if strings.Contains(userAgent, r.token) {
+			return r.name
+		}
+	}
+	return Unknown
+}
+
+func deviceType(userAgent string, device Device) string {
+	switch {
+	case device.OS == "iPadOS" || strings.Contains(userAgent, "Tablet"):
+		return DeviceTypeTablet
+	case device.OS == "iOS" || strings.Contains(userAgent, "Mobile"):
+		return DeviceTypeMobile
+	case device.OS == "Android":
+		// Android tablets leave "Mobile" out of the User-Agent
+		return DeviceTypeTablet
+	case device.OS == Unknown:
+		return DeviceTypeOther
+	}
+	return DeviceTypeDesktop
+}
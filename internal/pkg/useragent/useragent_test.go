@@ -0,0 +1,89 @@
+package useragent
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Diberikan berbagai User-Agent", t, func() {
+		cases := []struct {
+			name      string
+			userAgent string
+			want      Device
+			display   string
+		}{
+			{
+				name:      "Chrome di macOS",
+				userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+				want:      Device{Browser: "Chrome", OS: "macOS", Type: DeviceTypeDesktop},
+				display:   "Chrome on macOS",
+			},
+			{
+				name:      "Edge di Windows",
+				userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0",
+				want:      Device{Browser: "Edge", OS: "Windows", Type: DeviceTypeDesktop},
+				display:   "Edge on Windows",
+			},
+			{
+				name:      "Safari di iPhone",
+				userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1",
+				want:      Device{Browser: "Safari", OS: "iOS", Type: DeviceTypeMobile},
+				display:   "Safari on iOS",
+			},
+			{
+				name:      "Firefox di Android",
+				userAgent: "Mozilla/5.0 (Android 14; Mobile; rv:131.0) Gecko/131.0 Firefox/131.0",
+				want:      Device{Browser: "Firefox", OS: "Android", Type: DeviceTypeMobile},
+				display:   "Firefox on Android",
+			},
+			{
+				name:      "Chrome di tablet Android",
+				userAgent: "Mozilla/5.0 (Linux; Android 13; SM-X200) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+				want:      Device{Browser: "Chrome", OS: "Android", Type: DeviceTypeTablet},
+				display:   "Chrome on Android",
+			},
+			{
+				name:      "curl",
+				userAgent: "curl/8.5.0",
+				want:      Device{Browser: "curl", OS: Unknown, Type: DeviceTypeOther},
+				display:   "curl",
+			},
+			{
+				name:      "header kosong",
+				userAgent: "",
+				want:      Device{Browser: Unknown, OS: Unknown, Type: DeviceTypeOther},
+				display:   Unknown,
+			},
+		}
+
+		for _, tc := range cases {
+			Convey("Ketika User-Agent berasal dari "+tc.name, func() {
+				device := Parse(tc.userAgent)
+
+				So(device, ShouldResemble, tc.want)
+				So(device.String(), ShouldEqual, tc.display)
+			})
+		}
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	Convey("Diberikan dua User-Agent", t, func() {
+		Convey("Ketika keduanya hanya berbeda versi browser", func() {
+			older := Parse("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/128.0.0.0 Safari/537.36")
+			newer := Parse("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36")
+
+			So(newer.Fingerprint(), ShouldEqual, older.Fingerprint())
+			So(newer.Fingerprint(), ShouldHaveLength, 64)
+		})
+
+		Convey("Ketika keduanya berasal dari perangkat yang berbeda", func() {
+			desktop := Parse("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36")
+			mobile := Parse("Mozilla/5.0 (Android 14; Mobile; rv:131.0) Gecko/131.0 Firefox/131.0")
+
+			So(mobile.Fingerprint(), ShouldNotEqual, desktop.Fingerprint())
+		})
+	})
+}
@@ -2,6 +2,9 @@ package validator
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/sammidev/goca/internal/pkg/password"
 )
 
 // getEnglishErrorMessage returns English error messages
@@ -45,6 +48,13 @@ func (v *GoPlaygroundValidator) getEnglishErrorMessage(field, tag, param string)
 		return fmt.Sprintf("%s must be equal to %s", field, param)
 	case "nefield":
 		return fmt.Sprintf("%s must not be equal to %s", field, param)
+	case "required_without":
+		return fmt.Sprintf("%s is required when %s is not provided", field, param)
+	case "required_if":
+		if other, value, ok := strings.Cut(param, " "); ok {
+			return fmt.Sprintf("%s is required when %s is %s", field, other, value)
+		}
+		return fmt.Sprintf("%s is required", field)
 	case "contains":
 		return fmt.Sprintf("%s must contain '%s'", field, param)
 	case "containsany":
@@ -92,9 +102,11 @@ func (v *GoPlaygroundValidator) getEnglishErrorMessage(field, tag, param string)
 	case "longitude":
 		return fmt.Sprintf("%s must be a valid longitude", field)
 	case "password":
-		return fmt.Sprintf("%s must contain at least 6 characters with uppercase, lowercase, number, and special character", field)
+		return fmt.Sprintf("%s does not meet the password policy", field)
 	case "strong_password":
 		return fmt.Sprintf("%s must contain at least 8 characters with 2+ uppercase, 2+ lowercase, 2+ numbers, 2+ special characters, and no common patterns", field)
+	case "notbreached":
+		return fmt.Sprintf("%s has appeared in a data breach and cannot be used, choose a different one", field)
 	case "phone_id":
 		return fmt.Sprintf("%s must be a valid Indonesian phone number", field)
 	case "postal_code_id":
@@ -153,6 +165,13 @@ func (v *GoPlaygroundValidator) getIndonesianErrorMessage(field, tag, param stri
 		return fmt.Sprintf("%s harus sama dengan %s", field, param)
 	case "nefield":
 		return fmt.Sprintf("%s tidak boleh sama dengan %s", field, param)
+	case "required_without":
+		return fmt.Sprintf("%s wajib diisi jika %s tidak diisi", field, param)
+	case "required_if":
+		if other, value, ok := strings.Cut(param, " "); ok {
+			return fmt.Sprintf("%s wajib diisi jika %s bernilai %s", field, other, value)
+		}
+		return fmt.Sprintf("%s wajib diisi", field)
 	case "contains":
 		return fmt.Sprintf("%s harus mengandung '%s'", field, param)
 	case "containsany":
@@ -200,9 +219,11 @@ func (v *GoPlaygroundValidator) getIndonesianErrorMessage(field, tag, param stri
 	case "longitude":
 		return fmt.Sprintf("%s harus berupa bujur yang valid", field)
 	case "password":
-		return fmt.Sprintf("%s harus mengandung minimal 6 karakter dengan huruf besar, huruf kecil, angka, dan karakter khusus", field)
+		return fmt.Sprintf("%s tidak memenuhi kebijakan password", field)
 	case "strong_password":
 		return fmt.Sprintf("%s harus mengandung minimal 8 karakter dengan 2+ huruf besar, 2+ huruf kecil, 2+ angka, 2+ karakter khusus, dan tidak menggunakan pola umum", field)
+	case "notbreached":
+		return fmt.Sprintf("%s pernah muncul dalam kebocoran data dan tidak dapat digunakan, pilih yang lain", field)
 	case "phone_id":
 		return fmt.Sprintf("%s harus berupa nomor telepon Indonesia yang valid", field)
 	case "postal_code_id":
@@ -219,3 +240,71 @@ func (v *GoPlaygroundValidator) getIndonesianErrorMessage(field, tag, param stri
 		return fmt.Sprintf("%s tidak valid", field)
 	}
 }
+
+// getPasswordPolicyMessage lists every password policy rule that failed in the validator's locale
+func (v *GoPlaygroundValidator) getPasswordPolicyMessage(field string, violations []password.Violation) string {
+	requirements := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		switch v.locale {
+		case LocaleID:
+			requirements = append(requirements, getIndonesianPasswordRuleMessage(violation))
+		default:
+			requirements = append(requirements, getEnglishPasswordRuleMessage(violation))
+		}
+	}
+
+	switch v.locale {
+	case LocaleID:
+		return fmt.Sprintf("%s harus %s", field, strings.Join(requirements, "; "))
+	default:
+		return fmt.Sprintf("%s must %s", field, strings.Join(requirements, "; "))
+	}
+}
+
+// getEnglishPasswordRuleMessage returns the English requirement for a failed password rule
+func getEnglishPasswordRuleMessage(violation password.Violation) string {
+	switch violation.Rule {
+	case password.RuleMinLength:
+		return fmt.Sprintf("be at least %s characters long", violation.Param)
+	case password.RuleLowercase:
+		return fmt.Sprintf("contain at least %s lowercase letter(s)", violation.Param)
+	case password.RuleUppercase:
+		return fmt.Sprintf("contain at least %s uppercase letter(s)", violation.Param)
+	case password.RuleDigit:
+		return fmt.Sprintf("contain at least %s number(s)", violation.Param)
+	case password.RuleSpecial:
+		return fmt.Sprintf("contain at least %s special character(s)", violation.Param)
+	case password.RuleBannedWord:
+		return "not contain common or banned words"
+	case password.RulePersonalInfo:
+		return "not contain your name or email address"
+	case password.RuleStrength:
+		return fmt.Sprintf("be harder to guess (strength score of at least %s out of %d)", violation.Param, password.MaxStrengthScore)
+	default:
+		return fmt.Sprintf("satisfy the %s rule", violation.Rule)
+	}
+}
+
+// getIndonesianPasswordRuleMessage returns the Indonesian requirement for a failed password rule
+func getIndonesianPasswordRuleMessage(violation password.Violation) string {
+	switch violation.Rule {
+	case password.RuleMinLength:
+		return fmt.Sprintf("terdiri dari minimal %s karakter", violation.Param)
+	case password.RuleLowercase:
+		return fmt.Sprintf("mengandung minimal %s huruf kecil", violation.Param)
+	case password.RuleUppercase:
+		return fmt.Sprintf("mengandung minimal %s huruf besar", violation.Param)
+	case password.RuleDigit:
+		return fmt.Sprintf("mengandung minimal %s angka", violation.Param)
+	case password.RuleSpecial:
+		return fmt.Sprintf("mengandung minimal %s karakter khusus", violation.Param)
+	case password.RuleBannedWord:
+		return "tidak mengandung kata umum atau kata yang dilarang"
+	case password.RulePersonalInfo:
+		return "tidak mengandung nama atau alamat email Anda"
+	case password.RuleStrength:
+		return fmt.Sprintf("lebih sulit ditebak (skor kekuatan minimal %s dari %d)", violation.Param, password.MaxStrengthScore)
+	default:
+		return fmt.Sprintf("memenuhi aturan %s", violation.Rule)
+	}
+}
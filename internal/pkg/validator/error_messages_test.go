@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/sammidev/goca/internal/pkg/password"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type passwordRequest struct {
+	Email       string `json:"email"`
+	NewPassword string `json:"new_password" validate:"password"`
+}
+
+func (r passwordRequest) PersonalInfo() []string {
+	return []string{r.Email}
+}
+
+func TestPasswordRuleMessages(t *testing.T) {
+	Convey("Diberikan setiap aturan kebijakan password", t, func() {
+		cases := []struct {
+			violation password.Violation
+			en        string
+			id        string
+		}{
+			{password.Violation{Rule: password.RuleMinLength, Param: "8"}, "be at least 8 characters long", "terdiri dari minimal 8 karakter"},
+			{password.Violation{Rule: password.RuleLowercase, Param: "1"}, "contain at least 1 lowercase letter(s)", "mengandung minimal 1 huruf kecil"},
+			{password.Violation{Rule: password.RuleUppercase, Param: "2"}, "contain at least 2 uppercase letter(s)", "mengandung minimal 2 huruf besar"},
+			{password.Violation{Rule: password.RuleDigit, Param: "1"}, "contain at least 1 number(s)", "mengandung minimal 1 angka"},
+			{password.Violation{Rule: password.RuleSpecial, Param: "1"}, "contain at least 1 special character(s)", "mengandung minimal 1 karakter khusus"},
+			{password.Violation{Rule: password.RuleBannedWord}, "not contain common or banned words", "tidak mengandung kata umum atau kata yang dilarang"},
+			{password.Violation{Rule: password.RulePersonalInfo}, "not contain your name or email address", "tidak mengandung nama atau alamat email Anda"},
+			{password.Violation{Rule: password.RuleStrength, Param: "3"}, "be harder to guess (strength score of at least 3 out of 4)", "lebih sulit ditebak (skor kekuatan minimal 3 dari 4)"},
+			{password.Violation{Rule: "unknown"}, "satisfy the unknown rule", "memenuhi aturan unknown"},
+		}
+
+		Convey("Ketika pesan berbahasa Inggris dibuat", func() {
+			Convey("Maka setiap aturan punya pesannya sendiri", func() {
+				for _, c := range cases {
+					So(getEnglishPasswordRuleMessage(c.violation), ShouldEqual, c.en)
+				}
+			})
+		})
+
+		Convey("Ketika pesan berbahasa Indonesia dibuat", func() {
+			Convey("Maka setiap aturan punya pesannya sendiri", func() {
+				for _, c := range cases {
+					So(getIndonesianPasswordRuleMessage(c.violation), ShouldEqual, c.id)
+				}
+			})
+		})
+	})
+}
+
+func TestPasswordPolicyMessage(t *testing.T) {
+	Convey("Diberikan password yang melanggar beberapa aturan", t, func() {
+		req := passwordRequest{Email: "sammi@example.com", NewPassword: "sammi1"}
+
+		Convey("Ketika validator memakai bahasa Inggris", func() {
+			errs := NewGoPlaygroundValidatorWithLocale(LocaleEN).ValidateAndGetErrors(req)
+
+			Convey("Maka pesan menggabungkan setiap aturan yang gagal", func() {
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Message, ShouldEqual, "new_password must contain at least 1 uppercase letter(s); contain at least 1 special character(s); not contain your name or email address")
+			})
+
+			Convey("Maka aturan yang gagal dikembalikan", func() {
+				So(errs[0].Rules, ShouldResemble, []string{"uppercase", "special", "personal_info"})
+			})
+		})
+
+		Convey("Ketika validator memakai bahasa Indonesia", func() {
+			errs := NewGoPlaygroundValidatorWithLocale(LocaleID).ValidateAndGetErrors(req)
+
+			Convey("Maka pesan menggabungkan setiap aturan yang gagal", func() {
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Message, ShouldEqual, "new_password harus mengandung minimal 1 huruf besar; mengandung minimal 1 karakter khusus; tidak mengandung nama atau alamat email Anda")
+			})
+		})
+
+		Convey("Ketika kebijakan yang dikonfigurasi lebih longgar", func() {
+			v := NewGoPlaygroundValidatorWithLocale(LocaleEN, WithPasswordPolicy(password.Policy{MinLength: 6}))
+
+			Convey("Maka hanya aturan yang dikonfigurasi yang diperiksa", func() {
+				So(v.ValidateAndGetErrors(passwordRequest{Email: "sammi@example.com", NewPassword: "abcdef"}), ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -5,9 +5,10 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
-	"unicode"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/sammidev/goca/internal/pkg/logger"
+	"github.com/sammidev/goca/internal/pkg/password"
 )
 
 // Locale constants
@@ -24,6 +25,8 @@ type ValidationError struct {
 	Message string `json:"message"`
 	Tag     string `json:"tag,omitempty"`
 	Value   string `json:"value,omitempty"`
+	// Rules lists the password policy rules that failed, for the password tags only.
+	Rules []string `json:"rules,omitempty"`
 }
 
 // ValidationErrors represents a collection of validation apperror
@@ -49,8 +52,47 @@ func (ve ValidationErrors) Error() string {
 
 // GoPlaygroundValidator is a concrete implementation of the Validator interface using go-playground/validator
 type GoPlaygroundValidator struct {
-	validate *validator.Validate
-	locale   Locale
+	validate       *validator.Validate
+	locale         Locale
+	breachChecker  BreachChecker
+	passwordPolicy password.Policy
+	logger         logger.Logger
+}
+
+// PersonalInfoProvider is implemented by requests whose password must not contain
+// the account's own details, such as its name or email address
+type PersonalInfoProvider interface {
+	PersonalInfo() []string
+}
+
+// BreachChecker reports whether a password appears in a breached-password corpus
+type BreachChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// Option configures optional dependencies of the validator
+type Option func(*GoPlaygroundValidator)
+
+// WithBreachChecker enables the notbreached tag. Without it the tag accepts every value.
+func WithBreachChecker(checker BreachChecker) Option {
+	return func(v *GoPlaygroundValidator) {
+		v.breachChecker = checker
+	}
+}
+
+// WithLogger sets the logger used to report failures of optional dependencies, such as
+// a breach corpus that cannot be read.
+func WithLogger(l logger.Logger) Option {
+	return func(v *GoPlaygroundValidator) {
+		v.logger = l
+	}
+}
+
+// WithPasswordPolicy sets the policy behind the password tag. Without it the tag uses password.DefaultPolicy.
+func WithPasswordPolicy(policy password.Policy) Option {
+	return func(v *GoPlaygroundValidator) {
+		v.passwordPolicy = policy
+	}
 }
 
 // New creates a new GoPlaygroundValidator instance with English as the default locale
@@ -59,7 +101,7 @@ func New() Validator {
 }
 
 // NewGoPlaygroundValidatorWithLocale creates a new GoPlaygroundValidator instance with the specified locale
-func NewGoPlaygroundValidatorWithLocale(locale Locale) *GoPlaygroundValidator {
+func NewGoPlaygroundValidatorWithLocale(locale Locale, opts ...Option) *GoPlaygroundValidator {
 	validate := validator.New()
 
 	// Register a custom tag name function to use "json" tags for field names
@@ -72,8 +114,12 @@ func NewGoPlaygroundValidatorWithLocale(locale Locale) *GoPlaygroundValidator {
 	})
 
 	v := &GoPlaygroundValidator{
-		validate: validate,
-		locale:   locale,
+		validate:       validate,
+		locale:         locale,
+		passwordPolicy: password.DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(v)
 	}
 
 	// Register custom validators
@@ -108,6 +154,17 @@ func (v *GoPlaygroundValidator) Validate(i any) error {
 				Value:   fmt.Sprintf("%v", fieldErr.Value()),
 				Message: v.getErrorMessage(fieldErr),
 			}
+
+			// Password errors say exactly which rules failed instead of restating the whole policy
+			if policy, ok := v.policyForTag(fieldErr.Tag()); ok {
+				violations := policy.Check(validationError.Value, personalInfo(i)...)
+				if len(violations) > 0 {
+					validationError.Message = v.getPasswordPolicyMessage(fieldErr.Field(), violations)
+					for _, violation := range violations {
+						validationError.Rules = append(validationError.Rules, string(violation.Rule))
+					}
+				}
+			}
 			validationErrors = append(validationErrors, validationError)
 		}
 	}
@@ -143,6 +200,34 @@ func (v *GoPlaygroundValidator) getErrorMessage(fe validator.FieldError) string
 	}
 }
 
+// policyForTag returns the password policy enforced by a password tag
+func (v *GoPlaygroundValidator) policyForTag(tag string) (password.Policy, bool) {
+	switch tag {
+	case "password":
+		return v.passwordPolicy, true
+	case "strong_password":
+		return password.StrongPolicy(), true
+	}
+	return password.Policy{}, false
+}
+
+// personalInfo returns the account details a request carries for the password policy
+func personalInfo(i any) []string {
+	if provider, ok := i.(PersonalInfoProvider); ok {
+		return provider.PersonalInfo()
+	}
+	return nil
+}
+
+// fieldPersonalInfo returns the personal details of the request a field belongs to
+func fieldPersonalInfo(fl validator.FieldLevel) []string {
+	top := fl.Top()
+	if !top.IsValid() || !top.CanInterface() {
+		return nil
+	}
+	return personalInfo(top.Interface())
+}
+
 // Helper functions for convenience
 
 // IsValidationErrors checks if the error is of type ValidationErrors
@@ -165,6 +250,8 @@ func (v *GoPlaygroundValidator) registerCustomValidators() {
 	v.validate.RegisterValidation("password", v.validatePassword)
 	// Strong password with stricter rules
 	v.validate.RegisterValidation("strong_password", v.validateStrongPassword)
+	// Password not found in the breached-password corpus
+	v.validate.RegisterValidation("notbreached", v.validateNotBreached)
 	// Indonesian phone number
 	v.validate.RegisterValidation("phone_id", v.validateIndonesianPhone)
 	// Indonesian postal code
@@ -179,98 +266,35 @@ func (v *GoPlaygroundValidator) registerCustomValidators() {
 	v.validate.RegisterValidation("currency_id", v.validateIndonesianCurrency)
 }
 
-// validatePassword validates password with standard rules:
-// - Minimum 6 characters
-// - At least 1 lowercase letter
-// - At least 1 uppercase letter
-// - At least 1 digit
-// - At least 1 special character
+// validatePassword validates a password against the configured password policy
 func (v *GoPlaygroundValidator) validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-
-	// Minimum length
-	if len(password) < 6 {
-		return false
-	}
-
-	var (
-		hasLower   = false
-		hasUpper   = false
-		hasNumber  = false
-		hasSpecial = false
-	)
-
-	for _, char := range password {
-		switch {
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsNumber(char):
-			hasNumber = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
-		}
-	}
-
-	return hasLower && hasUpper && hasNumber && hasSpecial
+	return len(v.passwordPolicy.Check(fl.Field().String(), fieldPersonalInfo(fl)...)) == 0
 }
 
-// validateStrongPassword validates password with stricter rules:
+// validateStrongPassword validates a password against password.StrongPolicy:
 // - Minimum 8 characters
-// - At least 2 lowercase letters
-// - At least 2 uppercase letters
-// - At least 2 digits
-// - At least 2 special characters
+// - At least 2 lowercase letters, 2 uppercase letters, 2 digits and 2 special characters
 // - No common patterns or sequences
 func (v *GoPlaygroundValidator) validateStrongPassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-
-	// Minimum length
-	if len(password) < 8 {
-		return false
-	}
-
-	var (
-		lowerCount   = 0
-		upperCount   = 0
-		numberCount  = 0
-		specialCount = 0
-	)
-
-	for _, char := range password {
-		switch {
-		case unicode.IsLower(char):
-			lowerCount++
-		case unicode.IsUpper(char):
-			upperCount++
-		case unicode.IsNumber(char):
-			numberCount++
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			specialCount++
-		}
-	}
-
-	// Check minimum counts
-	if lowerCount < 2 || upperCount < 2 || numberCount < 2 || specialCount < 2 {
-		return false
-	}
+	return len(password.StrongPolicy().Check(fl.Field().String(), fieldPersonalInfo(fl)...)) == 0
+}
 
-	// Check for common weak patterns
-	lowerPass := strings.ToLower(password)
-	weakPatterns := []string{
-		"123456", "654321", "abcdef", "fedcba",
-		"qwerty", "asdfgh", "zxcvbn", "password",
-		"admin", "user", "guest", "test",
+// validateNotBreached rejects passwords found in the breached-password corpus. A corpus
+// that cannot be read lets the password through rather than blocking every password change,
+// and the error is logged so the missing check does not go unnoticed.
+func (v *GoPlaygroundValidator) validateNotBreached(fl validator.FieldLevel) bool {
+	if v.breachChecker == nil {
+		return true
 	}
 
-	for _, pattern := range weakPatterns {
-		if strings.Contains(lowerPass, pattern) {
-			return false
+	breached, err := v.breachChecker.IsBreached(fl.Field().String())
+	if err != nil {
+		if v.logger != nil {
+			v.logger.Error("Failed to check password against the breach corpus, accepting it", "field", fl.FieldName(), "error", err)
 		}
+		return true
 	}
-
-	return true
+	return !breached
 }
 
 // validateIndonesianPhone validates an Indonesian phone number (e.g., +6281234567890 or 081234567890)
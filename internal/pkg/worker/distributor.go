@@ -24,4 +24,58 @@ type TaskDistributor interface {
 		payload *PayloadSendForgotPasswordEmail,
 		opts ...asynq.Option,
 	) error
+
+	DistributeTaskSendAccountLockedEmail(
+		ctx context.Context,
+		payload *PayloadSendAccountLockedEmail,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendPasswordChangedEmail(
+		ctx context.Context,
+		payload *PayloadSendPasswordChangedEmail,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendEmailChangeVerification(
+		ctx context.Context,
+		payload *PayloadSendEmailChangeVerification,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendEmailChangeNotice(
+		ctx context.Context,
+		payload *PayloadSendEmailChangeNotice,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendAccountDeletionScheduledEmail(
+		ctx context.Context,
+		payload *PayloadSendAccountDeletionScheduledEmail,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendNewLoginAlertEmail(
+		ctx context.Context,
+		payload *PayloadSendNewLoginAlertEmail,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendVerificationLinkEmail(
+		ctx context.Context,
+		payload *PayloadSendVerificationLinkEmail,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendPasswordResetLinkEmail(
+		ctx context.Context,
+		payload *PayloadSendPasswordResetLinkEmail,
+		opts ...asynq.Option,
+	) error
+
+	DistributeTaskSendInviteEmail(
+		ctx context.Context,
+		payload *PayloadSendInviteEmail,
+		opts ...asynq.Option,
+	) error
 }
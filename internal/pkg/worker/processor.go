@@ -24,6 +24,15 @@ type TaskProcessor interface {
 	ProcessTaskHello(ctx context.Context, task *asynq.Task) error
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
 	ProcessTaskSendForgotPasswordEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendAccountLockedEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendPasswordChangedEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendEmailChangeVerification(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendEmailChangeNotice(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendAccountDeletionScheduledEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendNewLoginAlertEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendVerificationLinkEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendPasswordResetLinkEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendInviteEmail(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
@@ -79,6 +88,15 @@ func (p *RedisTaskProcessor) Start() error {
 	mux.HandleFunc(TaskHello, p.ProcessTaskHello)
 	mux.HandleFunc(TaskSendVerifyEmail, p.ProcessTaskSendVerifyEmail)
 	mux.HandleFunc(TaskSendForgotPasswordEmail, p.ProcessTaskSendForgotPasswordEmail)
+	mux.HandleFunc(TaskSendAccountLockedEmail, p.ProcessTaskSendAccountLockedEmail)
+	mux.HandleFunc(TaskSendPasswordChangedEmail, p.ProcessTaskSendPasswordChangedEmail)
+	mux.HandleFunc(TaskSendEmailChangeVerification, p.ProcessTaskSendEmailChangeVerification)
+	mux.HandleFunc(TaskSendEmailChangeNotice, p.ProcessTaskSendEmailChangeNotice)
+	mux.HandleFunc(TaskSendAccountDeletionScheduledEmail, p.ProcessTaskSendAccountDeletionScheduledEmail)
+	mux.HandleFunc(TaskSendNewLoginAlertEmail, p.ProcessTaskSendNewLoginAlertEmail)
+	mux.HandleFunc(TaskSendVerificationLinkEmail, p.ProcessTaskSendVerificationLinkEmail)
+	mux.HandleFunc(TaskSendPasswordResetLinkEmail, p.ProcessTaskSendPasswordResetLinkEmail)
+	mux.HandleFunc(TaskSendInviteEmail, p.ProcessTaskSendInviteEmail)
 
 	return p.server.Start(mux)
 }
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendAccountDeletionScheduledEmailMaxRetry = 3
+	TaskSendAccountDeletionScheduledEmail         = "task:send_account_deletion_scheduled_email"
+	TaskSendAccountDeletionScheduledEmailSubject  = "Akun Anda Dijadwalkan untuk Dihapus"
+)
+
+type PayloadSendAccountDeletionScheduledEmail struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	DeletionDate string    `json:"deletion_date"`
+
+	// fill by distributor
+	From      string `json:"from"`
+	Subject   string `json:"subject"`
+	LoginLink string `json:"login_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendAccountDeletionScheduledEmail(
+	ctx context.Context,
+	payload *PayloadSendAccountDeletionScheduledEmail,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendAccountDeletionScheduledEmailSubject
+	payload.From = d.cfg.AppName
+	payload.LoginLink = fmt.Sprintf("%s/login", d.cfg.AppFrontendURL)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendAccountDeletionScheduledEmail, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendAccountDeletionScheduledEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendAccountDeletionScheduledEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailAccountDeletionScheduledTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse account deletion scheduled email template", "error", err)
+		return fmt.Errorf("failed to parse account deletion scheduled email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute account deletion scheduled email template", "error", err)
+		return fmt.Errorf("failed to execute account deletion scheduled email template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send account deletion scheduled email", "error", err)
+		return fmt.Errorf("failed to send account deletion scheduled email: %w", err)
+	}
+
+	p.logger.Info("account deletion scheduled email sent", "email", payload.Email)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendAccountLockedEmailMaxRetry = 3
+	TaskSendAccountLockedEmail         = "task:send_account_locked_email"
+	TaskSendAccountLockedEmailSubject  = "Akun Anda Dikunci Sementara"
+)
+
+type PayloadSendAccountLockedEmail struct {
+	UserID               uuid.UUID `json:"user_id"`
+	Name                 string    `json:"name"`
+	Email                string    `json:"email"`
+	LockedUntil          string    `json:"locked_until"`
+	UnlockToken          string    `json:"unlock_token"`
+	UnlockLinkExpiration int       `json:"unlock_link_expiration"` // in minutes
+
+	// fill by distributor
+	From       string `json:"from"`
+	Subject    string `json:"subject"`
+	UnlockLink string `json:"unlock_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendAccountLockedEmail(
+	ctx context.Context,
+	payload *PayloadSendAccountLockedEmail,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendAccountLockedEmailSubject
+	payload.From = d.cfg.AppName
+	payload.UnlockLink = fmt.Sprintf("%s/unlock-account/%s", d.cfg.AppFrontendURL, payload.UnlockToken)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendAccountLockedEmail, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendAccountLockedEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendAccountLockedEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailAccountLockedTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse account locked email template", "error", err)
+		return fmt.Errorf("failed to parse account locked email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute account locked email template", "error", err)
+		return fmt.Errorf("failed to execute account locked email template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send account locked email", "error", err)
+		return fmt.Errorf("failed to send account locked email: %w", err)
+	}
+
+	p.logger.Info("account locked email sent", "email", payload.Email)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendEmailChangeNoticeMaxRetry = 3
+	TaskSendEmailChangeNotice         = "task:send_email_change_notice"
+	TaskSendEmailChangeNoticeSubject  = "Permintaan Perubahan Alamat Email"
+)
+
+type PayloadSendEmailChangeNotice struct {
+	UserID               uuid.UUID `json:"user_id"`
+	Name                 string    `json:"name"`
+	Email                string    `json:"email"` // the current address
+	NewEmail             string    `json:"new_email"`
+	CancelToken          string    `json:"cancel_token"`
+	CancelLinkExpiration int       `json:"cancel_link_expiration"` // in minutes
+
+	// fill by distributor
+	From       string `json:"from"`
+	Subject    string `json:"subject"`
+	CancelLink string `json:"cancel_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendEmailChangeNotice(
+	ctx context.Context,
+	payload *PayloadSendEmailChangeNotice,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendEmailChangeNoticeSubject
+	payload.From = d.cfg.AppName
+	payload.CancelLink = fmt.Sprintf("%s/cancel-email-change/%s", d.cfg.AppFrontendURL, payload.CancelToken)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendEmailChangeNotice, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendEmailChangeNotice(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendEmailChangeNotice
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailChangeNoticeTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse email change notice template", "error", err)
+		return fmt.Errorf("failed to parse email change notice template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute email change notice template", "error", err)
+		return fmt.Errorf("failed to execute email change notice template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send email change notice", "error", err)
+		return fmt.Errorf("failed to send email change notice: %w", err)
+	}
+
+	p.logger.Info("email change notice sent", "email", payload.Email)
+
+	return nil
+}
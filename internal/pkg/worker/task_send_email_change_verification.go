@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendEmailChangeVerificationMaxRetry = 3
+	TaskSendEmailChangeVerification         = "task:send_email_change_verification"
+	TaskSendEmailChangeVerificationSubject  = "Konfirmasi Alamat Email Baru"
+)
+
+type PayloadSendEmailChangeVerification struct {
+	UserID                     uuid.UUID `json:"user_id"`
+	Name                       string    `json:"name"`
+	Email                      string    `json:"email"` // the new address
+	VerificationCode           string    `json:"verification_code"`
+	VerificationCodeExpiration int       `json:"verification_code_expiration"` // in minutes
+
+	// fill by distributor
+	From    string `json:"from"`
+	Subject string `json:"subject"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendEmailChangeVerification(
+	ctx context.Context,
+	payload *PayloadSendEmailChangeVerification,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendEmailChangeVerificationSubject
+	payload.From = d.cfg.AppName
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendEmailChangeVerification, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendEmailChangeVerification(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendEmailChangeVerification
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailChangeVerificationTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse email change verification template", "error", err)
+		return fmt.Errorf("failed to parse email change verification template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute email change verification template", "error", err)
+		return fmt.Errorf("failed to execute email change verification template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send email change verification", "error", err)
+		return fmt.Errorf("failed to send email change verification: %w", err)
+	}
+
+	p.logger.Info("email change verification sent", "email", payload.Email)
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendInviteEmailMaxRetry = 3
+	TaskSendInviteEmail         = "task:send_invite_email"
+	TaskSendInviteEmailSubject  = "Undangan Membuat Akun"
+)
+
+type PayloadSendInviteEmail struct {
+	InviteID         uuid.UUID `json:"invite_id"`
+	Email            string    `json:"email"`
+	InviterName      string    `json:"inviter_name"`
+	InviteToken      string    `json:"invite_token"`
+	InviteExpiration int       `json:"invite_expiration"` // in days
+
+	// fill by distributor
+	From       string `json:"from"`
+	Subject    string `json:"subject"`
+	InviteLink string `json:"invite_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendInviteEmail(
+	ctx context.Context,
+	payload *PayloadSendInviteEmail,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendInviteEmailSubject
+	payload.From = d.cfg.AppName
+	payload.InviteLink = fmt.Sprintf("%s/accept-invite/%s", d.cfg.AppFrontendURL, payload.InviteToken)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendInviteEmail, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendInviteEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendInviteEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailInviteTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse invite email template", "error", err)
+		return fmt.Errorf("failed to parse invite email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute invite email template", "error", err)
+		return fmt.Errorf("failed to execute invite email template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send invite email", "error", err)
+		return fmt.Errorf("failed to send invite email: %w", err)
+	}
+
+	p.logger.Info("invite email sent", "email", payload.Email)
+
+	return nil
+}
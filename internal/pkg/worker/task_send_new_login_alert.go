@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendNewLoginAlertEmailMaxRetry = 3
+	TaskSendNewLoginAlertEmail         = "task:send_new_login_alert_email"
+	TaskSendNewLoginAlertEmailSubject  = "Login Baru ke Akun Anda"
+)
+
+type PayloadSendNewLoginAlertEmail struct {
+	UserID               uuid.UUID `json:"user_id"`
+	Name                 string    `json:"name"`
+	Email                string    `json:"email"`
+	LoginAt              string    `json:"login_at"`
+	Device               string    `json:"device"`
+	IPAddress            string    `json:"ip_address"`
+	Location             string    `json:"location"` // empty when the location is unknown
+	RevokeToken          string    `json:"revoke_token"`
+	RevokeLinkExpiration int       `json:"revoke_link_expiration"` // in days
+
+	// fill by distributor
+	From              string `json:"from"`
+	Subject           string `json:"subject"`
+	RevokeLink        string `json:"revoke_link"`
+	ResetPasswordLink string `json:"reset_password_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendNewLoginAlertEmail(
+	ctx context.Context,
+	payload *PayloadSendNewLoginAlertEmail,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendNewLoginAlertEmailSubject
+	payload.From = d.cfg.AppName
+	payload.RevokeLink = fmt.Sprintf("%s/report-login/%s", d.cfg.AppFrontendURL, payload.RevokeToken)
+	payload.ResetPasswordLink = fmt.Sprintf("%s/forgot-password", d.cfg.AppFrontendURL)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendNewLoginAlertEmail, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendNewLoginAlertEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendNewLoginAlertEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailNewLoginAlertTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse new login alert email template", "error", err)
+		return fmt.Errorf("failed to parse new login alert email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute new login alert email template", "error", err)
+		return fmt.Errorf("failed to execute new login alert email template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send new login alert email", "error", err)
+		return fmt.Errorf("failed to send new login alert email: %w", err)
+	}
+
+	p.logger.Info("new login alert email sent", "email", payload.Email)
+
+	return nil
+}
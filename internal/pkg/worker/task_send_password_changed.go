@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendPasswordChangedEmailMaxRetry = 3
+	TaskSendPasswordChangedEmail         = "task:send_password_changed_email"
+	TaskSendPasswordChangedEmailSubject  = "Kata Sandi Anda Telah Diubah"
+)
+
+type PayloadSendPasswordChangedEmail struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	ChangedAt string    `json:"changed_at"`
+	Device    string    `json:"device"`
+	IPAddress string    `json:"ip_address"`
+
+	// fill by distributor
+	From              string `json:"from"`
+	Subject           string `json:"subject"`
+	ResetPasswordLink string `json:"reset_password_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendPasswordChangedEmail(
+	ctx context.Context,
+	payload *PayloadSendPasswordChangedEmail,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendPasswordChangedEmailSubject
+	payload.From = d.cfg.AppName
+	payload.ResetPasswordLink = fmt.Sprintf("%s/forgot-password", d.cfg.AppFrontendURL)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendPasswordChangedEmail, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendPasswordChangedEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendPasswordChangedEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailPasswordChangedTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse password changed email template", "error", err)
+		return fmt.Errorf("failed to parse password changed email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute password changed email template", "error", err)
+		return fmt.Errorf("failed to execute password changed email template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send password changed email", "error", err)
+		return fmt.Errorf("failed to send password changed email: %w", err)
+	}
+
+	p.logger.Info("password changed email sent", "email", payload.Email)
+
+	return nil
+}
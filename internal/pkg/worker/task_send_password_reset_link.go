@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendPasswordResetLinkEmailMaxRetry = 3
+	TaskSendPasswordResetLinkEmail         = "task:send_password_reset_link_email"
+	TaskSendPasswordResetLinkEmailSubject  = "Permintaan Reset Password"
+)
+
+type PayloadSendPasswordResetLinkEmail struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	LinkToken      string    `json:"link_token"`
+	LinkExpiration int       `json:"link_expiration"` // in minutes
+
+	// fill by distributor
+	From      string `json:"from"`
+	Subject   string `json:"subject"`
+	ResetLink string `json:"reset_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendPasswordResetLinkEmail(
+	ctx context.Context,
+	payload *PayloadSendPasswordResetLinkEmail,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendPasswordResetLinkEmailSubject
+	payload.From = d.cfg.AppName
+	payload.ResetLink = fmt.Sprintf("%s/reset-password/link/%s", d.cfg.AppFrontendURL, payload.LinkToken)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendPasswordResetLinkEmail, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendPasswordResetLinkEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendPasswordResetLinkEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailPasswordResetLinkTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse password reset link email template", "error", err)
+		return fmt.Errorf("failed to parse password reset link email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute password reset link email template", "error", err)
+		return fmt.Errorf("failed to execute password reset link email template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send password reset link email", "error", err)
+		return fmt.Errorf("failed to send password reset link email: %w", err)
+	}
+
+	p.logger.Info("password reset link email sent", "email", payload.Email)
+
+	return nil
+}
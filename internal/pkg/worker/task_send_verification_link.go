@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/sammidev/goca/internal/pkg/assets"
+)
+
+const (
+	TaskSendVerificationLinkEmailMaxRetry = 3
+	TaskSendVerificationLinkEmail         = "task:send_verification_link_email"
+	TaskSendVerificationLinkEmailSubject  = "Verifikasi Email"
+)
+
+type PayloadSendVerificationLinkEmail struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	LinkToken      string    `json:"link_token"`
+	LinkExpiration int       `json:"link_expiration"` // in minutes
+
+	// fill by distributor
+	From             string `json:"from"`
+	Subject          string `json:"subject"`
+	VerificationLink string `json:"verification_link"`
+}
+
+func (d *RedisTaskDistributor) DistributeTaskSendVerificationLinkEmail(
+	ctx context.Context,
+	payload *PayloadSendVerificationLinkEmail,
+	opts ...asynq.Option,
+) error {
+	payload.Subject = TaskSendVerificationLinkEmailSubject
+	payload.From = d.cfg.AppName
+	payload.VerificationLink = fmt.Sprintf("%s/verify-email/%s", d.cfg.AppFrontendURL, payload.LinkToken)
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendVerificationLinkEmail, jsonPayload, opts...)
+
+	_, err = d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendVerificationLinkEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendVerificationLinkEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		p.logger.Error("failed to unmarshal payload", "error", err)
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	tpl, err := template.ParseFS(assets.EmbeddedFiles, assets.EmailVerificationLinkTemplatePath)
+	if err != nil {
+		p.logger.Error("failed to parse verification link email template", "error", err)
+		return fmt.Errorf("failed to parse verification link email template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tpl.ExecuteTemplate(&body, "htmlBody", payload); err != nil {
+		p.logger.Error("failed to execute verification link email template", "error", err)
+		return fmt.Errorf("failed to execute verification link email template: %w", err)
+	}
+
+	err = p.email.Send(payload.Email, payload.Subject, body.String(), payload)
+	if err != nil {
+		p.logger.Error("failed to send verification link email", "error", err)
+		return fmt.Errorf("failed to send verification link email: %w", err)
+	}
+
+	p.logger.Info("verification link email sent", "email", payload.Email)
+
+	return nil
+}
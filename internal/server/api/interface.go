@@ -10,9 +10,46 @@ type UserHandler interface {
 	RefreshToken(c *fiber.Ctx) error
 	ForgotPassword(c *fiber.Ctx) error
 	ResetPassword(c *fiber.Ctx) error
+	ConsumeLink(c *fiber.Ctx) error
+	ChangePassword(c *fiber.Ctx) error
+	RequestEmailChange(c *fiber.Ctx) error
+	ConfirmEmailChange(c *fiber.Ctx) error
+	CancelEmailChange(c *fiber.Ctx) error
+	Reauthenticate(c *fiber.Ctx) error
 	Setup2FA(c *fiber.Ctx) error
 	Verify2FA(c *fiber.Ctx) error
+	Complete2FAChallenge(c *fiber.Ctx) error
 	Disable2FA(c *fiber.Ctx) error
+	GetProfile(c *fiber.Ctx) error
+	UpdateProfile(c *fiber.Ctx) error
+	DeleteAccount(c *fiber.Ctx) error
+	GetRecoveryCodesStatus(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+	LogoutAll(c *fiber.Ctx) error
+	ListSessions(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	CreatePersonalAccessToken(c *fiber.Ctx) error
+	ListPersonalAccessTokens(c *fiber.Ctx) error
+	RevokePersonalAccessToken(c *fiber.Ctx) error
+	RevokeOtherSessions(c *fiber.Ctx) error
+	UnlockAccount(c *fiber.Ctx) error
+	ReportLogin(c *fiber.Ctx) error
+	AdminUnlockAccount(c *fiber.Ctx) error
+	AdminListUsers(c *fiber.Ctx) error
+	AdminGetUser(c *fiber.Ctx) error
+	AdminSuspendUser(c *fiber.Ctx) error
+	AdminReactivateUser(c *fiber.Ctx) error
+	AdminVerifyEmail(c *fiber.Ctx) error
+	AdminReset2FA(c *fiber.Ctx) error
+	AdminResetPassword(c *fiber.Ctx) error
+	AdminListRoles(c *fiber.Ctx) error
+	AdminListSecurityEvents(c *fiber.Ctx) error
+	ListSecurityEvents(c *fiber.Ctx) error
+	AdminAssignRole(c *fiber.Ctx) error
+	AdminCreateInvite(c *fiber.Ctx) error
+	AdminListInvites(c *fiber.Ctx) error
+	AdminRevokeInvite(c *fiber.Ctx) error
 }
 
 type NoteHandler interface {
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/authz"
 	"github.com/sammidev/goca/internal/pkg/response"
 	"github.com/sammidev/goca/internal/pkg/token"
 )
@@ -23,7 +25,34 @@ var (
 	ErrInvalidToken      = errors.New("token tidak valid atau telah kedaluwarsa")
 )
 
-func AuthMiddleware(tokenMaker token.Token) fiber.Handler {
+// PersonalAccessTokenAuthenticator memverifikasi personal access token yang disimpan
+// di database dan mengubahnya menjadi payload seperti access token biasa.
+type PersonalAccessTokenAuthenticator interface {
+	AuthenticatePersonalAccessToken(ctx context.Context, value, ipAddress string) (*token.Payload, error)
+}
+
+// AuthOption mengatur jenis kredensial tambahan yang diterima AuthMiddleware.
+type AuthOption func(*authOptions)
+
+type authOptions struct {
+	pats PersonalAccessTokenAuthenticator
+}
+
+// WithPersonalAccessTokens membuat AuthMiddleware menerima personal access token selain
+// access token. Setiap route di belakangnya harus dibatasi dengan RequireScope atau
+// RequirePermission agar token hanya bisa dipakai sesuai scope-nya.
+func WithPersonalAccessTokens(authenticator PersonalAccessTokenAuthenticator) AuthOption {
+	return func(o *authOptions) {
+		o.pats = authenticator
+	}
+}
+
+func AuthMiddleware(tokenMaker token.Token, denylist token.Denylist, opts ...AuthOption) fiber.Handler {
+	var options authOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return func(ctx *fiber.Ctx) error {
 		authHeader := ctx.Get(authorizationHeaderKey)
 		if authHeader == "" {
@@ -38,17 +67,48 @@ func AuthMiddleware(tokenMaker token.Token) fiber.Handler {
 		}
 
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		if token.IsPersonalAccessToken(accessToken) {
+			if options.pats == nil {
+				appErr := apperror.NewAppError(apperror.ErrCodeUnauthorized, "Personal access token tidak dapat dipakai untuk endpoint ini.")
+				return response.HandleErrorAPI(ctx, appErr)
+			}
+
+			payload, err := options.pats.AuthenticatePersonalAccessToken(ctx.UserContext(), accessToken, ctx.IP())
+			if err != nil {
+				return response.HandleErrorAPI(ctx, err)
+			}
+
+			return authenticated(ctx, payload)
+		}
+
+		payload, err := tokenMaker.VerifyToken(accessToken, token.TokenTypeAccess)
 		if err != nil {
 			appErr := apperror.NewAppError(apperror.ErrCodeUnauthorized, "Token tidak valid atau telah kadaluwarsa.")
 			return response.HandleErrorAPI(ctx, appErr)
 		}
 
-		ctx.Locals(authorizationPayloadKey, payload)
-		return ctx.Next()
+		revoked, err := denylist.IsRevoked(ctx.UserContext(), payload)
+		if err != nil {
+			appErr := apperror.WrapError(err, apperror.ErrCodeInternalError, "Gagal memeriksa status token.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+		if revoked {
+			appErr := apperror.NewAppError(apperror.ErrCodeUnauthorized, "Token telah dicabut. Silakan login kembali.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+
+		return authenticated(ctx, payload)
 	}
 }
 
+// authenticated menyimpan payload pada request lalu melanjutkan ke handler berikutnya.
+func authenticated(ctx *fiber.Ctx, payload *token.Payload) error {
+	ctx.Locals(authorizationPayloadKey, payload)
+	// Permission juga diteruskan ke service lewat context untuk pengecekan per resource
+	ctx.SetUserContext(authz.NewContext(ctx.UserContext(), payload.Permissions))
+	return ctx.Next()
+}
+
 func GetUser(ctx *fiber.Ctx) *token.Payload {
 	payload, ok := ctx.Locals(authorizationPayloadKey).(*token.Payload)
 	if !ok {
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/response"
+)
+
+// RequirePermission memastikan access token membawa semua permission yang diminta.
+// Permission dibaca dari token, sehingga perubahan role baru berlaku setelah token
+// diperbarui. Harus dipasang setelah AuthMiddleware.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		user := GetUser(ctx)
+		if user == nil {
+			appErr := apperror.NewAppError(apperror.ErrCodeUnauthorized, "Authorization header tidak ditemukan.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+
+		for _, permission := range permissions {
+			if !user.HasPermission(permission) {
+				appErr := apperror.NewAppError(apperror.ErrCodeForbidden, "Anda tidak memiliki izin untuk melakukan aksi ini.")
+				return response.HandleErrorAPI(ctx, appErr)
+			}
+		}
+
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/pkg/authz"
+	"github.com/sammidev/goca/internal/pkg/token"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRequirePermission(t *testing.T) {
+	Convey("Diberikan route yang membutuhkan permission", t, func() {
+		maker := newTestTokenMaker()
+		denylist := newStubDenylist()
+
+		app := fiber.New()
+		app.Get("/admin", AuthMiddleware(maker, denylist), RequirePermission(authz.UsersRead, authz.UsersManage), func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusNoContent)
+		})
+		app.Get("/unauthenticated", RequirePermission(authz.UsersRead), func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusNoContent)
+		})
+
+		send := func(path string, permissions ...string) *http.Response {
+			accessToken := generateTestToken(maker, uuid.New(), token.TokenTypeAccess, token.WithPermissions(permissions...))
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set(authorizationHeaderKey, "Bearer "+accessToken.Value)
+			resp, err := app.Test(req)
+			So(err, ShouldBeNil)
+			return resp
+		}
+
+		Convey("Ketika token membawa semua permission yang diminta", func() {
+			Convey("Maka request diteruskan", func() {
+				So(send("/admin", authz.UsersRead, authz.UsersManage).StatusCode, ShouldEqual, http.StatusNoContent)
+			})
+		})
+
+		Convey("Ketika token hanya membawa sebagian permission", func() {
+			Convey("Maka request ditolak", func() {
+				So(send("/admin", authz.UsersRead).StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika token tidak membawa permission", func() {
+			Convey("Maka request ditolak", func() {
+				So(send("/admin").StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika middleware dipasang tanpa AuthMiddleware", func() {
+			Convey("Maka request ditolak sebagai tidak terautentikasi", func() {
+				So(send("/unauthenticated", authz.UsersRead).StatusCode, ShouldEqual, http.StatusUnauthorized)
+			})
+		})
+	})
+}
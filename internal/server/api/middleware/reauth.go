@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/response"
+	"github.com/sammidev/goca/internal/pkg/token"
+)
+
+const (
+	reauthHeaderKey = "X-Reauth-Token"
+)
+
+// RequireReauthentication memastikan user baru saja melakukan re-autentikasi sebelum
+// menjalankan aksi sensitif. Token re-autentikasi hanya berlaku untuk user dan sesi yang
+// sama dengan access token. Harus dipasang setelah AuthMiddleware.
+func RequireReauthentication(tokenMaker token.Token, denylist token.Denylist) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		user := GetUser(ctx)
+		if user == nil {
+			appErr := apperror.NewAppError(apperror.ErrCodeUnauthorized, "Authorization header tidak ditemukan.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+
+		reauthToken := ctx.Get(reauthHeaderKey)
+		if reauthToken == "" {
+			return response.HandleErrorAPI(ctx, apperror.ErrReauthRequired)
+		}
+
+		payload, err := tokenMaker.VerifyToken(reauthToken, token.TokenTypeReauth)
+		if err != nil || payload.UserID != user.UserID || payload.SessionID != user.SessionID {
+			appErr := apperror.NewAppError(apperror.ErrCodeReauthRequired, "Token re-autentikasi tidak valid atau telah kadaluwarsa.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+
+		revoked, err := denylist.IsRevoked(ctx.UserContext(), payload)
+		if err != nil {
+			appErr := apperror.WrapError(err, apperror.ErrCodeInternalError, "Gagal memeriksa status token.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+		if revoked {
+			appErr := apperror.NewAppError(apperror.ErrCodeReauthRequired, "Token re-autentikasi telah dicabut.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+
+		return ctx.Next()
+	}
+}
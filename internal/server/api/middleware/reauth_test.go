@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/config"
+	"github.com/sammidev/goca/internal/pkg/token"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// stubDenylist adalah implementasi token.Denylist di memori untuk pengujian.
+type stubDenylist struct {
+	tokens   map[uuid.UUID]bool
+	sessions map[uuid.UUID]bool
+}
+
+func newStubDenylist() *stubDenylist {
+	return &stubDenylist{tokens: make(map[uuid.UUID]bool), sessions: make(map[uuid.UUID]bool)}
+}
+
+func (d *stubDenylist) RevokeToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error {
+	d.tokens[tokenID] = true
+	return nil
+}
+
+func (d *stubDenylist) ClaimToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) (bool, error) {
+	claimed := !d.tokens[tokenID]
+	d.tokens[tokenID] = true
+	return claimed, nil
+}
+
+func (d *stubDenylist) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+func (d *stubDenylist) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	d.sessions[sessionID] = true
+	return nil
+}
+
+func (d *stubDenylist) IsRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	return d.tokens[payload.ID] || d.sessions[payload.SessionID], nil
+}
+
+func newTestTokenMaker() token.Token {
+	maker, err := token.NewJWT(&config.Config{AuthJWTSecret: strings.Repeat("x", 32), AppName: "test-app"})
+	So(err, ShouldBeNil)
+	return maker
+}
+
+func generateTestToken(maker token.Token, userID uuid.UUID, tokenType token.TokenType, opts ...token.GenerateOption) *token.GenerateTokenResponse {
+	resp, err := maker.GenerateToken(userID, tokenType, time.Hour, opts...)
+	So(err, ShouldBeNil)
+	return resp
+}
+
+func TestRequireReauthentication(t *testing.T) {
+	Convey("Diberikan route yang membutuhkan re-autentikasi", t, func() {
+		maker := newTestTokenMaker()
+		denylist := newStubDenylist()
+
+		app := fiber.New()
+		app.Post("/sensitive", AuthMiddleware(maker, denylist), RequireReauthentication(maker, denylist), func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusNoContent)
+		})
+
+		userID := uuid.New()
+		sessionID := uuid.New()
+		accessToken := generateTestToken(maker, userID, token.TokenTypeAccess, token.WithSessionID(sessionID))
+
+		send := func(reauthToken string) *http.Response {
+			req := httptest.NewRequest(http.MethodPost, "/sensitive", nil)
+			req.Header.Set(authorizationHeaderKey, "Bearer "+accessToken.Value)
+			if reauthToken != "" {
+				req.Header.Set(reauthHeaderKey, reauthToken)
+			}
+			resp, err := app.Test(req)
+			So(err, ShouldBeNil)
+			return resp
+		}
+
+		Convey("Ketika token re-autentikasi milik user dan sesi yang sama", func() {
+			reauthToken := generateTestToken(maker, userID, token.TokenTypeReauth, token.WithSessionID(sessionID))
+
+			Convey("Maka request diteruskan", func() {
+				So(send(reauthToken.Value).StatusCode, ShouldEqual, http.StatusNoContent)
+			})
+		})
+
+		Convey("Ketika header re-autentikasi tidak dikirim", func() {
+			Convey("Maka request ditolak", func() {
+				So(send("").StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika token re-autentikasi milik user lain", func() {
+			reauthToken := generateTestToken(maker, uuid.New(), token.TokenTypeReauth, token.WithSessionID(sessionID))
+
+			Convey("Maka request ditolak", func() {
+				So(send(reauthToken.Value).StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika token re-autentikasi diterbitkan untuk sesi lain", func() {
+			reauthToken := generateTestToken(maker, userID, token.TokenTypeReauth, token.WithSessionID(uuid.New()))
+
+			Convey("Maka request ditolak", func() {
+				So(send(reauthToken.Value).StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika yang dikirim adalah access token", func() {
+			Convey("Maka request ditolak", func() {
+				So(send(accessToken.Value).StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika token re-autentikasi sudah dicabut", func() {
+			reauthToken := generateTestToken(maker, userID, token.TokenTypeReauth, token.WithSessionID(sessionID))
+			So(denylist.RevokeToken(context.Background(), reauthToken.ID, reauthToken.ExpiresAt), ShouldBeNil)
+
+			Convey("Maka request ditolak", func() {
+				So(send(reauthToken.Value).StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/response"
+)
+
+// RequireScope memastikan personal access token membawa semua scope yang diminta.
+// Access token hasil login tidak dibatasi scope sehingga selalu diloloskan.
+// Harus dipasang setelah AuthMiddleware.
+func RequireScope(scopes ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		user := GetUser(ctx)
+		if user == nil {
+			appErr := apperror.NewAppError(apperror.ErrCodeUnauthorized, "Authorization header tidak ditemukan.")
+			return response.HandleErrorAPI(ctx, appErr)
+		}
+
+		if !user.IsPersonalAccessToken() {
+			return ctx.Next()
+		}
+
+		for _, scope := range scopes {
+			if !user.HasScope(scope) {
+				appErr := apperror.NewAppError(apperror.ErrCodeForbidden, "Token tidak memiliki scope yang dibutuhkan untuk aksi ini.")
+				return response.HandleErrorAPI(ctx, appErr)
+			}
+		}
+
+		return ctx.Next()
+	}
+}
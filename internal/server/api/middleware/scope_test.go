@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sammidev/goca/internal/pkg/apperror"
+	"github.com/sammidev/goca/internal/pkg/authz"
+	"github.com/sammidev/goca/internal/pkg/token"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testPersonalAccessToken = token.PersonalAccessTokenPrefix + "test"
+
+// stubPersonalAccessTokens mengembalikan payload yang sudah ditentukan untuk setiap
+// personal access token.
+type stubPersonalAccessTokens struct {
+	payload *token.Payload
+	err     error
+}
+
+func (s *stubPersonalAccessTokens) AuthenticatePersonalAccessToken(ctx context.Context, value, ipAddress string) (*token.Payload, error) {
+	return s.payload, s.err
+}
+
+func newPersonalAccessTokenPayload(scopes, permissions []string) *token.Payload {
+	return &token.Payload{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Type:        token.TokenTypePersonalAccess,
+		Audience:    token.AudienceAPI,
+		IssuedAt:    time.Now(),
+		Scopes:      scopes,
+		Permissions: permissions,
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	Convey("Diberikan route yang dibatasi scope", t, func() {
+		maker := newTestTokenMaker()
+		denylist := newStubDenylist()
+		pats := &stubPersonalAccessTokens{}
+
+		app := fiber.New()
+		protected := app.Group("/", AuthMiddleware(maker, denylist, WithPersonalAccessTokens(pats)))
+		protected.Get("/notes", RequireScope(authz.ScopeNotesRead), func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusNoContent)
+		})
+		protected.Get("/admin/users", RequireScope(authz.UsersRead), RequirePermission(authz.UsersRead), func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusNoContent)
+		})
+
+		send := func(path, bearer string) *http.Response {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set(authorizationHeaderKey, "Bearer "+bearer)
+			resp, err := app.Test(req)
+			So(err, ShouldBeNil)
+			return resp
+		}
+
+		Convey("Ketika request memakai access token hasil login", func() {
+			accessToken := generateTestToken(maker, uuid.New(), token.TokenTypeAccess)
+
+			Convey("Maka scope tidak diperiksa", func() {
+				So(send("/notes", accessToken.Value).StatusCode, ShouldEqual, http.StatusNoContent)
+			})
+		})
+
+		Convey("Ketika personal access token membawa scope yang diminta", func() {
+			pats.payload = newPersonalAccessTokenPayload([]string{authz.ScopeNotesRead}, nil)
+
+			Convey("Maka request diteruskan", func() {
+				So(send("/notes", testPersonalAccessToken).StatusCode, ShouldEqual, http.StatusNoContent)
+			})
+		})
+
+		Convey("Ketika personal access token tidak membawa scope yang diminta", func() {
+			pats.payload = newPersonalAccessTokenPayload([]string{authz.ScopeNotesWrite}, nil)
+
+			Convey("Maka request ditolak", func() {
+				So(send("/notes", testPersonalAccessToken).StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika scope personal access token tidak lagi diberikan oleh role pemiliknya", func() {
+			pats.payload = newPersonalAccessTokenPayload([]string{authz.UsersRead}, []string{})
+
+			Convey("Maka request ditolak oleh pemeriksaan permission", func() {
+				So(send("/admin/users", testPersonalAccessToken).StatusCode, ShouldEqual, http.StatusForbidden)
+			})
+		})
+
+		Convey("Ketika scope personal access token masih diberikan oleh role pemiliknya", func() {
+			pats.payload = newPersonalAccessTokenPayload([]string{authz.UsersRead}, []string{authz.UsersRead})
+
+			Convey("Maka request diteruskan", func() {
+				So(send("/admin/users", testPersonalAccessToken).StatusCode, ShouldEqual, http.StatusNoContent)
+			})
+		})
+	})
+}
+
+func TestAuthMiddlewarePersonalAccessToken(t *testing.T) {
+	Convey("Diberikan AuthMiddleware dan personal access token", t, func() {
+		maker := newTestTokenMaker()
+		denylist := newStubDenylist()
+		pats := &stubPersonalAccessTokens{payload: newPersonalAccessTokenPayload([]string{authz.ScopeProfileRead}, nil)}
+
+		var user *token.Payload
+		handler := func(c *fiber.Ctx) error {
+			user = GetUser(c)
+			return c.SendStatus(http.StatusNoContent)
+		}
+
+		app := fiber.New()
+		app.Get("/with-pats", AuthMiddleware(maker, denylist, WithPersonalAccessTokens(pats)), handler)
+		app.Get("/without-pats", AuthMiddleware(maker, denylist), handler)
+
+		send := func(path string) *http.Response {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set(authorizationHeaderKey, "Bearer "+testPersonalAccessToken)
+			resp, err := app.Test(req)
+			So(err, ShouldBeNil)
+			return resp
+		}
+
+		Convey("Ketika route menerima personal access token", func() {
+			resp := send("/with-pats")
+
+			Convey("Maka payload dari authenticator disimpan pada request", func() {
+				So(resp.StatusCode, ShouldEqual, http.StatusNoContent)
+				So(user, ShouldEqual, pats.payload)
+			})
+		})
+
+		Convey("Ketika route tidak menerima personal access token", func() {
+			Convey("Maka request ditolak", func() {
+				So(send("/without-pats").StatusCode, ShouldEqual, http.StatusUnauthorized)
+			})
+		})
+
+		Convey("Ketika personal access token tidak valid", func() {
+			pats.err = apperror.NewAppError(apperror.ErrCodeUnauthorized, "Personal access token is invalid, expired or revoked")
+
+			Convey("Maka error dari authenticator dikembalikan", func() {
+				So(send("/with-pats").StatusCode, ShouldEqual, http.StatusUnauthorized)
+			})
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/sammidev/goca/internal/pkg/authz"
 	"github.com/sammidev/goca/internal/server/api/middleware"
 )
 
@@ -16,18 +17,62 @@ func (s *Server) registerAPIRoutes() {
 	auth.Post("/refresh-token", s.userHandler.RefreshToken)
 	auth.Post("/forgot-password", s.userHandler.ForgotPassword)
 	auth.Post("/reset-password", s.userHandler.ResetPassword)
-	auth.Post("/setup-2fa", middleware.AuthMiddleware(s.token), s.userHandler.Setup2FA)
-	auth.Post("/verify-2fa", middleware.AuthMiddleware(s.token), s.userHandler.Verify2FA)
-	auth.Post("/disable-2fa", middleware.AuthMiddleware(s.token), s.userHandler.Disable2FA)
+	auth.Post("/consume-link", s.userHandler.ConsumeLink)
+	auth.Post("/2fa/challenge", s.userHandler.Complete2FAChallenge)
+	auth.Post("/unlock-account", s.userHandler.UnlockAccount)
+	auth.Post("/report-login", s.userHandler.ReportLogin)
+	auth.Post("/setup-2fa", middleware.AuthMiddleware(s.token, s.denylist), middleware.RequireReauthentication(s.token, s.denylist), s.userHandler.Setup2FA)
+	auth.Post("/verify-2fa", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.Verify2FA)
+	auth.Post("/change-password", middleware.AuthMiddleware(s.token, s.denylist), middleware.RequireReauthentication(s.token, s.denylist), s.userHandler.ChangePassword)
+	auth.Post("/change-email", middleware.AuthMiddleware(s.token, s.denylist), middleware.RequireReauthentication(s.token, s.denylist), s.userHandler.RequestEmailChange)
+	auth.Post("/change-email/confirm", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.ConfirmEmailChange)
+	auth.Post("/change-email/cancel", s.userHandler.CancelEmailChange)
+	auth.Post("/reauthenticate", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.Reauthenticate)
+	auth.Post("/disable-2fa", middleware.AuthMiddleware(s.token, s.denylist), middleware.RequireReauthentication(s.token, s.denylist), s.userHandler.Disable2FA)
+	auth.Get("/2fa/recovery-codes", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.GetRecoveryCodesStatus)
+	auth.Post("/2fa/recovery-codes", middleware.AuthMiddleware(s.token, s.denylist), middleware.RequireReauthentication(s.token, s.denylist), s.userHandler.RegenerateRecoveryCodes)
+	auth.Post("/logout", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.Logout)
+	auth.Post("/logout-all", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.LogoutAll)
+	auth.Get("/sessions", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.ListSessions)
+	auth.Post("/sessions/revoke-others", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.RevokeOtherSessions)
+	auth.Delete("/sessions/:id", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.RevokeSession)
+	auth.Post("/access-tokens", middleware.AuthMiddleware(s.token, s.denylist), middleware.RequireReauthentication(s.token, s.denylist), s.userHandler.CreatePersonalAccessToken)
+	auth.Get("/access-tokens", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.ListPersonalAccessTokens)
+	auth.Delete("/access-tokens/:id", middleware.AuthMiddleware(s.token, s.denylist), s.userHandler.RevokePersonalAccessToken)
 
-	// Protected routes
-	protected := api.Use(middleware.AuthMiddleware(s.token))
+	// Protected routes also accept personal access tokens, so every route below must
+	// be guarded by RequireScope or RequirePermission.
+	protected := api.Use(middleware.AuthMiddleware(s.token, s.denylist, middleware.WithPersonalAccessTokens(s.pats)))
+
+	// Profile routes
+	protected.Get("/me", middleware.RequireScope(authz.ScopeProfileRead), s.userHandler.GetProfile)
+	protected.Patch("/me", middleware.RequireScope(authz.ScopeProfileWrite), s.userHandler.UpdateProfile)
+	protected.Delete("/me", middleware.RequireScope(authz.ScopeProfileWrite), middleware.RequireReauthentication(s.token, s.denylist), s.userHandler.DeleteAccount)
+	protected.Get("/me/security-events", middleware.RequireScope(authz.ScopeProfileRead), s.userHandler.ListSecurityEvents)
 
 	// Note routes
 	notes := protected.Group("/notes")
-	notes.Post("/", s.noteHandler.CreateNote)
-	notes.Get("/", s.noteHandler.GetNotes)
-	notes.Get("/:id", s.noteHandler.GetNote)
-	notes.Put("/:id", s.noteHandler.UpdateNote)
-	notes.Delete("/:id", s.noteHandler.DeleteNote)
+	notes.Post("/", middleware.RequireScope(authz.ScopeNotesWrite), s.noteHandler.CreateNote)
+	notes.Get("/", middleware.RequireScope(authz.ScopeNotesRead), s.noteHandler.GetNotes)
+	notes.Get("/:id", middleware.RequireScope(authz.ScopeNotesRead), s.noteHandler.GetNote)
+	notes.Put("/:id", middleware.RequireScope(authz.ScopeNotesWrite), s.noteHandler.UpdateNote)
+	notes.Delete("/:id", middleware.RequireScope(authz.ScopeNotesWrite), s.noteHandler.DeleteNote)
+
+	// Admin routes. The token permissions reject most callers early, the service
+	// checks the permission again against the current role in the database.
+	admin := protected.Group("/admin")
+	admin.Get("/users", middleware.RequirePermission(authz.UsersRead), s.userHandler.AdminListUsers)
+	admin.Get("/users/:id", middleware.RequirePermission(authz.UsersRead), s.userHandler.AdminGetUser)
+	admin.Post("/users/:id/suspend", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminSuspendUser)
+	admin.Post("/users/:id/reactivate", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminReactivateUser)
+	admin.Post("/users/:id/verify-email", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminVerifyEmail)
+	admin.Post("/users/:id/reset-2fa", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminReset2FA)
+	admin.Post("/users/:id/reset-password", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminResetPassword)
+	admin.Post("/users/:id/unlock", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminUnlockAccount)
+	admin.Put("/users/:id/role", middleware.RequirePermission(authz.RolesManage), s.userHandler.AdminAssignRole)
+	admin.Get("/roles", middleware.RequirePermission(authz.RolesManage), s.userHandler.AdminListRoles)
+	admin.Get("/security-events", middleware.RequirePermission(authz.SecurityEventsRead), s.userHandler.AdminListSecurityEvents)
+	admin.Post("/invites", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminCreateInvite)
+	admin.Get("/invites", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminListInvites)
+	admin.Delete("/invites/:id", middleware.RequirePermission(authz.UsersManage), s.userHandler.AdminRevokeInvite)
 }
@@ -24,6 +24,8 @@ type Server struct {
 	cfg         *config.Config
 	logger      logger.Logger
 	token       token.Token
+	denylist    token.Denylist
+	pats        middleware.PersonalAccessTokenAuthenticator
 	userHandler UserHandler
 	noteHandler NoteHandler
 }
@@ -33,6 +35,8 @@ func NewServer(
 	cfg *config.Config,
 	logger logger.Logger,
 	token token.Token,
+	denylist token.Denylist,
+	pats middleware.PersonalAccessTokenAuthenticator,
 	userHandler UserHandler,
 	noteHandler NoteHandler,
 ) (*Server, error) {
@@ -49,6 +53,8 @@ func NewServer(
 		cfg:         cfg,
 		logger:      logger,
 		token:       token,
+		denylist:    denylist,
+		pats:        pats,
 		userHandler: userHandler,
 		noteHandler: noteHandler,
 	}
@@ -88,6 +94,11 @@ func (s *Server) setupRoutes() {
 	s.app.Get("/swagger/*", fiberSwagger.WrapHandler)
 	s.app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
 
+	// Public keys for verifying access tokens, only available with asymmetric signing
+	if keySet, ok := s.token.(token.KeySetProvider); ok {
+		s.app.Get("/.well-known/jwks.json", s.jwks(keySet))
+	}
+
 	// API routes
 	s.registerAPIRoutes()
 }
@@ -101,6 +112,13 @@ func (s *Server) healthCheck(c *fiber.Ctx) error {
 	})
 }
 
+func (s *Server) jwks(keySet token.KeySetProvider) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(keySet.JWKS())
+	}
+}
+
 func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.cfg.ServerHost, s.cfg.ServerPort)
 	s.logger.Info("Server starting on %s", addr)
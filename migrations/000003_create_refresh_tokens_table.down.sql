@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_refresh_tokens_expires_at;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_two_factor_enrollments_expires_at;
+
+DROP TABLE IF EXISTS two_factor_enrollments;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS two_factor_enrollments (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    secret VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_two_factor_enrollments_expires_at ON two_factor_enrollments(expires_at);
+
+-- Secrets that were stored but never confirmed belong to abandoned setups.
+UPDATE users SET two_factor_secret = NULL WHERE two_factor_enabled = FALSE AND two_factor_secret IS NOT NULL;
+
+-- The old setup flow also set two_factor_enabled = TRUE before any code was confirmed.
+-- Those rows cannot be told apart from confirmed setups, so they are left untouched:
+-- clearing them here would strip 2FA from accounts that really use it. A user locked
+-- out by an abandoned setup can be recovered with POST /admin/users/{id}/reset-2fa.
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_two_factor_recovery_codes_user_id;
+
+DROP TABLE IF EXISTS two_factor_recovery_codes;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
+
+UPDATE users SET last_name = '' WHERE last_name IS NULL;
+ALTER TABLE users ALTER COLUMN last_name SET NOT NULL;
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_name VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    permission_name VARCHAR(100) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (role_name, permission_name)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('user', 'Regular user, can only access their own resources'),
+    ('admin', 'Administrator with every permission')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('notes:read:any', 'Read notes of any user'),
+    ('notes:update:any', 'Update notes of any user'),
+    ('notes:delete:any', 'Delete notes of any user'),
+    ('users:read', 'List and view users'),
+    ('users:manage', 'Suspend, reactivate, verify and reset users'),
+    ('roles:manage', 'View roles and assign them to users')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+-- Every role already in use must exist before users.role can reference it
+INSERT INTO roles (name)
+SELECT DISTINCT role FROM users
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
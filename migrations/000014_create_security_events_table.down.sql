@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'security_events:read';
+
+DROP TABLE IF EXISTS security_events;
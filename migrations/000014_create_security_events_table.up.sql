@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY,
+    user_id UUID NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id_created_at ON security_events(user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_event_type ON security_events(event_type);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events(created_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('security_events:read', 'View the security events of any user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'security_events:read')
+ON CONFLICT DO NOTHING;
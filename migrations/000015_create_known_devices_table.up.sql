@@ -0,0 +1,17 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS login_alerts_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- One row per device fingerprint and IP range a user has signed in from.
+CREATE TABLE IF NOT EXISTS known_devices (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    fingerprint VARCHAR(64) NOT NULL,
+    ip_range VARCHAR(50) NOT NULL DEFAULT '',
+    device VARCHAR(255) NOT NULL DEFAULT '',
+    location VARCHAR(255) NOT NULL DEFAULT '',
+    first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_known_devices_user_id_fingerprint_ip_range ON known_devices(user_id, fingerprint, ip_range);
+
+CREATE INDEX IF NOT EXISTS idx_known_devices_last_seen_at ON known_devices(last_seen_at);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS invites (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    invited_by UUID NULL REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    redeemed_at TIMESTAMP WITH TIME ZONE NULL,
+    redeemed_by UUID NULL REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+    revoked_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invites_token_hash ON invites(token_hash);
+
+CREATE INDEX IF NOT EXISTS idx_invites_email ON invites(LOWER(email));
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_two_factor_recovery_codes_user_id_code_hash;
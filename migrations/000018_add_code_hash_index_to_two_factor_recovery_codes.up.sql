@@ -0,0 +1,6 @@
+-- Recovery codes are now stored as an HMAC-SHA256 of the code, keyed with a subkey
+-- derived from AUTH_OTP_SECRET, and looked up by that value instead of being checked
+-- one by one with the password hasher as first introduced. Codes already stored with
+-- the password hasher (PHC strings starting with "$") stay redeemable until the user
+-- regenerates them, so no existing rows are changed.
+CREATE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_user_id_code_hash ON two_factor_recovery_codes(user_id, code_hash);